@@ -53,10 +53,45 @@ func monitoringStatsRoutes(router *mux.Router) {
 }
 
 func integrationRoutes(router *mux.Router) {
+	router.HandleFunc("/integration-types", GetIntegrationTypesHandler).Methods("GET")
+
 	router.HandleFunc("/integrations", AddIntegrationHandler).Methods("POST")
 	router.HandleFunc("/integrations", GetIntegrationsHandler).Methods("GET")
 	router.HandleFunc("/integrations/{integrationID}", GetIntegrationHandler).Methods("GET")
 	router.HandleFunc("/integrations/{integrationID}", DeleteIntegrationHandler).Methods("DELETE")
+	router.HandleFunc("/integrations/{integrationID}/test", TestIntegrationHandler).Methods("POST")
+
+	router.HandleFunc("/integrations/{integrationID}/monitoring-urls", GetIntegrationMonitoringURLsHandler).Methods("GET")
+	router.HandleFunc(
+		"/integrations/{integrationID}/monitoring-urls/{monitoringURLID}", IntegrationMonitoringURLHandler,
+	).Methods("PUT", "DELETE")
+}
+
+func notificationRoutes(router *mux.Router) {
+	router.HandleFunc("/integrations/{integrationID}/notifications", GetIntegrationNotificationsHandler).Methods("GET")
+	router.HandleFunc("/monitoring-urls/{monitoringURLID}/notifications", GetMonitoringURLNotificationsHandler).Methods("GET")
+}
+
+func escalationRoutes(router *mux.Router) {
+	router.HandleFunc("/escalation-policies", AddEscalationPolicyHandler).Methods("POST")
+	router.HandleFunc("/escalation-policies", GetEscalationPoliciesHandler).Methods("GET")
+	router.HandleFunc("/escalation-policies/{escalationPolicyID}", GetEscalationPolicyHandler).Methods("GET")
+	router.HandleFunc("/escalation-policies/{escalationPolicyID}", DeleteEscalationPolicyHandler).Methods("DELETE")
+
+	router.HandleFunc("/incidents", GetIncidentsHandler).Methods("GET")
+	router.HandleFunc("/incidents/{incidentID}/acknowledge", AcknowledgeIncidentHandler).Methods("POST")
+}
+
+func maintenanceRoutes(router *mux.Router) {
+	router.HandleFunc("/maintenance-windows", AddMaintenanceWindowHandler).Methods("POST")
+	router.HandleFunc("/maintenance-windows", GetMaintenanceWindowsHandler).Methods("GET")
+	router.HandleFunc("/maintenance-windows/{maintenanceWindowID}", GetMaintenanceWindowHandler).Methods("GET")
+	router.HandleFunc("/maintenance-windows/{maintenanceWindowID}", DeleteMaintenanceWindowHandler).Methods("DELETE")
+}
+
+func heartbeatRoutes(router *mux.Router) {
+	router.HandleFunc("/heartbeat/{token}", HeartbeatHandler).Methods("GET", "POST", "HEAD")
+	router.HandleFunc("/heartbeat/{token}/{event:start|fail}", HeartbeatHandler).Methods("GET", "POST", "HEAD")
 }
 
 // StartServer Start the server.
@@ -67,6 +102,10 @@ func StartServer() {
 	monitoringDetailsRoutes(router)
 	monitoringStatsRoutes(router)
 	integrationRoutes(router)
+	notificationRoutes(router)
+	escalationRoutes(router)
+	maintenanceRoutes(router)
+	heartbeatRoutes(router)
 	authRoutes(router)
 	userRoutes(router)
 	dashboardRoutes(router)
@@ -23,12 +23,14 @@ func DashboardStatsHandler(w http.ResponseWriter, r *http.Request) {
 	monitoringURLCount := datastore.GetMonitoringURLSByUserIDCount(user.ID)
 	upMonitoringURLCount := datastore.GetMonitoringURLSByUserIDAndStatus(user.ID, utils.StatusUp)
 	downMonitoringURLCount := datastore.GetMonitoringURLSByUserIDAndStatus(user.ID, utils.StatusDown)
+	flappingMonitoringURLCount := datastore.GetFlappingMonitoringURLSByUserIDCount(user.ID)
 
 	stats := make(map[string]interface{})
 
 	stats["monitoring_urls_count"] = monitoringURLCount
 	stats["up_monitoring_urls_count"] = upMonitoringURLCount
 	stats["down_monitoring_urls_count"] = downMonitoringURLCount
+	stats["flapping_monitoring_urls_count"] = flappingMonitoringURLCount
 
 	writeSuccessStructResponse(w, stats, http.StatusOK)
 }
@@ -46,6 +46,7 @@ func TestGetDashboardStats(t *testing.T) {
 	monitoringURLCount := response.Data["monitoring_urls_count"].(float64)
 	upMonitoringURLCount := response.Data["up_monitoring_urls_count"].(float64)
 	downMonitoringURLCount := response.Data["down_monitoring_urls_count"].(float64)
+	flappingMonitoringURLCount := response.Data["flapping_monitoring_urls_count"].(float64)
 
 	if monitoringURLCount != 1 {
 		t.Errorf("monitoring url count should be 1")
@@ -53,5 +54,7 @@ func TestGetDashboardStats(t *testing.T) {
 		t.Errorf("up monitoring url count should be 1")
 	} else if downMonitoringURLCount != 0 {
 		t.Errorf("down monitoring url count should be 0")
+	} else if flappingMonitoringURLCount != 0 {
+		t.Errorf("flapping monitoring url count should be 0")
 	}
 }
@@ -0,0 +1,175 @@
+package api
+
+import (
+	"encoding/json"
+	"net/http"
+	"time"
+
+	"github.com/defraglabs/uptime/internal/db"
+	"github.com/defraglabs/uptime/internal/forms"
+	"github.com/fatih/structs"
+	"github.com/gorilla/mux"
+	log "github.com/sirupsen/logrus"
+)
+
+// incidentHistoryLimit is the number of incidents returned, latest first.
+const incidentHistoryLimit = 100
+
+// AddEscalationPolicyHandler can be used to add a new escalation policy.
+func AddEscalationPolicyHandler(w http.ResponseWriter, r *http.Request) {
+	authToken := r.Header.Get("Authorization")
+	user, authErr := db.ValidateJWT(authToken)
+
+	if authErr != nil {
+		writeErrorResponse(w, "Authentication failed")
+
+		return
+	}
+
+	decoder := json.NewDecoder(r.Body)
+	var escalationPolicyForm forms.EscalationPolicyForm
+	err := decoder.Decode(&escalationPolicyForm)
+	if err != nil {
+		writeErrorResponse(w, "Invalid input format")
+
+		return
+	}
+
+	validationMessage := escalationPolicyForm.Validate()
+	if validationMessage != "" {
+		writeErrorResponse(w, validationMessage)
+
+		log.Info("Validation failed while adding escalation policy.")
+		return
+	}
+
+	datastore := db.New()
+	for _, step := range escalationPolicyForm.Steps {
+		if !integrationsExist(datastore, user.ID, step.IntegrationIDs) {
+			writeErrorResponse(w, "Integration not found")
+
+			return
+		}
+	}
+
+	escalationPolicyForm.ID = db.GenerateObjectID().Hex()
+	escalationPolicyForm.UserID = user.ID
+	escalationPolicy := datastore.AddEscalationPolicy(escalationPolicyForm)
+
+	log.Info("Escalation policy added successfully.")
+
+	responseData := structs.Map(escalationPolicy)
+	writeSuccessStructResponse(w, responseData, http.StatusCreated)
+}
+
+// GetEscalationPoliciesHandler gets all escalation policies of the logged in user.
+func GetEscalationPoliciesHandler(w http.ResponseWriter, r *http.Request) {
+	authToken := r.Header.Get("Authorization")
+	user, authErr := db.ValidateJWT(authToken)
+
+	if authErr != nil {
+		writeErrorResponse(w, "Authentication failed")
+
+		return
+	}
+
+	datastore := db.New()
+	escalationPolicies := datastore.GetEscalationPoliciesByUserID(user.ID)
+	writeSuccessSimpleResponse(w, escalationPolicies, http.StatusOK)
+}
+
+// GetEscalationPolicyHandler gets a specific escalation policy.
+func GetEscalationPolicyHandler(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	escalationPolicyID := vars["escalationPolicyID"]
+
+	authToken := r.Header.Get("Authorization")
+	user, authErr := db.ValidateJWT(authToken)
+
+	if authErr != nil {
+		writeErrorResponse(w, "Authentication failed")
+
+		return
+	}
+
+	datastore := db.New()
+	escalationPolicy := datastore.GetEscalationPolicyByUserID(user.ID, escalationPolicyID)
+	if escalationPolicy.ID == "" {
+		writeErrorResponse(w, "Escalation policy not found")
+
+		return
+	}
+
+	responseData := structs.Map(escalationPolicy)
+	writeSuccessStructResponse(w, responseData, http.StatusOK)
+}
+
+// DeleteEscalationPolicyHandler removes an escalation policy. The alerts of its monitoring urls
+// are sent through their integrations again.
+func DeleteEscalationPolicyHandler(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	escalationPolicyID := vars["escalationPolicyID"]
+
+	authToken := r.Header.Get("Authorization")
+	user, authErr := db.ValidateJWT(authToken)
+
+	if authErr != nil {
+		writeErrorResponse(w, "Authentication failed")
+
+		return
+	}
+
+	datastore := db.New()
+	datastore.DeleteEscalationPolicy(user.ID, escalationPolicyID)
+
+	w.Header().Set("Content-Type", "application/json; charset=UTF-8")
+	w.WriteHeader(http.StatusNoContent)
+	log.Info("Escalation policy removed successfully.")
+}
+
+// GetIncidentsHandler lists the latest incidents of the logged in user.
+func GetIncidentsHandler(w http.ResponseWriter, r *http.Request) {
+	authToken := r.Header.Get("Authorization")
+	user, authErr := db.ValidateJWT(authToken)
+
+	if authErr != nil {
+		writeErrorResponse(w, "Authentication failed")
+
+		return
+	}
+
+	datastore := db.New()
+	incidents := datastore.GetIncidentsByUserID(user.ID, incidentHistoryLimit)
+	writeSuccessSimpleResponse(w, incidents, http.StatusOK)
+}
+
+// AcknowledgeIncidentHandler acknowledges an incident, which stops its escalation.
+func AcknowledgeIncidentHandler(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	incidentID := vars["incidentID"]
+
+	authToken := r.Header.Get("Authorization")
+	user, authErr := db.ValidateJWT(authToken)
+
+	if authErr != nil {
+		writeErrorResponse(w, "Authentication failed")
+
+		return
+	}
+
+	datastore := db.New()
+	incident := datastore.GetIncidentByUserID(user.ID, incidentID)
+	if incident.ID == "" {
+		writeErrorResponse(w, "Incident not found")
+
+		return
+	} else if !datastore.AcknowledgeIncident(user.ID, incidentID, time.Now()) {
+		writeErrorResponse(w, "Incident is already "+incident.Status)
+
+		return
+	}
+
+	incident = datastore.GetIncidentByUserID(user.ID, incidentID)
+	responseData := structs.Map(incident)
+	writeSuccessStructResponse(w, responseData, http.StatusOK)
+}
@@ -0,0 +1,132 @@
+package api
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"testing"
+
+	"github.com/defraglabs/uptime/internal/db"
+	"github.com/defraglabs/uptime/internal/forms"
+	"github.com/gorilla/mux"
+)
+
+func clearEscalationCollections() {
+	clearIntegrationCollection()
+
+	datastore := db.New()
+	datastore.Client.Database(datastore.DatabaseName).Collection(
+		db.EscalationPolicyCollection).Drop(context.Background())
+
+	datastore.Client.Database(datastore.DatabaseName).Collection(
+		db.IncidentCollection).Drop(context.Background())
+}
+
+func TestAddEscalationPolicyWithUnknownIntegrationHandler(t *testing.T) {
+	os.Setenv("MONGO_DATABASE_NAME", "uptime_test")
+	user, jwt := createTestUser()
+	integrationID := addTestIntegration(user.ID)
+
+	defer clearEscalationCollections()
+
+	escalationPolicyForm := forms.EscalationPolicyForm{
+		Name: "on-call",
+		Steps: []forms.EscalationStepForm{
+			{IntegrationIDs: []string{integrationID}, Delay: 10},
+			{IntegrationIDs: []string{"unknown"}},
+		},
+	}
+
+	byte, _ := json.Marshal(escalationPolicyForm)
+	req, err := http.NewRequest("POST", "localhost:8080/api/escalation-policies", bytes.NewBuffer(byte))
+
+	token := fmt.Sprintf("JWT %s", jwt)
+	req.Header.Add("Authorization", token)
+
+	if err != nil {
+		t.Errorf("Unable to create a new request")
+	}
+
+	responseWriter := httptest.NewRecorder()
+	AddEscalationPolicyHandler(responseWriter, req)
+
+	res := responseWriter.Result()
+	defer res.Body.Close()
+
+	if res.StatusCode != http.StatusBadRequest {
+		t.Errorf("expected status BAD REQUEST, got %v", res.StatusCode)
+	}
+
+	response := Response{}
+	json.NewDecoder(res.Body).Decode(&response)
+
+	if response.Error["message"] != "Integration not found" {
+		t.Errorf("should not be able to escalate to an unknown integration")
+	}
+}
+
+func TestAcknowledgeIncidentHandler(t *testing.T) {
+	os.Setenv("MONGO_DATABASE_NAME", "uptime_test")
+	user, jwt := createTestUser()
+	integrationID := addTestIntegration(user.ID)
+
+	defer clearEscalationCollections()
+
+	datastore := db.New()
+	escalationPolicy := datastore.AddEscalationPolicy(forms.EscalationPolicyForm{
+		ID:     db.GenerateObjectID().Hex(),
+		UserID: user.ID,
+		Name:   "on-call",
+		Steps: []forms.EscalationStepForm{
+			{IntegrationIDs: []string{integrationID}, Delay: 10},
+			{IntegrationIDs: []string{integrationID}},
+		},
+	})
+
+	alert := db.NewStatusAlert(
+		db.MonitorURL{ID: "monitor-url-id", UserID: user.ID, URL: "example.com"},
+		"UP",
+		db.MonitorResult{Status: "DOWN"},
+	)
+	incident := datastore.AddIncident(db.NewIncident(escalationPolicy, alert))
+
+	req, err := http.NewRequest("POST", fmt.Sprintf("localhost:8080/api/incidents/%s/acknowledge", incident.ID), nil)
+
+	token := fmt.Sprintf("JWT %s", jwt)
+	req.Header.Add("Authorization", token)
+
+	if err != nil {
+		t.Errorf("Unable to create a new request")
+	}
+
+	vars := map[string]string{
+		"incidentID": incident.ID,
+	}
+	req = mux.SetURLVars(req, vars)
+
+	responseWriter := httptest.NewRecorder()
+	AcknowledgeIncidentHandler(responseWriter, req)
+
+	res := responseWriter.Result()
+	defer res.Body.Close()
+
+	if res.StatusCode != http.StatusOK {
+		t.Errorf("expected status OK, got %v", res.StatusCode)
+	}
+
+	response := StructResponse{}
+	json.NewDecoder(res.Body).Decode(&response)
+
+	if response.Data["status"] != db.IncidentStatusAcknowledged {
+		t.Errorf("expected incident to be acknowledged, got %v", response.Data["status"])
+	}
+
+	dueIncidents := datastore.GetDueIncidents(incident.NextEscalationAt, 10)
+	if len(dueIncidents) != 0 {
+		t.Errorf("acknowledged incident should not escalate")
+	}
+}
@@ -0,0 +1,44 @@
+package api
+
+import (
+	"net/http"
+
+	"github.com/gorilla/mux"
+
+	"github.com/defraglabs/uptime/internal/db"
+	"github.com/defraglabs/uptime/internal/tasks"
+	log "github.com/sirupsen/logrus"
+)
+
+// HeartbeatHandler receives the pings of heartbeat monitors. It is not authenticated,
+// the token in the url identifies the monitor. Without an event the ping reports
+// a successful run. Valid values for the event are
+//   - start
+//   - fail
+func HeartbeatHandler(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	heartbeatToken := vars["token"]
+
+	event := vars["event"]
+	if event == "" {
+		event = tasks.HeartbeatPing
+	}
+
+	datastore := db.New()
+	monitorURL := datastore.GetMonitoringURLByHeartbeatToken(heartbeatToken)
+	if monitorURL.ID == "" {
+		writeErrorResponse(w, "Heartbeat not found")
+
+		return
+	}
+
+	if monitorURL.MonitoringStatus == db.MonitoringStatusPaused {
+		log.Infof("Monitoring paused, ignoring heartbeat for %s", monitorURL.Name)
+	} else {
+		tasks.ReceiveHeartbeat(monitorURL, event)
+	}
+
+	data := make(map[string]string)
+	data["message"] = "Heartbeat received"
+	writeSuccessResponse(w, data, http.StatusOK)
+}
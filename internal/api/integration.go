@@ -2,7 +2,9 @@ package api
 
 import (
 	"encoding/json"
+	"fmt"
 	"net/http"
+	"time"
 
 	"github.com/defraglabs/uptime/internal/db"
 	"github.com/defraglabs/uptime/internal/forms"
@@ -34,6 +36,10 @@ func AddIntegrationHandler(w http.ResponseWriter, r *http.Request) {
 	}
 
 	validationMessage := integrationForm.Validate()
+	if validationMessage == "" {
+		_, validationMessage = db.NewIntegration(integrationForm)
+	}
+
 	if validationMessage != "" {
 		writeErrorResponse(w, validationMessage)
 
@@ -49,10 +55,25 @@ func AddIntegrationHandler(w http.ResponseWriter, r *http.Request) {
 
 	log.Info("Integration added successfully.")
 
-	responseData := structs.Map(integration)
+	// The secrets are only shown once, when the integration is added.
+	responseData := integration.Map(true)
 	writeSuccessStructResponse(w, responseData, http.StatusCreated)
 }
 
+// GetIntegrationTypesHandler lists the integration types with the fields of their config.
+func GetIntegrationTypesHandler(w http.ResponseWriter, r *http.Request) {
+	authToken := r.Header.Get("Authorization")
+	_, authErr := db.ValidateJWT(authToken)
+
+	if authErr != nil {
+		writeErrorResponse(w, "Authentication failed")
+
+		return
+	}
+
+	writeSuccessSimpleResponse(w, db.GetNotifierTypes(), http.StatusOK)
+}
+
 // GetIntegrationsHandler gets all integrations by the logged in user.
 func GetIntegrationsHandler(w http.ResponseWriter, r *http.Request) {
 	authToken := r.Header.Get("Authorization")
@@ -67,13 +88,9 @@ func GetIntegrationsHandler(w http.ResponseWriter, r *http.Request) {
 	datastore := db.New()
 	integrations := datastore.GetIntegrationsByUserID(user.ID)
 
-	data := make(map[string][]db.Integration)
+	data := make(map[string][]map[string]interface{})
 	for _, integration := range integrations {
-		if _, ok := data[integration.Type]; ok {
-			data[integration.Type] = append(data[integration.Type], integration)
-		} else {
-			data[integration.Type] = append(data[integration.Type], integration)
-		}
+		data[integration.Type] = append(data[integration.Type], integration.Map(false))
 	}
 
 	writeSuccessSimpleResponse(w, data, http.StatusOK)
@@ -95,7 +112,7 @@ func GetIntegrationHandler(w http.ResponseWriter, r *http.Request) {
 
 	datastore := db.New()
 	integration := datastore.GetIntegrationByUserID(user.ID, integrationID)
-	responseData := structs.Map(integration)
+	responseData := integration.Map(false)
 	writeSuccessStructResponse(w, responseData, http.StatusOK)
 }
 
@@ -114,9 +131,144 @@ func DeleteIntegrationHandler(w http.ResponseWriter, r *http.Request) {
 	}
 
 	datastore := db.New()
+
+	// Without integrations the alerts of a monitoring url would be sent through all of them.
+	if count := datastore.GetMonitoringURLSRoutedOnlyToIntegrationCount(user.ID, integrationID); count > 0 {
+		writeErrorResponse(w, fmt.Sprintf("Integration is the only integration of %d monitoring urls, route them to another integration first", count))
+
+		return
+	}
+
 	datastore.DeleteIntegration(user.ID, integrationID)
+	datastore.RemoveIntegrationFromMonitoringURLS(user.ID, integrationID)
 
 	w.Header().Set("Content-Type", "application/json; charset=UTF-8")
 	w.WriteHeader(http.StatusNoContent)
 	log.Info("Integration removed successfully.")
 }
+
+// TestIntegrationHandler sends a test notification through the integration and returns the
+// response of the integration, or the error.
+func TestIntegrationHandler(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	integrationID := vars["integrationID"]
+
+	authToken := r.Header.Get("Authorization")
+	user, authErr := db.ValidateJWT(authToken)
+
+	if authErr != nil {
+		writeErrorResponse(w, "Authentication failed")
+
+		return
+	}
+
+	datastore := db.New()
+	integration := datastore.GetIntegrationByUserID(user.ID, integrationID)
+	if integration.ID == "" {
+		writeErrorResponse(w, "Integration not found")
+
+		return
+	}
+
+	attempt, err := integration.Attempt(db.NewTestAlert(integration, time.Now()))
+	if err != nil {
+		log.Infof("Test notification of integration %s failed: %s", integration.ID, err)
+	}
+
+	responseData := structs.Map(attempt)
+	responseData["delivered"] = err == nil
+	writeSuccessStructResponse(w, responseData, http.StatusOK)
+}
+
+// GetIntegrationMonitoringURLsHandler lists the monitoring urls whose alerts are sent through the integration.
+func GetIntegrationMonitoringURLsHandler(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	integrationID := vars["integrationID"]
+
+	authToken := r.Header.Get("Authorization")
+	user, authErr := db.ValidateJWT(authToken)
+
+	if authErr != nil {
+		writeErrorResponse(w, "Authentication failed")
+
+		return
+	}
+
+	datastore := db.New()
+	integration := datastore.GetIntegrationByUserID(user.ID, integrationID)
+	if integration.ID == "" {
+		writeErrorResponse(w, "Integration not found")
+
+		return
+	}
+
+	monitoringURLS := datastore.GetMonitoringURLSByIntegrationID(user.ID, integrationID)
+	writeSuccessSimpleResponse(w, monitoringURLS, http.StatusOK)
+}
+
+// IntegrationMonitoringURLHandler routes the alerts of a monitoring url through the
+// integration (PUT) or stops routing them (DELETE).
+func IntegrationMonitoringURLHandler(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	integrationID := vars["integrationID"]
+	monitoringURLID := vars["monitoringURLID"]
+
+	authToken := r.Header.Get("Authorization")
+	user, authErr := db.ValidateJWT(authToken)
+
+	if authErr != nil {
+		writeErrorResponse(w, "Authentication failed")
+
+		return
+	}
+
+	datastore := db.New()
+	integration := datastore.GetIntegrationByUserID(user.ID, integrationID)
+	if integration.ID == "" {
+		writeErrorResponse(w, "Integration not found")
+
+		return
+	}
+
+	monitoringURL := datastore.GetMonitoringURLByUserID(user.ID, monitoringURLID)
+	if monitoringURL.ID == "" {
+		writeErrorResponse(w, "Monitoring url not found")
+
+		return
+	}
+
+	// A monitoring url without integrations is routed to all of them, including the ones added later,
+	// so it is left as is on PUT. On DELETE it is routed to every other one instead.
+	integrationIDs := monitoringURL.IntegrationIDs
+	if len(integrationIDs) == 0 && r.Method == http.MethodPut {
+		responseData := structs.Map(monitoringURL)
+		writeSuccessStructResponse(w, responseData, http.StatusOK)
+
+		return
+	} else if len(integrationIDs) == 0 {
+		for _, userIntegration := range datastore.GetIntegrationsByUserID(user.ID) {
+			integrationIDs = append(integrationIDs, userIntegration.ID)
+		}
+	}
+
+	routedIntegrationIDs := []string{}
+	for _, routedIntegrationID := range integrationIDs {
+		if routedIntegrationID != integrationID {
+			routedIntegrationIDs = append(routedIntegrationIDs, routedIntegrationID)
+		}
+	}
+
+	if r.Method == http.MethodPut {
+		routedIntegrationIDs = append(routedIntegrationIDs, integrationID)
+	} else if len(routedIntegrationIDs) == 0 {
+		writeErrorResponse(w, "Monitoring url should have at least one integration")
+
+		return
+	}
+
+	datastore.SetMonitoringURLIntegrations(user.ID, monitoringURLID, routedIntegrationIDs)
+
+	monitoringURL = datastore.GetMonitoringURLByUserID(user.ID, monitoringURLID)
+	responseData := structs.Map(monitoringURL)
+	writeSuccessStructResponse(w, responseData, http.StatusOK)
+}
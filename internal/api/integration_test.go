@@ -164,7 +164,7 @@ func TestAddSlackWithInvalidTypeIntegrationHandler(t *testing.T) {
 		t.Errorf("response success is true")
 	}
 
-	if response.Error["message"] != "invalid integration type. Should be slack/email/pagerduty" {
+	if response.Error["message"] != "invalid integration type. Should be discord/slack/email/msteams/opsgenie/pagerduty/sms/voice/webhook" {
 		t.Errorf("should not be able to add integration with wrong type")
 	}
 }
@@ -210,6 +210,120 @@ func TestAddPagerDutyIntegrationHandler(t *testing.T) {
 	}
 }
 
+func TestAddPagerDutyWithInvalidSeverityIntegrationHandler(t *testing.T) {
+	os.Setenv("MONGO_DATABASE_NAME", "uptime_test")
+	_, jwt := createTestUser()
+
+	defer clearIntegrationCollection()
+
+	integrationForm := forms.IntegrationForm{
+		Type:         "pagerduty",
+		PDRoutingKey: "routing-key",
+		PDSeverity:   "urgent",
+	}
+
+	byte, _ := json.Marshal(integrationForm)
+	req, err := http.NewRequest("POST", "localhost:8080/api/integrations", bytes.NewBuffer(byte))
+
+	token := fmt.Sprintf("JWT %s", jwt)
+	req.Header.Add("Authorization", token)
+
+	if err != nil {
+		t.Errorf("Unable to create a new request")
+	}
+
+	responseWriter := httptest.NewRecorder()
+	AddIntegrationHandler(responseWriter, req)
+
+	res := responseWriter.Result()
+	defer res.Body.Close()
+
+	if res.StatusCode != http.StatusBadRequest {
+		t.Errorf("expected status BAD REQUEST, got %v", res.StatusCode)
+	}
+
+	response := StructResponse{}
+	json.NewDecoder(res.Body).Decode(&response)
+
+	if response.Error["message"] != "invalid pagerduty severity. Should be critical/error/warning/info" {
+		t.Errorf("should not be able to add pagerduty integration with an invalid severity")
+	}
+}
+
+func TestAddWebhookIntegrationHandler(t *testing.T) {
+	os.Setenv("MONGO_DATABASE_NAME", "uptime_test")
+	_, jwt := createTestUser()
+
+	defer clearIntegrationCollection()
+
+	integrationForm := forms.IntegrationForm{
+		Type:         "webhook",
+		WebhookURL:   "https://bot.example.com/uptime",
+		Headers:      map[string]string{"X-Team": "infra"},
+		BodyTemplate: `{"text": {{json .Summary}}}`,
+	}
+
+	byte, _ := json.Marshal(integrationForm)
+	req, err := http.NewRequest("POST", "localhost:8080/api/integrations", bytes.NewBuffer(byte))
+
+	token := fmt.Sprintf("JWT %s", jwt)
+	req.Header.Add("Authorization", token)
+
+	if err != nil {
+		t.Errorf("Unable to create a new request")
+	}
+
+	responseWriter := httptest.NewRecorder()
+	AddIntegrationHandler(responseWriter, req)
+
+	res := responseWriter.Result()
+	defer res.Body.Close()
+
+	if res.StatusCode != http.StatusCreated {
+		t.Errorf("expected status CREATED, got %v", res.StatusCode)
+	}
+
+	response := StructResponse{}
+	json.NewDecoder(res.Body).Decode(&response)
+
+	if secret, _ := response.Data["webhookSecret"].(string); secret == "" {
+		t.Errorf("webhook secret should be generated")
+	}
+}
+
+func TestAddWebhookWithInvalidTemplateIntegrationHandler(t *testing.T) {
+	os.Setenv("MONGO_DATABASE_NAME", "uptime_test")
+	_, jwt := createTestUser()
+
+	defer clearIntegrationCollection()
+
+	integrationForm := forms.IntegrationForm{
+		Type:         "webhook",
+		WebhookURL:   "https://bot.example.com/uptime",
+		BodyTemplate: `{"text": {{.Summary}`,
+	}
+
+	byte, _ := json.Marshal(integrationForm)
+	req, err := http.NewRequest("POST", "localhost:8080/api/integrations", bytes.NewBuffer(byte))
+
+	token := fmt.Sprintf("JWT %s", jwt)
+	req.Header.Add("Authorization", token)
+
+	if err != nil {
+		t.Errorf("Unable to create a new request")
+	}
+
+	responseWriter := httptest.NewRecorder()
+	AddIntegrationHandler(responseWriter, req)
+
+	res := responseWriter.Result()
+	defer res.Body.Close()
+
+	if res.StatusCode != http.StatusBadRequest {
+		t.Errorf("expected status BAD REQUEST, got %v", res.StatusCode)
+	}
+}
+
 func TestGetIntegrationsHandler(t *testing.T) {
 	os.Setenv("MONGO_DATABASE_NAME", "uptime_test")
 	user, jwt := createTestUser()
@@ -249,6 +363,40 @@ func TestGetIntegrationsHandler(t *testing.T) {
 	}
 }
 
+func TestGetIntegrationTypesHandler(t *testing.T) {
+	os.Setenv("MONGO_DATABASE_NAME", "uptime_test")
+	_, jwt := createTestUser()
+	defer clearIntegrationCollection()
+
+	req, err := http.NewRequest("GET", "localhost:8080/api/integration-types", nil)
+
+	token := fmt.Sprintf("JWT %s", jwt)
+	req.Header.Add("Authorization", token)
+
+	if err != nil {
+		t.Errorf("Unable to create a new request")
+	}
+
+	responseWriter := httptest.NewRecorder()
+
+	GetIntegrationTypesHandler(responseWriter, req)
+
+	res := responseWriter.Result()
+	defer res.Body.Close()
+
+	if res.StatusCode != http.StatusOK {
+		t.Errorf("expected status OK, got %v", res.StatusCode)
+	}
+
+	response := SimpleResponse{}
+	json.NewDecoder(res.Body).Decode(&response)
+
+	integrationTypes, _ := response.Data.([]interface{})
+	if len(integrationTypes) != len(db.GetNotifierTypes()) {
+		t.Errorf("expected %d integration types, got %d", len(db.GetNotifierTypes()), len(integrationTypes))
+	}
+}
+
 func TestDeleteIntegrationHandler(t *testing.T) {
 	os.Setenv("MONGO_DATABASE_NAME", "uptime_test")
 	user, jwt := createTestUser()
@@ -286,3 +434,191 @@ func TestDeleteIntegrationHandler(t *testing.T) {
 		t.Errorf("Integration is not removed from the database.")
 	}
 }
+
+func TestDeleteOnlyIntegrationOfMonitoringURLHandler(t *testing.T) {
+	os.Setenv("MONGO_DATABASE_NAME", "uptime_test")
+	user, jwt := createTestUser()
+	integrationID := addTestIntegration(user.ID)
+	defer clearIntegrationCollection()
+	defer clearMonitorCollection()
+
+	datastore := db.New()
+	datastore.AddMonitoringURL(forms.MonitorURLForm{
+		ID:             db.GenerateObjectID().Hex(),
+		UserID:         user.ID,
+		Protocol:       "http",
+		Name:           "example",
+		URL:            "example.com",
+		Frequency:      5,
+		Unit:           "minute",
+		IntegrationIDs: []string{integrationID},
+	})
+
+	url := fmt.Sprintf("localhost:8080/api/integrations/%s", integrationID)
+	req, err := http.NewRequest("DELETE", url, nil)
+
+	token := fmt.Sprintf("JWT %s", jwt)
+	req.Header.Add("Authorization", token)
+
+	if err != nil {
+		t.Errorf("Unable to create a new request")
+	}
+
+	responseWriter := httptest.NewRecorder()
+
+	vars := map[string]string{
+		"integrationID": integrationID,
+	}
+	req = mux.SetURLVars(req, vars)
+	DeleteIntegrationHandler(responseWriter, req)
+	res := responseWriter.Result()
+	defer res.Body.Close()
+
+	if res.StatusCode != http.StatusBadRequest {
+		t.Errorf("expected status BAD REQUEST, got %v", res.StatusCode)
+	}
+
+	integration := datastore.GetIntegrationByUserID(user.ID, integrationID)
+	if integration.ID == "" {
+		t.Errorf("Integration of the monitoring url is removed from the database.")
+	}
+}
+
+// routeIntegrationMonitoringURL sends the PUT or DELETE routing the monitoring url through the integration.
+func routeIntegrationMonitoringURL(t *testing.T, method, jwt, integrationID, monitoringURLID string) *http.Response {
+	url := fmt.Sprintf("localhost:8080/api/integrations/%s/monitoring-urls/%s", integrationID, monitoringURLID)
+	req, err := http.NewRequest(method, url, nil)
+
+	token := fmt.Sprintf("JWT %s", jwt)
+	req.Header.Add("Authorization", token)
+
+	if err != nil {
+		t.Errorf("Unable to create a new request")
+	}
+
+	responseWriter := httptest.NewRecorder()
+
+	vars := map[string]string{
+		"integrationID":   integrationID,
+		"monitoringURLID": monitoringURLID,
+	}
+	req = mux.SetURLVars(req, vars)
+	IntegrationMonitoringURLHandler(responseWriter, req)
+
+	return responseWriter.Result()
+}
+
+func TestRouteMonitoringURLRoutedToAllIntegrationsHandler(t *testing.T) {
+	os.Setenv("MONGO_DATABASE_NAME", "uptime_test")
+	user, jwt := createTestUser()
+	integrationID := addTestIntegration(user.ID)
+	monitoringURLID := addTestMonitorURL(user.ID)
+	defer clearIntegrationCollection()
+	defer clearMonitorCollection()
+
+	res := routeIntegrationMonitoringURL(t, "PUT", jwt, integrationID, monitoringURLID)
+	defer res.Body.Close()
+
+	if res.StatusCode != http.StatusOK {
+		t.Errorf("expected status OK, got %v", res.StatusCode)
+	}
+
+	datastore := db.New()
+	monitoringURL := datastore.GetMonitoringURLByUserID(user.ID, monitoringURLID)
+	if len(monitoringURL.IntegrationIDs) != 0 {
+		t.Errorf("expected the monitoring url to stay routed to all the integrations, got %v", monitoringURL.IntegrationIDs)
+	}
+
+	// An integration added afterwards receives the alerts of the monitoring url too.
+	laterIntegrationID := addTestIntegration(user.ID)
+	monitoringURLs := datastore.GetMonitoringURLSByIntegrationID(user.ID, laterIntegrationID)
+	if len(monitoringURLs) != 1 || monitoringURLs[0].ID != monitoringURLID {
+		t.Errorf("expected the monitoring url to be routed to the integration added later, got %v", monitoringURLs)
+	}
+}
+
+func TestStopRoutingMonitoringURLRoutedToAllIntegrationsHandler(t *testing.T) {
+	os.Setenv("MONGO_DATABASE_NAME", "uptime_test")
+	user, jwt := createTestUser()
+	integrationID := addTestIntegration(user.ID)
+	otherIntegrationID := addTestIntegration(user.ID)
+	monitoringURLID := addTestMonitorURL(user.ID)
+	defer clearIntegrationCollection()
+	defer clearMonitorCollection()
+
+	res := routeIntegrationMonitoringURL(t, "DELETE", jwt, integrationID, monitoringURLID)
+	defer res.Body.Close()
+
+	if res.StatusCode != http.StatusOK {
+		t.Errorf("expected status OK, got %v", res.StatusCode)
+	}
+
+	datastore := db.New()
+	monitoringURL := datastore.GetMonitoringURLByUserID(user.ID, monitoringURLID)
+	if len(monitoringURL.IntegrationIDs) != 1 || monitoringURL.IntegrationIDs[0] != otherIntegrationID {
+		t.Errorf("expected the monitoring url to be routed to the other integration, got %v", monitoringURL.IntegrationIDs)
+	}
+}
+
+func TestTestIntegrationHandler(t *testing.T) {
+	os.Setenv("MONGO_DATABASE_NAME", "uptime_test")
+	user, jwt := createTestUser()
+	defer clearIntegrationCollection()
+
+	var event db.WebhookEvent
+	receiver := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		json.NewDecoder(r.Body).Decode(&event)
+
+		w.WriteHeader(http.StatusAccepted)
+		w.Write([]byte("received"))
+	}))
+	defer receiver.Close()
+
+	integrationForm := forms.IntegrationForm{
+		ID:         db.GenerateObjectID().Hex(),
+		UserID:     user.ID,
+		Type:       "webhook",
+		WebhookURL: receiver.URL,
+	}
+	datastore := db.New()
+	integration := datastore.AddIntegration(integrationForm)
+
+	url := fmt.Sprintf("localhost:8080/api/integrations/%s/test", integration.ID)
+	req, err := http.NewRequest("POST", url, nil)
+
+	token := fmt.Sprintf("JWT %s", jwt)
+	req.Header.Add("Authorization", token)
+
+	if err != nil {
+		t.Errorf("Unable to create a new request")
+	}
+
+	responseWriter := httptest.NewRecorder()
+
+	vars := map[string]string{
+		"integrationID": integration.ID,
+	}
+	req = mux.SetURLVars(req, vars)
+	TestIntegrationHandler(responseWriter, req)
+	res := responseWriter.Result()
+	defer res.Body.Close()
+
+	if res.StatusCode != http.StatusOK {
+		t.Errorf("expected status OK, got %v", res.StatusCode)
+	}
+
+	response := StructResponse{}
+	json.NewDecoder(res.Body).Decode(&response)
+
+	if response.Data["delivered"] != true || response.Data["statusCode"] != float64(http.StatusAccepted) {
+		t.Errorf("expected the test notification to be delivered, got %v", response.Data)
+	}
+
+	if response.Data["response"] != "received" {
+		t.Errorf("expected the response of the integration, got %v", response.Data["response"])
+	}
+
+	if event.Type != db.AlertTypeTest {
+		t.Errorf("expected a test event, got %s", event.Type)
+	}
+}
@@ -0,0 +1,124 @@
+package api
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"github.com/defraglabs/uptime/internal/db"
+	"github.com/defraglabs/uptime/internal/forms"
+	"github.com/fatih/structs"
+	"github.com/gorilla/mux"
+	log "github.com/sirupsen/logrus"
+)
+
+// AddMaintenanceWindowHandler can be used to add a new maintenance window.
+func AddMaintenanceWindowHandler(w http.ResponseWriter, r *http.Request) {
+	authToken := r.Header.Get("Authorization")
+	user, authErr := db.ValidateJWT(authToken)
+
+	if authErr != nil {
+		writeErrorResponse(w, "Authentication failed")
+
+		return
+	}
+
+	decoder := json.NewDecoder(r.Body)
+	var maintenanceWindowForm forms.MaintenanceWindowForm
+	err := decoder.Decode(&maintenanceWindowForm)
+	if err != nil {
+		writeErrorResponse(w, "Invalid input format")
+
+		return
+	}
+
+	validationMessage := maintenanceWindowForm.Validate()
+	if validationMessage != "" {
+		writeErrorResponse(w, validationMessage)
+
+		log.Info("Validation failed while adding maintenance window.")
+		return
+	}
+
+	datastore := db.New()
+	for _, monitoringURLID := range maintenanceWindowForm.MonitorURLIDs {
+		monitoringURL := datastore.GetMonitoringURLByUserID(user.ID, monitoringURLID)
+		if monitoringURL.ID == "" {
+			writeErrorResponse(w, "Monitoring url not found")
+
+			return
+		}
+	}
+
+	maintenanceWindowForm.ID = db.GenerateObjectID().Hex()
+	maintenanceWindowForm.UserID = user.ID
+	maintenanceWindow := datastore.AddMaintenanceWindow(maintenanceWindowForm)
+
+	log.Info("Maintenance window added successfully.")
+
+	responseData := structs.Map(maintenanceWindow)
+	writeSuccessStructResponse(w, responseData, http.StatusCreated)
+}
+
+// GetMaintenanceWindowsHandler gets all maintenance windows of the logged in user.
+func GetMaintenanceWindowsHandler(w http.ResponseWriter, r *http.Request) {
+	authToken := r.Header.Get("Authorization")
+	user, authErr := db.ValidateJWT(authToken)
+
+	if authErr != nil {
+		writeErrorResponse(w, "Authentication failed")
+
+		return
+	}
+
+	datastore := db.New()
+	maintenanceWindows := datastore.GetMaintenanceWindowsByUserID(user.ID)
+	writeSuccessSimpleResponse(w, maintenanceWindows, http.StatusOK)
+}
+
+// GetMaintenanceWindowHandler gets a specific maintenance window.
+func GetMaintenanceWindowHandler(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	maintenanceWindowID := vars["maintenanceWindowID"]
+
+	authToken := r.Header.Get("Authorization")
+	user, authErr := db.ValidateJWT(authToken)
+
+	if authErr != nil {
+		writeErrorResponse(w, "Authentication failed")
+
+		return
+	}
+
+	datastore := db.New()
+	maintenanceWindow := datastore.GetMaintenanceWindowByUserID(user.ID, maintenanceWindowID)
+	if maintenanceWindow.ID == "" {
+		writeErrorResponse(w, "Maintenance window not found")
+
+		return
+	}
+
+	responseData := structs.Map(maintenanceWindow)
+	writeSuccessStructResponse(w, responseData, http.StatusOK)
+}
+
+// DeleteMaintenanceWindowHandler removes a maintenance window.
+func DeleteMaintenanceWindowHandler(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	maintenanceWindowID := vars["maintenanceWindowID"]
+
+	authToken := r.Header.Get("Authorization")
+	user, authErr := db.ValidateJWT(authToken)
+
+	if authErr != nil {
+		writeErrorResponse(w, "Authentication failed")
+
+		return
+	}
+
+	datastore := db.New()
+	datastore.DeleteMaintenanceWindow(user.ID, maintenanceWindowID)
+
+	w.Header().Set("Content-Type", "application/json; charset=UTF-8")
+	w.WriteHeader(http.StatusNoContent)
+	log.Info("Maintenance window removed successfully.")
+}
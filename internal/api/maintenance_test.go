@@ -0,0 +1,102 @@
+package api
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/defraglabs/uptime/internal/db"
+	"github.com/defraglabs/uptime/internal/forms"
+)
+
+func clearMaintenanceWindowCollection() {
+	clearMonitorCollection()
+
+	datastore := db.New()
+	datastore.Client.Database(datastore.DatabaseName).Collection(
+		db.MaintenanceWindowCollection).Drop(context.Background())
+}
+
+func TestAddMaintenanceWindowHandler(t *testing.T) {
+	os.Setenv("MONGO_DATABASE_NAME", "uptime_test")
+	user, jwt := createTestUser()
+	monitoringURLID := addTestMonitorURL(user.ID)
+
+	defer clearMaintenanceWindowCollection()
+
+	maintenanceWindowForm := forms.MaintenanceWindowForm{
+		Name:          "weekly deploy",
+		MonitorURLIDs: []string{monitoringURLID},
+		Action:        "suppressAlerts",
+		Recurrence:    "FREQ=WEEKLY;BYDAY=TU;BYHOUR=22;BYMINUTE=0",
+		Duration:      30,
+		Timezone:      "Europe/Paris",
+	}
+
+	byte, _ := json.Marshal(maintenanceWindowForm)
+	req, err := http.NewRequest("POST", "localhost:8080/api/maintenance-windows", bytes.NewBuffer(byte))
+
+	token := fmt.Sprintf("JWT %s", jwt)
+	req.Header.Add("Authorization", token)
+
+	if err != nil {
+		t.Errorf("Unable to create a new request")
+	}
+
+	responseWriter := httptest.NewRecorder()
+	AddMaintenanceWindowHandler(responseWriter, req)
+
+	res := responseWriter.Result()
+	defer res.Body.Close()
+
+	if res.StatusCode != http.StatusCreated {
+		t.Errorf("expected status CREATED, got %v", res.StatusCode)
+	}
+}
+
+func TestAddMaintenanceWindowWithInvalidRecurrenceHandler(t *testing.T) {
+	os.Setenv("MONGO_DATABASE_NAME", "uptime_test")
+	_, jwt := createTestUser()
+
+	defer clearMaintenanceWindowCollection()
+
+	maintenanceWindowForm := forms.MaintenanceWindowForm{
+		Name:       "deploy",
+		Action:     "skipChecks",
+		Recurrence: "FREQ=DAILY;INTERVAL=2",
+		Duration:   30,
+	}
+
+	byte, _ := json.Marshal(maintenanceWindowForm)
+	req, err := http.NewRequest("POST", "localhost:8080/api/maintenance-windows", bytes.NewBuffer(byte))
+
+	token := fmt.Sprintf("JWT %s", jwt)
+	req.Header.Add("Authorization", token)
+
+	if err != nil {
+		t.Errorf("Unable to create a new request")
+	}
+
+	responseWriter := httptest.NewRecorder()
+	AddMaintenanceWindowHandler(responseWriter, req)
+
+	res := responseWriter.Result()
+	defer res.Body.Close()
+
+	if res.StatusCode != http.StatusBadRequest {
+		t.Errorf("expected status BAD REQUEST, got %v", res.StatusCode)
+	}
+
+	response := Response{}
+	json.NewDecoder(res.Body).Decode(&response)
+
+	if !strings.HasPrefix(response.Error["message"], "Invalid recurrence") {
+		t.Errorf("should not be able to add a window with an unsupported recurrence")
+	}
+}
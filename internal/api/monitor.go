@@ -1,19 +1,21 @@
 package api
 
 import (
+	"encoding/hex"
 	"encoding/json"
 	"fmt"
 	"net/http"
 	"regexp"
 	"strconv"
-	"time"
 
 	"github.com/gorilla/mux"
 
 	"github.com/defraglabs/uptime/internal/db"
 	"github.com/defraglabs/uptime/internal/forms"
+	"github.com/defraglabs/uptime/internal/tasks"
 	"github.com/defraglabs/uptime/internal/utils"
 	"github.com/fatih/structs"
+	"github.com/gofrs/uuid"
 	log "github.com/sirupsen/logrus"
 )
 
@@ -48,20 +50,38 @@ func AddMonitoringURLHandler(w http.ResponseWriter, r *http.Request) {
 	}
 
 	datastore := db.New()
-	var monitoringURL db.MonitorURL
-	monitoringURL = datastore.GetMonitoringURLByUserIDAndURL(user.ID, monitorURLForm.Protocol, monitorURLForm.URL)
+	if !integrationsExist(datastore, user.ID, monitorURLForm.IntegrationIDs) {
+		writeErrorResponse(w, "Integration not found")
 
-	if monitoringURL.ID != "" {
-		writeErrorResponse(w, "URL already exists.")
+		return
+	} else if !escalationPolicyExists(datastore, user.ID, monitorURLForm.EscalationPolicyID) {
+		writeErrorResponse(w, "Escalation policy not found")
 
 		return
 	}
+	var monitoringURL db.MonitorURL
+	isHeartbeat := monitorURLForm.Type == utils.MonitorTypeHeartbeat
+
+	// Heartbeat monitors have no url, they are pinged on an url of their own.
+	if isHeartbeat {
+		monitorURLForm.HeartbeatToken = hex.EncodeToString(uuid.Must(uuid.NewV4()).Bytes())
+	} else {
+		monitoringURL = datastore.GetMonitoringURLByUserIDAndURL(user.ID, monitorURLForm.Protocol, monitorURLForm.URL)
+
+		if monitoringURL.ID != "" {
+			writeErrorResponse(w, "URL already exists.")
+
+			return
+		}
+	}
 
 	objectID := db.GenerateObjectID()
 	monitorURLForm.ID = objectID.Hex()
 
 	monitoringURL = datastore.AddMonitoringURL(monitorURLForm)
-	initialPingMonitorURL(monitoringURL, datastore)
+	if !isHeartbeat {
+		initialPingMonitorURL(monitoringURL)
+	}
 
 	log.Info(fmt.Sprintf("Added monitoring url %s", monitorURLForm.URL))
 
@@ -72,30 +92,31 @@ func AddMonitoringURLHandler(w http.ResponseWriter, r *http.Request) {
 	writeSuccessStructResponse(w, responseData, http.StatusCreated)
 }
 
-func initialPingMonitorURL(monitorURL db.MonitorURL, datastore *db.Datastore) {
-	start := time.Now()
-	url := fmt.Sprintf("%s://%s", monitorURL.Protocol, monitorURL.URL)
-
-	timeout := time.Duration(5 * time.Second)
-	client := http.Client{
-		Timeout: timeout,
+// integrationsExist checks that the integrations belong to the user.
+func integrationsExist(datastore *db.Datastore, userID string, integrationIDs []string) bool {
+	for _, integrationID := range integrationIDs {
+		integration := datastore.GetIntegrationByUserID(userID, integrationID)
+		if integration.ID == "" {
+			return false
+		}
 	}
-	client.Get(url)
 
-	resp, err := client.Get(url)
-	responseTime := time.Since(start)
-	if err != nil {
-		// Don't fail like this.
-		log.Warn("API ping failed")
-	}
+	return true
+}
 
-	t := time.Now()
-	timeStamp := t.Format(time.UnixDate)
+// escalationPolicyExists checks that the escalation policy, if any, belongs to the user.
+func escalationPolicyExists(datastore *db.Datastore, userID, escalationPolicyID string) bool {
+	if escalationPolicyID == "" {
+		return true
+	}
 
-	responseTimeInMillSeconds := float64(responseTime.Nanoseconds()) / 1000000
+	escalationPolicy := datastore.GetEscalationPolicyByUserID(userID, escalationPolicyID)
+	return escalationPolicy.ID != ""
+}
 
-	serviceStatus := utils.GetServiceStatus(resp.StatusCode)
-	datastore.AddMonitorDetail(monitorURL, resp.Status, serviceStatus, timeStamp, responseTimeInMillSeconds)
+func initialPingMonitorURL(monitorURL db.MonitorURL) {
+	result := tasks.CheckMonitorURL(monitorURL)
+	tasks.RecordMonitorResult(monitorURL, result)
 }
 
 // GetMonitoringURLsHandler api returns the monitoring urls configured
@@ -202,6 +223,8 @@ func MonitoringURLActionHandler(w http.ResponseWriter, r *http.Request) {
 //   - Protocol
 //   - Frequency
 //   - Unit
+//   - Request options (method, headers, body, content type, timeout, user agent)
+//   - Assertions
 func UpdateMonitoringURLHandler(w http.ResponseWriter, r *http.Request) {
 	authToken := r.Header.Get("Authorization")
 	user, authErr := db.ValidateJWT(authToken)
@@ -234,6 +257,21 @@ func UpdateMonitoringURLHandler(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	validationMessage := monitorURLForm.ValidateUpdate()
+	if validationMessage != "" {
+		writeErrorResponse(w, validationMessage)
+
+		return
+	} else if !integrationsExist(datastore, user.ID, monitorURLForm.IntegrationIDs) {
+		writeErrorResponse(w, "Integration not found")
+
+		return
+	} else if !escalationPolicyExists(datastore, user.ID, monitorURLForm.EscalationPolicyID) {
+		writeErrorResponse(w, "Escalation policy not found")
+
+		return
+	}
+
 	monitorURLForm.ID = monitoringURLID
 	monitorURLForm.UserID = user.ID
 
@@ -313,10 +351,32 @@ func GetMonitoringURLStatsHandler(w http.ResponseWriter, r *http.Request) {
 
 	data := make(map[string]interface{})
 	data["monitorResults"] = monitorResults
+	data["uptime"] = getUptime(monitorResults)
 
 	writeSuccessStructResponse(w, data, http.StatusOK)
 }
 
+// getUptime returns the percentage of UP results, results recorded during maintenance excluded.
+// Returns 100 when there are no results.
+func getUptime(monitorResults []db.MonitorResult) float64 {
+	var total, up int
+	for _, monitorResult := range monitorResults {
+		if monitorResult.Status == "" || monitorResult.Maintenance {
+			continue
+		}
+
+		total++
+		if monitorResult.Status == utils.StatusUp {
+			up++
+		}
+	}
+
+	if total == 0 {
+		return 100
+	}
+	return float64(up) * 100 / float64(total)
+}
+
 func validateInterval(interval string) bool {
 	matched, _ := regexp.MatchString("([0-9]+)-([a-z]+)", interval)
 
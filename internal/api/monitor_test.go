@@ -98,6 +98,199 @@ func TestAddDuplicateMonitoringURL(t *testing.T) {
 	}
 }
 
+func TestAddMonitoringURLWithInvalidAssertion(t *testing.T) {
+	os.Setenv("MONGO_DATABASE_NAME", "uptime_test")
+	_, jwt := createTestUser()
+
+	defer clearMonitorCollection()
+
+	monitorURLForm := forms.MonitorURLForm{
+		Protocol:  "http",
+		Name:      "example",
+		URL:       "example.com",
+		Frequency: 5,
+		Unit:      "minute",
+		Assertions: []forms.AssertionForm{
+			{
+				Type:       "jsonPath",
+				Property:   "$.healthy",
+				Comparison: "matches",
+				Value:      "true",
+			},
+		},
+	}
+
+	byte, _ := json.Marshal(monitorURLForm)
+	req, err := http.NewRequest("POST", "localhost:8080/api/monitoring-urls", bytes.NewBuffer(byte))
+
+	token := fmt.Sprintf("JWT %s", jwt)
+	req.Header.Add("Authorization", token)
+
+	if err != nil {
+		t.Errorf("Unable to create a new request")
+	}
+
+	responseWriter := httptest.NewRecorder()
+	AddMonitoringURLHandler(responseWriter, req)
+
+	res := responseWriter.Result()
+	defer res.Body.Close()
+
+	if res.StatusCode != http.StatusBadRequest {
+		t.Errorf("expected status BAD REQUEST, got %v", res.StatusCode)
+	}
+
+	response := Response{}
+	json.NewDecoder(res.Body).Decode(&response)
+
+	if response.Error["message"] != "invalid assertion comparison matches" {
+		t.Errorf("should not be able to add monitoring url with an invalid assertion")
+	}
+}
+
+func TestAddTCPMonitoringURLWithInvalidAddress(t *testing.T) {
+	os.Setenv("MONGO_DATABASE_NAME", "uptime_test")
+	_, jwt := createTestUser()
+
+	defer clearMonitorCollection()
+
+	monitorURLForm := forms.MonitorURLForm{
+		Type:      "tcp",
+		Name:      "database",
+		URL:       "db.example.com",
+		Frequency: 5,
+		Unit:      "minute",
+	}
+
+	byte, _ := json.Marshal(monitorURLForm)
+	req, err := http.NewRequest("POST", "localhost:8080/api/monitoring-urls", bytes.NewBuffer(byte))
+
+	token := fmt.Sprintf("JWT %s", jwt)
+	req.Header.Add("Authorization", token)
+
+	if err != nil {
+		t.Errorf("Unable to create a new request")
+	}
+
+	responseWriter := httptest.NewRecorder()
+	AddMonitoringURLHandler(responseWriter, req)
+
+	res := responseWriter.Result()
+	defer res.Body.Close()
+
+	if res.StatusCode != http.StatusBadRequest {
+		t.Errorf("expected status BAD REQUEST, got %v", res.StatusCode)
+	}
+
+	response := Response{}
+	json.NewDecoder(res.Body).Decode(&response)
+
+	if response.Error["message"] != "URL should be of the format host:port for tcp monitors" {
+		t.Errorf("should not be able to add tcp monitor without a port")
+	}
+}
+
+func TestAddMonitoringURLWithUnknownIntegration(t *testing.T) {
+	os.Setenv("MONGO_DATABASE_NAME", "uptime_test")
+	_, jwt := createTestUser()
+
+	defer clearMonitorCollection()
+
+	monitorURLForm := forms.MonitorURLForm{
+		Protocol:       "http",
+		Name:           "example",
+		URL:            "example.com",
+		Frequency:      5,
+		Unit:           "minute",
+		IntegrationIDs: []string{"unknown"},
+	}
+
+	byte, _ := json.Marshal(monitorURLForm)
+	req, err := http.NewRequest("POST", "localhost:8080/api/monitoring-urls", bytes.NewBuffer(byte))
+
+	token := fmt.Sprintf("JWT %s", jwt)
+	req.Header.Add("Authorization", token)
+
+	if err != nil {
+		t.Errorf("Unable to create a new request")
+	}
+
+	responseWriter := httptest.NewRecorder()
+	AddMonitoringURLHandler(responseWriter, req)
+
+	res := responseWriter.Result()
+	defer res.Body.Close()
+
+	if res.StatusCode != http.StatusBadRequest {
+		t.Errorf("expected status BAD REQUEST, got %v", res.StatusCode)
+	}
+
+	response := Response{}
+	json.NewDecoder(res.Body).Decode(&response)
+
+	if response.Error["message"] != "Integration not found" {
+		t.Errorf("should not be able to route alerts to an unknown integration")
+	}
+}
+
+func TestAddHeartbeatMonitoringURLAndPing(t *testing.T) {
+	os.Setenv("MONGO_DATABASE_NAME", "uptime_test")
+	_, jwt := createTestUser()
+
+	defer clearMonitorCollection()
+
+	monitorURLForm := forms.MonitorURLForm{
+		Type:           "heartbeat",
+		Name:           "nightly backup",
+		CronExpression: "0 3 * * *",
+		GracePeriod:    600,
+	}
+
+	byte, _ := json.Marshal(monitorURLForm)
+	req, err := http.NewRequest("POST", "localhost:8080/api/monitoring-urls", bytes.NewBuffer(byte))
+
+	token := fmt.Sprintf("JWT %s", jwt)
+	req.Header.Add("Authorization", token)
+
+	if err != nil {
+		t.Errorf("Unable to create a new request")
+	}
+
+	responseWriter := httptest.NewRecorder()
+	AddMonitoringURLHandler(responseWriter, req)
+
+	res := responseWriter.Result()
+	defer res.Body.Close()
+
+	if res.StatusCode != http.StatusCreated {
+		t.Errorf("expected status CREATED, got %v", res.StatusCode)
+	}
+
+	response := StructResponse{}
+	json.NewDecoder(res.Body).Decode(&response)
+
+	heartbeatToken, _ := response.Data["heartbeatToken"].(string)
+	if heartbeatToken == "" {
+		t.Fatalf("heartbeat token is missing")
+	}
+
+	req, _ = http.NewRequest("POST", fmt.Sprintf("localhost:8080/api/heartbeat/%s", heartbeatToken), nil)
+	vars := map[string]string{
+		"token": heartbeatToken,
+	}
+	req = mux.SetURLVars(req, vars)
+
+	responseWriter = httptest.NewRecorder()
+	HeartbeatHandler(responseWriter, req)
+
+	pingRes := responseWriter.Result()
+	defer pingRes.Body.Close()
+
+	if pingRes.StatusCode != http.StatusOK {
+		t.Errorf("expected status OK, got %v", pingRes.StatusCode)
+	}
+}
+
 func TestGetMonitoringURLsHandler(t *testing.T) {
 	os.Setenv("MONGO_DATABASE_NAME", "uptime_test")
 	user, jwt := createTestUser()
@@ -231,6 +424,236 @@ func TestUpdateMonitoringURLHandler(t *testing.T) {
 	}
 }
 
+func TestUpdateMonitoringURLClearsEscalationPolicyHandler(t *testing.T) {
+	os.Setenv("MONGO_DATABASE_NAME", "uptime_test")
+	user, jwt := createTestUser()
+	monitoringURLID := addTestMonitorURL(user.ID)
+	defer clearMonitorCollection()
+
+	datastore := db.New()
+	datastore.UpdateMonitoringURLByUserID(user.ID, monitoringURLID, forms.MonitorURLForm{EscalationPolicyID: "escalation-policy-id"})
+
+	monitorURLForm := forms.MonitorURLForm{ClearEscalationPolicy: true}
+
+	byte, _ := json.Marshal(monitorURLForm)
+
+	req, err := http.NewRequest("PUT", "localhost:8080/api/monitoring-urls", bytes.NewBuffer(byte))
+	token := fmt.Sprintf("JWT %s", jwt)
+	req.Header.Add("Authorization", token)
+
+	if err != nil {
+		t.Errorf("Unable to create a new request")
+	}
+
+	responseWriter := httptest.NewRecorder()
+
+	vars := map[string]string{
+		"monitoringURLID": monitoringURLID,
+	}
+	req = mux.SetURLVars(req, vars)
+
+	UpdateMonitoringURLHandler(responseWriter, req)
+
+	res := responseWriter.Result()
+	defer res.Body.Close()
+
+	if res.StatusCode != http.StatusOK {
+		t.Errorf("expected status OK, got %v", res.StatusCode)
+	}
+
+	monitorURL := datastore.GetMonitoringURLByUserID(user.ID, monitoringURLID)
+	if monitorURL.EscalationPolicyID != "" {
+		t.Errorf("expected the escalation policy to be cleared, got %s", monitorURL.EscalationPolicyID)
+	}
+}
+
+func TestUpdateMonitoringURLClearsReminderHandler(t *testing.T) {
+	os.Setenv("MONGO_DATABASE_NAME", "uptime_test")
+	user, jwt := createTestUser()
+	monitoringURLID := addTestMonitorURL(user.ID)
+	defer clearMonitorCollection()
+
+	datastore := db.New()
+	datastore.UpdateMonitoringURLByUserID(user.ID, monitoringURLID, forms.MonitorURLForm{ReminderInterval: 10, ReminderMaxCount: 3})
+
+	monitorURLForm := forms.MonitorURLForm{ReminderInterval: 30, ClearReminder: true}
+
+	byte, _ := json.Marshal(monitorURLForm)
+
+	req, err := http.NewRequest("PUT", "localhost:8080/api/monitoring-urls", bytes.NewBuffer(byte))
+	token := fmt.Sprintf("JWT %s", jwt)
+	req.Header.Add("Authorization", token)
+
+	if err != nil {
+		t.Errorf("Unable to create a new request")
+	}
+
+	responseWriter := httptest.NewRecorder()
+
+	vars := map[string]string{
+		"monitoringURLID": monitoringURLID,
+	}
+	req = mux.SetURLVars(req, vars)
+
+	UpdateMonitoringURLHandler(responseWriter, req)
+
+	res := responseWriter.Result()
+	defer res.Body.Close()
+
+	if res.StatusCode != http.StatusOK {
+		t.Errorf("expected status OK, got %v", res.StatusCode)
+	}
+
+	monitorURL := datastore.GetMonitoringURLByUserID(user.ID, monitoringURLID)
+	if monitorURL.ReminderInterval != 30 || monitorURL.ReminderMaxCount != 0 {
+		t.Errorf("expected a reminder every 30 minutes without limit, got every %d up to %d", monitorURL.ReminderInterval, monitorURL.ReminderMaxCount)
+	}
+}
+
+func TestUpdateMonitoringURLWithInvalidMethodHandler(t *testing.T) {
+	os.Setenv("MONGO_DATABASE_NAME", "uptime_test")
+	user, jwt := createTestUser()
+	monitoringURLID := addTestMonitorURL(user.ID)
+	defer clearMonitorCollection()
+
+	monitorURLForm := forms.MonitorURLForm{
+		Protocol:  "https",
+		Frequency: 30,
+		Unit:      "second",
+		Method:    "FETCH",
+	}
+
+	byte, _ := json.Marshal(monitorURLForm)
+
+	req, err := http.NewRequest("PUT", "localhost:8080/api/monitoring-urls", bytes.NewBuffer(byte))
+	token := fmt.Sprintf("JWT %s", jwt)
+	req.Header.Add("Authorization", token)
+
+	if err != nil {
+		t.Errorf("Unable to create a new request")
+	}
+
+	responseWriter := httptest.NewRecorder()
+
+	// Add url path parameter
+	vars := map[string]string{
+		"monitoringURLID": monitoringURLID,
+	}
+	req = mux.SetURLVars(req, vars)
+
+	UpdateMonitoringURLHandler(responseWriter, req)
+
+	res := responseWriter.Result()
+	defer res.Body.Close()
+
+	if res.StatusCode != http.StatusBadRequest {
+		t.Errorf("expected status BAD REQUEST, got %v", res.StatusCode)
+	}
+
+	response := Response{}
+	json.NewDecoder(res.Body).Decode(&response)
+
+	if response.Error["message"] != "Invalid method" {
+		t.Errorf("should not be able to update monitoring url with an invalid method")
+	}
+}
+
+func TestUpdateMonitoringURLWithInvalidFailureThresholdHandler(t *testing.T) {
+	os.Setenv("MONGO_DATABASE_NAME", "uptime_test")
+	user, jwt := createTestUser()
+	monitoringURLID := addTestMonitorURL(user.ID)
+	defer clearMonitorCollection()
+
+	monitorURLForm := forms.MonitorURLForm{
+		Protocol:         "https",
+		Frequency:        30,
+		Unit:             "second",
+		FailureThreshold: 50,
+	}
+
+	byte, _ := json.Marshal(monitorURLForm)
+
+	req, err := http.NewRequest("PUT", "localhost:8080/api/monitoring-urls", bytes.NewBuffer(byte))
+	token := fmt.Sprintf("JWT %s", jwt)
+	req.Header.Add("Authorization", token)
+
+	if err != nil {
+		t.Errorf("Unable to create a new request")
+	}
+
+	responseWriter := httptest.NewRecorder()
+
+	// Add url path parameter
+	vars := map[string]string{
+		"monitoringURLID": monitoringURLID,
+	}
+	req = mux.SetURLVars(req, vars)
+
+	UpdateMonitoringURLHandler(responseWriter, req)
+
+	res := responseWriter.Result()
+	defer res.Body.Close()
+
+	if res.StatusCode != http.StatusBadRequest {
+		t.Errorf("expected status BAD REQUEST, got %v", res.StatusCode)
+	}
+
+	response := Response{}
+	json.NewDecoder(res.Body).Decode(&response)
+
+	if response.Error["message"] != "Failure threshold should be between 1 and 10" {
+		t.Errorf("should not be able to update monitoring url with a failure threshold above the max")
+	}
+}
+
+func TestUpdateMonitoringURLWithInvalidReminderIntervalHandler(t *testing.T) {
+	os.Setenv("MONGO_DATABASE_NAME", "uptime_test")
+	user, jwt := createTestUser()
+	monitoringURLID := addTestMonitorURL(user.ID)
+	defer clearMonitorCollection()
+
+	monitorURLForm := forms.MonitorURLForm{
+		Protocol:         "https",
+		Frequency:        30,
+		Unit:             "second",
+		ReminderInterval: 2000,
+	}
+
+	byte, _ := json.Marshal(monitorURLForm)
+
+	req, err := http.NewRequest("PUT", "localhost:8080/api/monitoring-urls", bytes.NewBuffer(byte))
+	token := fmt.Sprintf("JWT %s", jwt)
+	req.Header.Add("Authorization", token)
+
+	if err != nil {
+		t.Errorf("Unable to create a new request")
+	}
+
+	responseWriter := httptest.NewRecorder()
+
+	// Add url path parameter
+	vars := map[string]string{
+		"monitoringURLID": monitoringURLID,
+	}
+	req = mux.SetURLVars(req, vars)
+
+	UpdateMonitoringURLHandler(responseWriter, req)
+
+	res := responseWriter.Result()
+	defer res.Body.Close()
+
+	if res.StatusCode != http.StatusBadRequest {
+		t.Errorf("expected status BAD REQUEST, got %v", res.StatusCode)
+	}
+
+	response := Response{}
+	json.NewDecoder(res.Body).Decode(&response)
+
+	if response.Error["message"] != "Reminder interval should be between 1 and 1440 minutes" {
+		t.Errorf("should not be able to update monitoring url with a reminder interval above the max")
+	}
+}
+
 func TestMonitoringURLActionHandler(t *testing.T) {
 	os.Setenv("MONGO_DATABASE_NAME", "uptime_test")
 	user, jwt := createTestUser()
@@ -0,0 +1,64 @@
+package api
+
+import (
+	"net/http"
+
+	"github.com/gorilla/mux"
+
+	"github.com/defraglabs/uptime/internal/db"
+)
+
+// notificationHistoryLimit is the number of notifications returned, latest first.
+const notificationHistoryLimit = 100
+
+// GetIntegrationNotificationsHandler lists the delivery history of an integration.
+func GetIntegrationNotificationsHandler(w http.ResponseWriter, r *http.Request) {
+	authToken := r.Header.Get("Authorization")
+	user, authErr := db.ValidateJWT(authToken)
+
+	if authErr != nil {
+		writeErrorResponse(w, "Authentication failed")
+
+		return
+	}
+
+	vars := mux.Vars(r)
+	integrationID := vars["integrationID"]
+
+	datastore := db.New()
+	integration := datastore.GetIntegrationByUserID(user.ID, integrationID)
+	if integration.ID == "" {
+		writeErrorResponse(w, "Integration not found")
+
+		return
+	}
+
+	notifications := datastore.GetNotificationsByIntegrationID(user.ID, integrationID, notificationHistoryLimit)
+	writeSuccessSimpleResponse(w, notifications, http.StatusOK)
+}
+
+// GetMonitoringURLNotificationsHandler lists the delivery history of the alerts of a monitoring url.
+func GetMonitoringURLNotificationsHandler(w http.ResponseWriter, r *http.Request) {
+	authToken := r.Header.Get("Authorization")
+	user, authErr := db.ValidateJWT(authToken)
+
+	if authErr != nil {
+		writeErrorResponse(w, "Authentication failed")
+
+		return
+	}
+
+	vars := mux.Vars(r)
+	monitoringURLID := vars["monitoringURLID"]
+
+	datastore := db.New()
+	monitoringURL := datastore.GetMonitoringURLByUserID(user.ID, monitoringURLID)
+	if monitoringURL.ID == "" {
+		writeErrorResponse(w, "Monitoring url not found")
+
+		return
+	}
+
+	notifications := datastore.GetNotificationsByMonitorURLID(user.ID, monitoringURLID, notificationHistoryLimit)
+	writeSuccessSimpleResponse(w, notifications, http.StatusOK)
+}
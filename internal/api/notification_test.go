@@ -0,0 +1,70 @@
+package api
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"testing"
+
+	"github.com/defraglabs/uptime/internal/db"
+	"github.com/gorilla/mux"
+)
+
+func clearNotificationCollection() {
+	clearIntegrationCollection()
+
+	datastore := db.New()
+	datastore.Client.Database(datastore.DatabaseName).Collection(
+		db.NotificationCollection).Drop(context.Background())
+}
+
+func TestGetIntegrationNotificationsHandler(t *testing.T) {
+	os.Setenv("MONGO_DATABASE_NAME", "uptime_test")
+	user, jwt := createTestUser()
+	integrationID := addTestIntegration(user.ID)
+	defer clearNotificationCollection()
+
+	datastore := db.New()
+	integration := datastore.GetIntegrationByUserID(user.ID, integrationID)
+	alert := db.NewStatusAlert(
+		db.MonitorURL{ID: "monitor-url-id", UserID: user.ID, URL: "example.com"},
+		"UP",
+		db.MonitorResult{Status: "DOWN"},
+	)
+	datastore.AddNotification(db.NewNotification(integration, alert))
+
+	req, err := http.NewRequest("GET", fmt.Sprintf("localhost:8080/api/integrations/%s/notifications", integrationID), nil)
+
+	token := fmt.Sprintf("JWT %s", jwt)
+	req.Header.Add("Authorization", token)
+
+	if err != nil {
+		t.Errorf("Unable to create a new request")
+	}
+
+	vars := map[string]string{
+		"integrationID": integrationID,
+	}
+	req = mux.SetURLVars(req, vars)
+
+	responseWriter := httptest.NewRecorder()
+	GetIntegrationNotificationsHandler(responseWriter, req)
+
+	res := responseWriter.Result()
+	defer res.Body.Close()
+
+	if res.StatusCode != http.StatusOK {
+		t.Errorf("expected status OK, got %v", res.StatusCode)
+	}
+
+	response := SimpleResponse{}
+	json.NewDecoder(res.Body).Decode(&response)
+
+	notifications, _ := response.Data.([]interface{})
+	if len(notifications) != 1 {
+		t.Errorf("expected 1 notification, got %d", len(notifications))
+	}
+}
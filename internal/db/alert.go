@@ -0,0 +1,269 @@
+package db
+
+import (
+	"bytes"
+	"fmt"
+	"html/template"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/defraglabs/uptime/internal/utils"
+)
+
+const (
+	// AlertTypeStatus is sent when a monitor url goes UP or DOWN.
+	AlertTypeStatus = "status"
+
+	// AlertTypeCertificate is sent when the certificate of a monitor url is about to expire or is invalid.
+	AlertTypeCertificate = "certificate"
+
+	// AlertTypeFlapping is sent when a monitor url starts flapping. Status alerts are
+	// suppressed until it stabilizes.
+	AlertTypeFlapping = "flapping"
+
+	// AlertTypeStabilized is sent when a monitor url stops flapping.
+	AlertTypeStabilized = "stabilized"
+
+	// AlertTypeReminder is sent every reminder interval while a monitor url stays DOWN.
+	AlertTypeReminder = "reminder"
+
+	// AlertTypeTest is sent on demand to check that an integration works.
+	AlertTypeTest = "test"
+)
+
+const (
+	// alertColorUp is the color of the alerts about monitor urls which are UP.
+	alertColorUp = "#2EB67D"
+
+	// alertColorDown is the color of the alerts about monitor urls which are DOWN.
+	alertColorDown = "#E01E5A"
+
+	// alertColorWarning is the color of the warnings about certificates & flapping.
+	alertColorWarning = "#ECB22E"
+)
+
+// alertEmailTemplate is the html body of the email alerts.
+var alertEmailTemplate = template.Must(template.New("alert").Parse(`<html>
+<body style="font-family: sans-serif;">
+	<h2>{{.Summary}}</h2>
+	<table cellpadding="4">
+		{{range .Details}}<tr><td><strong>{{.Name}}</strong></td><td>{{.Value}}</td></tr>
+		{{end}}
+	</table>
+</body>
+</html>
+`))
+
+// AlertDetail is a line in the body of the alert, e.g. the url or the response time.
+type AlertDetail struct {
+	Name  string
+	Value string
+}
+
+// Alert is the notification sent through the integrations.
+type Alert struct {
+	// Type of the alert, status or certificate.
+	Type string `bson:"type" json:"type"`
+
+	MonitorURL MonitorURL `bson:"monitorURL" json:"monitorURL"`
+
+	// Status of the monitor url, UP or DOWN.
+	Status string `bson:"status" json:"status"`
+
+	// PreviousStatus is the status of the monitor url before the check which raised the alert.
+	PreviousStatus string `bson:"previousStatus" json:"previousStatus"`
+
+	// Result of the check which raised the alert.
+	Result MonitorResult `bson:"result" json:"result"`
+
+	// Message overrides the default summary of the alert.
+	Message string `bson:"message" json:"message"`
+
+	// IncidentID is set on the alerts escalated through an escalation policy.
+	IncidentID string `bson:"incidentID" json:"incidentID"`
+
+	Time time.Time `bson:"time" json:"time"`
+}
+
+// NewStatusAlert creates the alert sent when the monitor url changes status.
+func NewStatusAlert(monitorURL MonitorURL, previousStatus string, result MonitorResult) Alert {
+	return Alert{
+		Type:           AlertTypeStatus,
+		MonitorURL:     monitorURL,
+		Status:         result.Status,
+		PreviousStatus: previousStatus,
+		Result:         result,
+		Time:           time.Now(),
+	}
+}
+
+// NewFlappingAlert creates the alert sent when the monitor url starts or stops flapping.
+func NewFlappingAlert(monitorURL MonitorURL, flapping bool, status string, result MonitorResult) Alert {
+	alert := Alert{
+		Type:       AlertTypeFlapping,
+		MonitorURL: monitorURL,
+		Status:     status,
+		Result:     result,
+		Message:    fmt.Sprintf("Site %s is flapping between UP and DOWN", getSiteName(monitorURL)),
+		Time:       time.Now(),
+	}
+
+	if !flapping {
+		alert.Type = AlertTypeStabilized
+		alert.Message = fmt.Sprintf("Site %s stabilized, it is %s", getSiteName(monitorURL), status)
+	}
+
+	return alert
+}
+
+// NewReminderAlert creates the reminder sent while the monitor url stays DOWN.
+func NewReminderAlert(monitorURL MonitorURL, t time.Time) Alert {
+	alert := Alert{
+		Type:       AlertTypeReminder,
+		MonitorURL: monitorURL,
+		Status:     utils.StatusDown,
+		Time:       t,
+	}
+
+	alert.Message = fmt.Sprintf("Site %s is still DOWN after %s", getSiteName(monitorURL), utils.FormatDuration(alert.Downtime()))
+	return alert
+}
+
+// NewTestAlert creates the test notification of the integration. Its monitor url is made up.
+func NewTestAlert(integration Integration, t time.Time) Alert {
+	return Alert{
+		Type: AlertTypeTest,
+		MonitorURL: MonitorURL{
+			ID:     fmt.Sprintf("test-%s", integration.ID),
+			UserID: integration.UserID,
+			Name:   "Test monitor",
+		},
+		Message: fmt.Sprintf("[TEST] Test notification of your %s integration from Uptime, no action needed", integration.Type),
+		Time:    t,
+	}
+}
+
+// Downtime returns how long the monitor url has been DOWN at the time of the alert, the total
+// downtime for the UP alert. Zero when unknown.
+func (alert Alert) Downtime() time.Duration {
+	if alert.MonitorURL.DownSince.IsZero() || alert.Time.Before(alert.MonitorURL.DownSince) {
+		return 0
+	}
+
+	return alert.Time.Sub(alert.MonitorURL.DownSince)
+}
+
+// Resolves tells whether the alert ends the outage it follows up on: UP ends DOWN, and so does
+// stabilizing UP end flapping. Stabilizing DOWN keeps the outage open.
+func (alert Alert) Resolves() bool {
+	isStatus := alert.Type == AlertTypeStatus || alert.Type == AlertTypeStabilized
+	return isStatus && alert.Status == utils.StatusUp
+}
+
+// DedupKey returns the key identifying the outage of the alert in the incident management tools.
+// It is stable per monitor url, so the recovery resolves the incident the failure opened. Flapping
+// shares the key of the status alerts, as status alerts are suppressed until it stabilizes.
+func (alert Alert) DedupKey() string {
+	dedupKey := fmt.Sprintf("uptime-%s", alert.MonitorURL.ID)
+	if alert.Type == AlertTypeCertificate {
+		return dedupKey + "-certificate"
+	}
+
+	return dedupKey
+}
+
+// Color returns the hex color of the alert in the chat integrations: red when DOWN, green when UP
+// and yellow for the warnings about certificates & flapping.
+func (alert Alert) Color() string {
+	if alert.Type == AlertTypeCertificate || alert.Type == AlertTypeFlapping {
+		return alertColorWarning
+	} else if alert.Status == utils.StatusDown {
+		return alertColorDown
+	}
+
+	return alertColorUp
+}
+
+// Link returns the page of the monitor url in the dashboard when DASHBOARD_URL is configured,
+// the url of http monitors otherwise. Empty when there is nothing to link to.
+func (alert Alert) Link() string {
+	if dashboardURL := utils.GetDashboardURL(); dashboardURL != "" {
+		return fmt.Sprintf("%s/monitoring-urls/%s", dashboardURL, alert.MonitorURL.ID)
+	} else if alert.MonitorURL.Protocol != "" && (alert.MonitorURL.Type == "" || alert.MonitorURL.Type == utils.MonitorTypeHTTP) {
+		return fmt.Sprintf("%s://%s", alert.MonitorURL.Protocol, alert.MonitorURL.URL)
+	}
+
+	return ""
+}
+
+// getSiteName returns the url of the monitor url, or its name for heartbeat monitors which have no url.
+func getSiteName(monitorURL MonitorURL) string {
+	if monitorURL.URL == "" {
+		return monitorURL.Name
+	}
+
+	return monitorURL.URL
+}
+
+// Summary returns a one line description of the alert.
+func (alert Alert) Summary() string {
+	if alert.Message != "" {
+		return alert.Message
+	}
+
+	return fmt.Sprintf("Site %s %s", getSiteName(alert.MonitorURL), alert.Status)
+}
+
+// Details returns the details of the monitor url & of the check shown in the body of the alert.
+func (alert Alert) Details() []AlertDetail {
+	details := []AlertDetail{{"Monitor", alert.MonitorURL.Name}}
+
+	if alert.MonitorURL.URL != "" {
+		details = append(details, AlertDetail{"URL", alert.MonitorURL.URL})
+	}
+	if alert.Status != "" {
+		details = append(details, AlertDetail{"Status", alert.Status})
+	}
+	if alert.Result.StatusCode != 0 {
+		details = append(details, AlertDetail{"Status code", strconv.Itoa(alert.Result.StatusCode)})
+	}
+	if alert.Result.StatusDescription != "" {
+		details = append(details, AlertDetail{"Description", alert.Result.StatusDescription})
+	}
+	if alert.Result.FailedAssertion != "" {
+		details = append(details, AlertDetail{"Failed assertion", alert.Result.FailedAssertion})
+	}
+	if alert.Result.ResponseTime > 0 {
+		details = append(details, AlertDetail{"Response time", fmt.Sprintf("%.0f ms", alert.Result.ResponseTime)})
+	}
+	if downtime := alert.Downtime(); downtime > 0 && alert.Status == utils.StatusDown {
+		details = append(details, AlertDetail{"Down for", utils.FormatDuration(downtime)})
+	} else if downtime > 0 && alert.Type == AlertTypeStatus {
+		details = append(details, AlertDetail{"Downtime", utils.FormatDuration(downtime)})
+	}
+	if alert.IncidentID != "" {
+		details = append(details, AlertDetail{"Incident", alert.IncidentID})
+	}
+
+	details = append(details, AlertDetail{"Time", alert.Time.UTC().Format(time.RFC1123)})
+	return details
+}
+
+// Text returns the plain text body of the alert.
+func (alert Alert) Text() string {
+	lines := []string{alert.Summary(), ""}
+	for _, detail := range alert.Details() {
+		lines = append(lines, fmt.Sprintf("%s: %s", detail.Name, detail.Value))
+	}
+
+	return strings.Join(lines, "\n") + "\n"
+}
+
+// HTML returns the html body of the alert.
+func (alert Alert) HTML() (string, error) {
+	var body bytes.Buffer
+	err := alertEmailTemplate.Execute(&body, alert)
+
+	return body.String(), err
+}
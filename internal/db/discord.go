@@ -0,0 +1,96 @@
+package db
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	log "github.com/sirupsen/logrus"
+)
+
+// discordMessage is the payload of discord webhooks.
+type discordMessage struct {
+	Username string         `json:"username,omitempty"`
+	Embeds   []discordEmbed `json:"embeds"`
+}
+
+type discordEmbed struct {
+	Title     string              `json:"title"`
+	URL       string              `json:"url,omitempty"`
+	Color     int64               `json:"color"`
+	Fields    []discordEmbedField `json:"fields"`
+	Timestamp string              `json:"timestamp"`
+}
+
+type discordEmbedField struct {
+	Name   string `json:"name"`
+	Value  string `json:"value"`
+	Inline bool   `json:"inline"`
+}
+
+// discordNotifier posts the alerts as embeds to a discord webhook.
+type discordNotifier struct {
+	WebhookURL string `bson:"webhookURL" json:"webhookURL"`
+
+	// Username overrides the name of the webhook in the channel.
+	Username string `bson:"username" json:"username"`
+}
+
+// Validate validates the discord config.
+func (notifier *discordNotifier) Validate() string {
+	return validateWebhookURL(notifier.WebhookURL, DiscordIntegration)
+}
+
+// newDiscordMessage builds the embed of the alert. The time of the alert is the timestamp of the embed.
+func (notifier *discordNotifier) newDiscordMessage(alert Alert) discordMessage {
+	fields := []discordEmbedField{}
+	for _, detail := range alert.Details() {
+		if detail.Name == "Time" {
+			continue
+		}
+
+		fields = append(fields, discordEmbedField{Name: detail.Name, Value: detail.Value, Inline: true})
+	}
+
+	color, _ := strconv.ParseInt(strings.TrimPrefix(alert.Color(), "#"), 16, 64)
+
+	return discordMessage{
+		Username: notifier.Username,
+		Embeds: []discordEmbed{{
+			Title:     alert.Summary(),
+			URL:       alert.Link(),
+			Color:     color,
+			Fields:    fields,
+			Timestamp: alert.Time.UTC().Format(time.RFC3339),
+		}},
+	}
+}
+
+// Send posts the embed of the alert to the discord webhook.
+func (notifier *discordNotifier) Send(integration Integration, alert Alert) (Delivery, error) {
+	if notifier.WebhookURL == "" {
+		log.Infof("Invalid integration. Webhook url not found for integration %s", integration.ID)
+
+		return Delivery{}, errors.New("invalid integration. webhook url not found")
+	}
+
+	delivery, err := postJSON(notifier.WebhookURL, notifier.newDiscordMessage(alert))
+	if err != nil {
+		return delivery, fmt.Errorf("discord notification send failed: %s", err)
+	}
+
+	return delivery, nil
+}
+
+func init() {
+	RegisterNotifier(NotifierType{
+		Name: DiscordIntegration,
+		Fields: []NotifierField{
+			{Name: "webhookURL", Type: FieldTypeURL, Required: true, Secret: true, Description: "Webhook url of the discord channel"},
+			{Name: "username", Type: FieldTypeString, Description: "Name the alerts are posted with, the name of the webhook by default"},
+		},
+		New: func() Notifier { return &discordNotifier{} },
+	})
+}
@@ -0,0 +1,38 @@
+package db
+
+import (
+	"testing"
+
+	"github.com/defraglabs/uptime/internal/utils"
+)
+
+func TestDiscordEmbed(t *testing.T) {
+	payloads := []map[string]interface{}{}
+	server := startChatStandIn(&payloads)
+	defer server.Close()
+
+	integration := newTestIntegration(t, DiscordIntegration, map[string]string{"webhookURL": server.URL})
+
+	monitorURL := MonitorURL{ID: "monitor", Name: "example", URL: "example.com", Protocol: "https"}
+	alert := NewStatusAlert(monitorURL, utils.StatusUp, MonitorResult{Status: utils.StatusDown, StatusCode: 503})
+	if _, err := integration.Send(alert); err != nil {
+		t.Fatalf("unable to send the alert: %s", err)
+	}
+
+	if len(payloads) != 1 {
+		t.Fatalf("expected 1 message, got %d", len(payloads))
+	}
+
+	embed := payloads[0]["embeds"].([]interface{})[0].(map[string]interface{})
+	if embed["color"] != float64(0xE01E5A) || embed["url"] != "https://example.com" {
+		t.Errorf("expected a red embed linking to https://example.com, got %v", embed)
+	}
+
+	fields := map[string]interface{}{}
+	for _, field := range embed["fields"].([]interface{}) {
+		fields[field.(map[string]interface{})["name"].(string)] = field.(map[string]interface{})["value"]
+	}
+	if fields["Monitor"] != "example" || fields["Status code"] != "503" {
+		t.Errorf("expected the monitor & status code fields, got %v", fields)
+	}
+}
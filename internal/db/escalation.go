@@ -0,0 +1,100 @@
+package db
+
+import (
+	"time"
+)
+
+const (
+	// IncidentStatusTriggered is an incident escalating through the steps of its policy.
+	IncidentStatusTriggered = "triggered"
+
+	// IncidentStatusAcknowledged is an incident somebody is working on, it no longer escalates.
+	IncidentStatusAcknowledged = "acknowledged"
+
+	// IncidentStatusResolved is an incident whose monitor url went back UP.
+	IncidentStatusResolved = "resolved"
+)
+
+// EscalationPolicy notifies its steps one after the other until the incident is acknowledged.
+type EscalationPolicy struct {
+	ID     string           `bson:"_id" json:"id" structs:"id"`
+	UserID string           `bson:"userID" json:"userID" structs:"userID"`
+	Name   string           `bson:"name" json:"name" structs:"name"`
+	Steps  []EscalationStep `bson:"steps" json:"steps" structs:"steps"`
+
+	CreatedAt time.Time `bson:"createdAt" json:"createdAt" structs:"createdAt,omitnested"`
+}
+
+// EscalationStep is a set of integrations notified together.
+type EscalationStep struct {
+	IntegrationIDs []string `bson:"integrationIDs" json:"integrationIDs" structs:"integrationIDs"`
+
+	// Delay in minutes before the next step is notified. Ignored on the last step.
+	Delay int32 `bson:"delay" json:"delay" structs:"delay"`
+}
+
+// GetNextEscalationAt returns when the incident escalates past the step notified at t.
+// Returns the zero time on the last step.
+func (policy EscalationPolicy) GetNextEscalationAt(step int32, t time.Time) time.Time {
+	if int(step) >= len(policy.Steps)-1 {
+		return time.Time{}
+	}
+
+	return t.Add(time.Duration(policy.Steps[step].Delay) * time.Minute)
+}
+
+// Incident is a DOWN alert escalated through an escalation policy.
+type Incident struct {
+	ID                 string `bson:"_id" json:"id" structs:"id"`
+	UserID             string `bson:"userID" json:"userID" structs:"userID"`
+	MonitorURLID       string `bson:"monitorURLID" json:"monitorURLID" structs:"monitorURLID"`
+	EscalationPolicyID string `bson:"escalationPolicyID" json:"escalationPolicyID" structs:"escalationPolicyID"`
+
+	Alert Alert `bson:"alert" json:"alert" structs:"alert,omitnested"`
+
+	// Status is triggered, acknowledged or resolved.
+	Status string `bson:"status" json:"status" structs:"status"`
+
+	// Step is the index of the last notified step of the policy.
+	Step int32 `bson:"step" json:"step" structs:"step"`
+
+	// NotifiedIntegrationIDs are the integrations notified so far, they receive the UP alert.
+	NotifiedIntegrationIDs []string `bson:"notifiedIntegrationIDs" json:"notifiedIntegrationIDs" structs:"notifiedIntegrationIDs"`
+
+	// NextEscalationAt is when the next step is notified, zero once there is none.
+	NextEscalationAt time.Time `bson:"nextEscalationAt" json:"nextEscalationAt" structs:"nextEscalationAt,omitnested"`
+
+	AcknowledgedAt time.Time `bson:"acknowledgedAt" json:"acknowledgedAt" structs:"acknowledgedAt,omitnested"`
+	AcknowledgedBy string    `bson:"acknowledgedBy" json:"acknowledgedBy" structs:"acknowledgedBy"`
+	ResolvedAt     time.Time `bson:"resolvedAt" json:"resolvedAt" structs:"resolvedAt,omitnested"`
+
+	CreatedAt time.Time `bson:"createdAt" json:"createdAt" structs:"createdAt,omitnested"`
+	UpdatedAt time.Time `bson:"updatedAt" json:"updatedAt" structs:"updatedAt,omitnested"`
+}
+
+// NewIncident creates the incident of the alert with the first step of the policy notified.
+// The alert carries the incident id so that the receivers can acknowledge it.
+func NewIncident(policy EscalationPolicy, alert Alert) Incident {
+	now := time.Now()
+
+	incident := Incident{
+		ID:                 GenerateObjectID().Hex(),
+		UserID:             policy.UserID,
+		MonitorURLID:       alert.MonitorURL.ID,
+		EscalationPolicyID: policy.ID,
+		Status:             IncidentStatusTriggered,
+		Step:               0,
+		NextEscalationAt:   policy.GetNextEscalationAt(0, now),
+		CreatedAt:          now,
+		UpdatedAt:          now,
+	}
+
+	if len(policy.Steps) > 0 {
+		incident.NotifiedIntegrationIDs = policy.Steps[0].IntegrationIDs
+	}
+
+	alert.IncidentID = incident.ID
+	incident.Alert = alert
+
+	return incident
+}
@@ -5,11 +5,13 @@ import (
 	"encoding/json"
 	"errors"
 	"fmt"
+	"io"
 	"io/ioutil"
 	"net/http"
 	"time"
 
-	"github.com/PagerDuty/go-pagerduty"
+	"github.com/defraglabs/uptime/internal/utils"
+	"github.com/mongodb/mongo-go-driver/bson"
 	log "github.com/sirupsen/logrus"
 )
 
@@ -22,6 +24,24 @@ const (
 
 	// PagerDutyIntegration represents pagerduty integration.
 	PagerDutyIntegration = "pagerduty"
+
+	// WebhookIntegration represents a generic webhook integration.
+	WebhookIntegration = "webhook"
+
+	// OpsgenieIntegration represents opsgenie integration.
+	OpsgenieIntegration = "opsgenie"
+
+	// MSTeamsIntegration represents microsoft teams integration.
+	MSTeamsIntegration = "msteams"
+
+	// DiscordIntegration represents discord integration.
+	DiscordIntegration = "discord"
+
+	// SMSIntegration represents sms sent through a twilio compatible api.
+	SMSIntegration = "sms"
+
+	// VoiceIntegration represents calls placed through a twilio compatible api.
+	VoiceIntegration = "voice"
 )
 
 // Integration struct represents a row in db.
@@ -29,104 +49,191 @@ type Integration struct {
 	ID     string `bson:"_id" json:"id,omitempty" structs:"id"`
 	UserID string `bson:"userID" json:"userID" structs:"userID"`
 
-	// Type of the integration.
-	// Supported integrations are Slack, Email, PagerDuty.
-	Type       string `bson:"type" json:"type" structs:"type"`
-	Email      string `bson:"email" json:"email" structs:"email"`
-	WebhookURL string `bson:"webhookURL" json:"webhookURL" structs:"webhookURL"`
+	// Type of the integration, one of the registered notifier types.
+	// Supported integrations are Slack, Email, PagerDuty, Webhook, Opsgenie, MS Teams, Discord, SMS, Voice.
+	Type string `bson:"type" json:"type" structs:"type"`
 
-	// PDRoutingKey is the routing key generated from PD integration.
-	PDRoutingKey string `bson:"pdRoutingKey" json:"pdRoutingKey" structs:"pdRoutingKey"`
+	// Config is decoded by the notifier of the type, see Integration.GetNotifier.
+	Config bson.Raw `bson:"config" json:"-" structs:"-"`
 
-	// PDAction is the PD event action. Possible values are trigger, acknowledge and resolve.
-	PDAction string `bson:"pdAction" json:"pdAction" structs:"pdAction"`
-
-	// PDSeverity can be one of info, warning, error, critical, or unknown.
-	PDSeverity string `bson:"pdSeverity" json:"pdSeverity" structs:"pdSeverity"`
+	// ReminderInterval is the interval in minutes between the reminders sent while a monitor url
+	// is DOWN, ReminderMaxCount caps their number. The settings of the monitor url take precedence.
+	ReminderInterval int32 `bson:"reminderInterval" json:"reminderInterval" structs:"reminderInterval"`
+	ReminderMaxCount int32 `bson:"reminderMaxCount" json:"reminderMaxCount" structs:"reminderMaxCount"`
 }
 
+// maxDeliveryResponseSize is how much of the response body is kept on a delivery.
+const maxDeliveryResponseSize = 512
+
+// integrationClient sends the http integrations. The timeout bounds the time
+// the receiver has to respond.
+var integrationClient = &http.Client{Timeout: 10 * time.Second}
+
 type slackNotificationMsg struct {
 	Text string `json:"text"`
 }
 
-// Send decides which integration to send notification and sends it.
-func (integration *Integration) Send(monitorURL MonitorURL, serviceStatus string) {
+// Delivery is the response of the integration to a notification.
+type Delivery struct {
+	// StatusCode of the http response, 0 for email integrations.
+	StatusCode int
+
+	// Response is the beginning of the response body.
+	Response string
+}
+
+// Send sends the notification with the notifier of the integration type.
+func (integration *Integration) Send(alert Alert) (Delivery, error) {
 	log.Info("Sending alert", integration.Type)
 
-	var err error
-	if integration.Type == "slack" {
-		log.Info("Try and send slack notification")
-		err = integration.SendSlackNotification(monitorURL, serviceStatus)
-	} else if integration.Type == "pagerduty" {
-		err = integration.SendPagerDutyEvent(monitorURL, serviceStatus)
-	} else {
-		return
+	notifier, err := integration.GetNotifier()
+	if err != nil {
+		return Delivery{}, err
 	}
 
+	delivery, err := notifier.Send(*integration, alert)
 	if err != nil {
-		log.Infof("Unable to send integration [%s]", integration.Type)
-		return
+		log.Infof("Unable to send integration [%s]: %s", integration.Type, err)
+		return delivery, err
 	}
 
-	log.Infof("Integration %s sent for site %s", integration.Type, monitorURL.URL)
+	log.Infof("Integration %s sent for site %s", integration.Type, alert.MonitorURL.URL)
+	return delivery, nil
 }
 
-// SendPagerDutyEvent sends an event v2 to pagerduty
-func (integration *Integration) SendPagerDutyEvent(monitorURL MonitorURL, serviceStatus string) error {
-	if integration.PDRoutingKey == "" {
-		log.Infof("Invalid integration. PDRoutingKey not found.")
+// postJSON posts the json encoded payload to the url & reads the delivery.
+func postJSON(url string, payload interface{}) (Delivery, error) {
+	body, err := json.Marshal(payload)
+	if err != nil {
+		return Delivery{}, err
+	}
 
-		return errors.New("invalid integration. PDRoutingKey not found")
+	resp, err := integrationClient.Post(url, "application/json", bytes.NewReader(body))
+	if err != nil {
+		return Delivery{}, err
 	}
+	defer resp.Body.Close()
 
-	timestamp := time.Now().String()
-	payload := pagerduty.V2Payload{
-		Summary:   fmt.Sprintf("Site %s %s", monitorURL.URL, serviceStatus),
-		Source:    "Uptime",
-		Severity:  integration.PDSeverity,
-		Timestamp: timestamp,
+	return readDelivery(resp)
+}
+
+// readDelivery reads the delivery from the http response. Responses other than 2xx are errors.
+func readDelivery(resp *http.Response) (Delivery, error) {
+	body, _ := ioutil.ReadAll(&io.LimitedReader{R: resp.Body, N: maxDeliveryResponseSize})
+
+	// Drain the rest of the body so the connection can be reused.
+	io.Copy(ioutil.Discard, resp.Body)
+
+	delivery := Delivery{
+		StatusCode: resp.StatusCode,
+		Response:   string(body),
 	}
-	event := pagerduty.V2Event{
-		Payload:    &payload,
-		RoutingKey: integration.PDRoutingKey,
-		Action:     integration.PDAction,
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return delivery, fmt.Errorf("responded with %s", resp.Status)
 	}
 
-	_, err := pagerduty.ManageEvent(event)
+	return delivery, nil
+}
 
-	if err != nil {
-		log.Infof("Pagerduty event send failed for integration %s", integration.ID)
+// slackNotifier sends the alerts to slack using slack webhooks.
+type slackNotifier struct {
+	WebhookURL string `bson:"webhookURL" json:"webhookURL"`
+}
 
-		return errors.New("pagerduty event send failed")
+// Validate validates the slack config.
+func (notifier *slackNotifier) Validate() string {
+	if notifier.WebhookURL == "" {
+		return "webhookURL is required for slack integration"
 	}
 
-	return nil
+	return ""
 }
 
-// SendSlackNotification sends a notification to slack using slack webhooks.
-func (integration *Integration) SendSlackNotification(monitorURL MonitorURL, serviceStatus string) error {
+// Send sends a notification to slack using slack webhooks.
+func (notifier *slackNotifier) Send(integration Integration, alert Alert) (Delivery, error) {
 	log.Info("Sending slack notification.")
 
-	if integration.WebhookURL == "" {
+	if notifier.WebhookURL == "" {
 		log.Infof("Invalid integration. Webhook url not found for integration %s", integration.ID)
 
-		return errors.New("invalid integration. webhook url not found")
+		return Delivery{}, errors.New("invalid integration. webhook url not found")
 	}
 
 	msg := slackNotificationMsg{
-		Text: fmt.Sprintf("Site %s %s", monitorURL.URL, serviceStatus),
+		Text: alert.Summary(),
 	}
 	msgByte, _ := json.Marshal(msg)
 	fmt.Println("slack message", string(msgByte))
-	resp, err := http.Post(integration.WebhookURL, "application/json", bytes.NewBuffer(msgByte))
+	resp, err := integrationClient.Post(notifier.WebhookURL, "application/json", bytes.NewBuffer(msgByte))
 
 	if err != nil {
-		return errors.New("slack notification send failed")
+		return Delivery{}, fmt.Errorf("slack notification send failed: %s", err)
 	}
 
 	defer resp.Body.Close()
-	body, _ := ioutil.ReadAll(resp.Body)
+	delivery, err := readDelivery(resp)
+
+	log.Infof("Response for slack integration for url %s is %d. Msg[%s]", alert.MonitorURL.URL, delivery.StatusCode, delivery.Response)
+	return delivery, err
+}
+
+// emailNotifier sends the alerts as html & plain text emails.
+type emailNotifier struct {
+	Email string `bson:"email" json:"email"`
+}
+
+// Validate validates the email config.
+func (notifier *emailNotifier) Validate() string {
+	if notifier.Email == "" {
+		return "email id is required for mail integration"
+	}
+
+	return ""
+}
+
+// Send sends the alert as an html & plain text email. Emails have no delivery.
+func (notifier *emailNotifier) Send(integration Integration, alert Alert) (Delivery, error) {
+	if notifier.Email == "" {
+		log.Infof("Invalid integration. Email not found for integration %s", integration.ID)
+
+		return Delivery{}, errors.New("invalid integration. email not found")
+	}
+
+	transport, err := utils.GetMailTransport()
+	if err != nil {
+		return Delivery{}, err
+	}
+
+	html, err := alert.HTML()
+	if err != nil {
+		return Delivery{}, err
+	}
+
+	mail := utils.Mail{
+		To:      notifier.Email,
+		Subject: alert.Summary(),
+		Text:    alert.Text(),
+		HTML:    html,
+	}
+
+	return Delivery{}, transport.Send(mail)
+}
 
-	log.Infof("Response for slack integration for url %s is %d. Msg[%s]", monitorURL.URL, resp.StatusCode, string(body))
-	return nil
+func init() {
+	RegisterNotifier(NotifierType{
+		Name: SlackIntegration,
+		Fields: []NotifierField{
+			{Name: "webhookURL", Type: FieldTypeURL, Required: true, Secret: true, Description: "Incoming webhook url of the slack channel"},
+		},
+		New: func() Notifier { return &slackNotifier{} },
+	})
+
+	RegisterNotifier(NotifierType{
+		Name: EmailIntegration,
+		Fields: []NotifierField{
+			{Name: "email", Type: FieldTypeEmail, Required: true, Description: "Email address the alerts are sent to"},
+		},
+		New: func() Notifier { return &emailNotifier{} },
+	})
 }
@@ -0,0 +1,98 @@
+package db
+
+import (
+	"time"
+
+	"github.com/defraglabs/uptime/internal/utils"
+)
+
+// MaintenanceWindow is a planned period during which the checks of the monitor urls it covers
+// are skipped or their alerts suppressed.
+type MaintenanceWindow struct {
+	ID     string `bson:"_id" json:"id" structs:"id"`
+	UserID string `bson:"userID" json:"userID" structs:"userID"`
+	Name   string `bson:"name" json:"name" structs:"name"`
+
+	// MonitorURLIDs & Tags select the monitor urls covered by the window.
+	// The window covers all the monitor urls of the user when both are empty.
+	MonitorURLIDs []string `bson:"monitorURLIDs" json:"monitorURLIDs" structs:"monitorURLIDs"`
+	Tags          []string `bson:"tags" json:"tags" structs:"tags"`
+
+	// Action is skipChecks or suppressAlerts.
+	Action string `bson:"action" json:"action" structs:"action"`
+
+	// StartAt & EndAt bound a one-off window. A recurring window only recurs between them,
+	// both are optional then.
+	StartAt time.Time `bson:"startAt" json:"startAt" structs:"startAt,omitnested"`
+	EndAt   time.Time `bson:"endAt" json:"endAt" structs:"endAt,omitnested"`
+
+	// Recurrence is a cron expression or an RRULE, evaluated in Timezone.
+	// Every occurrence lasts Duration minutes.
+	Recurrence string `bson:"recurrence" json:"recurrence" structs:"recurrence"`
+	Duration   int32  `bson:"duration" json:"duration" structs:"duration"`
+
+	// Timezone is an IANA timezone, e.g. Europe/Paris. Defaults to UTC.
+	Timezone string `bson:"timezone" json:"timezone" structs:"timezone"`
+
+	CreatedAt time.Time `bson:"createdAt" json:"createdAt" structs:"createdAt,omitnested"`
+}
+
+// IsActive checks if the window is in progress at t.
+func (window MaintenanceWindow) IsActive(t time.Time) bool {
+	if t.Before(window.StartAt) || (!window.EndAt.IsZero() && !t.Before(window.EndAt)) {
+		return false
+	} else if window.Recurrence == "" {
+		return true
+	}
+
+	location, err := time.LoadLocation(window.Timezone)
+	if err != nil {
+		location = time.UTC
+	}
+
+	schedule, err := utils.ParseRecurrence(window.Recurrence, window.StartAt.In(location))
+	if err != nil {
+		return false
+	}
+
+	// The occurrence in progress, if any, started within the duration before t.
+	duration := time.Duration(window.Duration) * time.Minute
+	occurrence := schedule.Next(t.In(location).Add(-duration))
+
+	return !occurrence.IsZero() && !occurrence.After(t)
+}
+
+// Covers checks if the window applies to the monitor url.
+func (window MaintenanceWindow) Covers(monitorURL MonitorURL) bool {
+	if len(window.MonitorURLIDs) == 0 && len(window.Tags) == 0 {
+		return true
+	} else if utils.StringInList(monitorURL.ID, window.MonitorURLIDs) {
+		return true
+	}
+
+	for _, tag := range monitorURL.Tags {
+		if utils.StringInList(tag, window.Tags) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// GetActiveMaintenanceWindow returns the window in progress at t covering the monitor url.
+// Windows skipping the checks take precedence. Returns an empty window if there is none.
+func GetActiveMaintenanceWindow(windows []MaintenanceWindow, monitorURL MonitorURL, t time.Time) MaintenanceWindow {
+	activeWindow := MaintenanceWindow{}
+
+	for _, window := range windows {
+		if window.UserID != monitorURL.UserID || !window.Covers(monitorURL) || !window.IsActive(t) {
+			continue
+		}
+
+		if activeWindow.ID == "" || window.Action == utils.MaintenanceActionSkipChecks {
+			activeWindow = window
+		}
+	}
+
+	return activeWindow
+}
@@ -0,0 +1,117 @@
+package db
+
+import (
+	"testing"
+	"time"
+
+	"github.com/defraglabs/uptime/internal/utils"
+)
+
+func TestMaintenanceWindowIsActive(t *testing.T) {
+	oneOff := MaintenanceWindow{
+		StartAt: time.Date(2024, 3, 1, 22, 0, 0, 0, time.UTC),
+		EndAt:   time.Date(2024, 3, 1, 23, 0, 0, 0, time.UTC),
+	}
+
+	// Every day from 22:00 to 00:00, paris time.
+	nightly := MaintenanceWindow{
+		Recurrence: "FREQ=DAILY;BYHOUR=22;BYMINUTE=0",
+		Duration:   120,
+		Timezone:   "Europe/Paris",
+	}
+
+	// Every day from 01:30 to 02:30, paris time. The clocks move from 02:00 to 03:00 on 31 march.
+	beforeChange := MaintenanceWindow{
+		Recurrence: "30 1 * * *",
+		Duration:   60,
+		Timezone:   "Europe/Paris",
+	}
+
+	cases := []struct {
+		name     string
+		window   MaintenanceWindow
+		t        time.Time
+		expected bool
+	}{
+		{"one-off before start", oneOff, time.Date(2024, 3, 1, 21, 59, 0, 0, time.UTC), false},
+		{"one-off at start", oneOff, time.Date(2024, 3, 1, 22, 0, 0, 0, time.UTC), true},
+		{"one-off at end", oneOff, time.Date(2024, 3, 1, 23, 0, 0, 0, time.UTC), false},
+
+		// CET is UTC+1, CEST UTC+2.
+		{"winter, before", nightly, time.Date(2024, 1, 15, 20, 30, 0, 0, time.UTC), false},
+		{"winter, during", nightly, time.Date(2024, 1, 15, 21, 30, 0, 0, time.UTC), true},
+		{"winter, past midnight", nightly, time.Date(2024, 1, 15, 23, 0, 0, 0, time.UTC), false},
+		{"eve of the change", nightly, time.Date(2024, 3, 30, 21, 30, 0, 0, time.UTC), true},
+		{"day of the change, before", nightly, time.Date(2024, 3, 31, 19, 59, 0, 0, time.UTC), false},
+		{"day of the change, during", nightly, time.Date(2024, 3, 31, 20, 0, 0, 0, time.UTC), true},
+		{"day of the change, after", nightly, time.Date(2024, 3, 31, 22, 0, 0, 0, time.UTC), false},
+		{"summer, during", nightly, time.Date(2024, 7, 1, 21, 59, 0, 0, time.UTC), true},
+		{"back to winter, during", nightly, time.Date(2024, 10, 27, 22, 30, 0, 0, time.UTC), true},
+		{"back to winter, before", nightly, time.Date(2024, 10, 27, 20, 30, 0, 0, time.UTC), false},
+
+		// The occurrence starts at 01:30 CET and lasts an hour, until 03:30 CEST.
+		{"across the change, start", beforeChange, time.Date(2024, 3, 31, 0, 30, 0, 0, time.UTC), true},
+		{"across the change, after 03:00", beforeChange, time.Date(2024, 3, 31, 1, 15, 0, 0, time.UTC), true},
+		{"across the change, end", beforeChange, time.Date(2024, 3, 31, 1, 30, 0, 0, time.UTC), false},
+
+		{"invalid recurrence", MaintenanceWindow{Recurrence: "FREQ=SECONDLY", Duration: 60}, time.Now(), false},
+	}
+
+	for _, c := range cases {
+		if active := c.window.IsActive(c.t); active != c.expected {
+			t.Errorf("%s: expected active to be %t at %s, got %t", c.name, c.expected, c.t, active)
+		}
+	}
+}
+
+func TestGetActiveMaintenanceWindow(t *testing.T) {
+	now := time.Date(2024, 3, 1, 12, 0, 0, 0, time.UTC)
+	active := func(id, userID, action string) MaintenanceWindow {
+		return MaintenanceWindow{
+			ID:      id,
+			UserID:  userID,
+			Action:  action,
+			StartAt: now.Add(-time.Hour),
+			EndAt:   now.Add(time.Hour),
+		}
+	}
+
+	monitorURL := MonitorURL{ID: "monitor", UserID: "user", Tags: []string{"web"}}
+
+	ended := active("ended", "user", utils.MaintenanceActionSkipChecks)
+	ended.EndAt = now
+	otherTag := active("other-tag", "user", utils.MaintenanceActionSkipChecks)
+	otherTag.Tags = []string{"db"}
+	sameTag := active("same-tag", "user", utils.MaintenanceActionSuppressAlerts)
+	sameTag.Tags = []string{"db", "web"}
+	otherURL := active("other-url", "user", utils.MaintenanceActionSkipChecks)
+	otherURL.MonitorURLIDs = []string{"other"}
+
+	cases := []struct {
+		name     string
+		windows  []MaintenanceWindow
+		expected string
+	}{
+		{"no window", nil, ""},
+		{"window of another user", []MaintenanceWindow{active("other-user", "other", utils.MaintenanceActionSkipChecks)}, ""},
+		{"ended window", []MaintenanceWindow{ended}, ""},
+		{"window of other urls", []MaintenanceWindow{otherTag, otherURL}, ""},
+		{"window of a tag of the url", []MaintenanceWindow{otherTag, sameTag}, "same-tag"},
+		{"first window wins", []MaintenanceWindow{
+			active("first", "user", utils.MaintenanceActionSuppressAlerts),
+			active("second", "user", utils.MaintenanceActionSuppressAlerts),
+		}, "first"},
+		{"skipping the checks wins", []MaintenanceWindow{
+			active("suppress", "user", utils.MaintenanceActionSuppressAlerts),
+			active("skip", "user", utils.MaintenanceActionSkipChecks),
+			active("suppress-again", "user", utils.MaintenanceActionSuppressAlerts),
+		}, "skip"},
+	}
+
+	for _, c := range cases {
+		window := GetActiveMaintenanceWindow(c.windows, monitorURL, now)
+		if window.ID != c.expected {
+			t.Errorf("%s: expected window %q, got %q", c.name, c.expected, window.ID)
+		}
+	}
+}
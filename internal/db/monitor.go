@@ -1,5 +1,7 @@
 package db
 
+import "time"
+
 const (
 	// MonitoringStatusPaused denotes that the url monitoring is paused.
 	MonitoringStatusPaused = "paused"
@@ -20,10 +22,13 @@ type MonitorURL struct {
 	// Name of the url
 	Name string `bson:"name" json:"name" structs:"name"`
 
+	// Type of the monitor (http, tcp, dns, heartbeat). Empty is treated as http.
+	Type string `bson:"type" json:"type" structs:"type"`
+
 	// Http protocol (http/https)
 	Protocol string `bson:"protocol" json:"protocol" structs:"protocol"`
 
-	// URL that should be pinged.
+	// URL that should be pinged. `host:port` for tcp monitors, the name to resolve for dns monitors.
 	URL string `bson:"url" json:"url" structs:"url"`
 
 	// Frequency in integer
@@ -33,7 +38,136 @@ type MonitorURL struct {
 	Unit string `bson:"unit" json:"unit" structs:"unit"`
 
 	// Status of the service. It can be (UP, DOWN, "")
+	// It changes once the failure or recovery threshold is reached.
 	Status string `bson:"status" json:"status" structs:"status"`
+
+	// FailureThreshold & RecoveryThreshold are the consecutive failed & successful checks
+	// before the status changes to DOWN & back to UP. 0 is treated as 1.
+	FailureThreshold  int32 `bson:"failureThreshold" json:"failureThreshold" structs:"failureThreshold"`
+	RecoveryThreshold int32 `bson:"recoveryThreshold" json:"recoveryThreshold" structs:"recoveryThreshold"`
+
+	// DownSince is when the failed checks which took the monitor url DOWN started, zero while UP
+	// without pending failures.
+	DownSince time.Time `bson:"downSince" json:"downSince" structs:"downSince,omitnested"`
+
+	// ReminderInterval is the interval in minutes between the reminders sent while DOWN,
+	// ReminderMaxCount caps their number. 0 falls back to the settings of the integrations.
+	ReminderInterval int32 `bson:"reminderInterval" json:"reminderInterval" structs:"reminderInterval"`
+	ReminderMaxCount int32 `bson:"reminderMaxCount" json:"reminderMaxCount" structs:"reminderMaxCount"`
+
+	// RemindersSent is the number of reminders sent per integration since the monitor url went DOWN.
+	RemindersSent map[string]int32 `bson:"remindersSent" json:"remindersSent" structs:"remindersSent"`
+
+	// PendingChecks is the number of consecutive checks disagreeing with the status.
+	PendingChecks int32 `bson:"pendingChecks" json:"pendingChecks" structs:"pendingChecks"`
+
+	// StatusDetail describes the status along with the pending checks, e.g. `UP, 1/3 failures`.
+	StatusDetail string `bson:"statusDetail" json:"statusDetail" structs:"statusDetail"`
+
+	// Tags group the monitor urls, e.g. to put them under maintenance together.
+	Tags []string `bson:"tags" json:"tags" structs:"tags"`
+
+	// IntegrationIDs are the integrations the alerts are sent through.
+	// Empty means all the integrations of the user.
+	IntegrationIDs []string `bson:"integrationIDs" json:"integrationIDs" structs:"integrationIDs"`
+
+	// EscalationPolicyID is the escalation policy of the DOWN alerts. The status alerts are
+	// sent through the steps of the policy instead of IntegrationIDs.
+	EscalationPolicyID string `bson:"escalationPolicyID" json:"escalationPolicyID" structs:"escalationPolicyID"`
+
+	// Severity of the failures of the monitor url, critical, error, warning or info.
+	// Overrides the severity of the pagerduty integrations.
+	Severity string `bson:"severity" json:"severity" structs:"severity"`
+
+	// Flapping is set while the status of the latest checks keeps changing.
+	// Status alerts are suppressed meanwhile.
+	Flapping bool `bson:"flapping" json:"flapping" structs:"flapping"`
+
+	// LastCheckedAt is when the scheduler last ran a check for the url.
+	LastCheckedAt time.Time `bson:"lastCheckedAt" json:"lastCheckedAt" structs:"lastCheckedAt,omitnested"`
+
+	// NextCheckAt is when the scheduler should run the next check.
+	// Zero value means the check is due right away.
+	NextCheckAt time.Time `bson:"nextCheckAt" json:"nextCheckAt" structs:"nextCheckAt,omitnested"`
+
+	// Payload sent once connected, for tcp monitors.
+	Payload string `bson:"payload" json:"payload" structs:"payload"`
+
+	// ExpectedBanner the tcp service should reply with. The url is DOWN if the reply doesn't contain it.
+	ExpectedBanner string `bson:"expectedBanner" json:"expectedBanner" structs:"expectedBanner"`
+
+	// RecordType to resolve (A, AAAA, CNAME, MX, TXT), for dns monitors.
+	RecordType string `bson:"recordType" json:"recordType" structs:"recordType"`
+
+	// Resolver (`host:port`) queried instead of the system resolver, for dns monitors.
+	Resolver string `bson:"resolver" json:"resolver" structs:"resolver"`
+
+	// ExpectedValues the dns answers should match.
+	// Without them the answers are compared with the accepted values.
+	ExpectedValues []string `bson:"expectedValues" json:"expectedValues" structs:"expectedValues"`
+
+	// AcceptedValues are the dns answers of the first successful lookup, when no values are expected.
+	// The url is DOWN while the answers differ from them. Reset when the record type or the resolver changes.
+	AcceptedValues []string `bson:"acceptedValues" json:"acceptedValues" structs:"acceptedValues"`
+
+	// HeartbeatToken is the secret in the ping url of heartbeat monitors.
+	HeartbeatToken string `bson:"heartbeatToken" json:"heartbeatToken" structs:"heartbeatToken"`
+
+	// CronExpression on which the pings are expected, instead of every frequency & unit.
+	CronExpression string `bson:"cronExpression" json:"cronExpression" structs:"cronExpression"`
+
+	// GracePeriod is how late, in seconds, a ping can be before the monitor goes DOWN.
+	GracePeriod int32 `bson:"gracePeriod" json:"gracePeriod" structs:"gracePeriod"`
+
+	// LastPingAt & LastStartAt are when the job last reported its completion & its start.
+	LastPingAt  time.Time `bson:"lastPingAt" json:"lastPingAt" structs:"lastPingAt,omitnested"`
+	LastStartAt time.Time `bson:"lastStartAt" json:"lastStartAt" structs:"lastStartAt,omitnested"`
+
+	// Method of the request. Defaults to GET.
+	Method string `bson:"method" json:"method" structs:"method"`
+
+	// Headers sent with the request.
+	Headers map[string]string `bson:"headers" json:"headers" structs:"headers"`
+
+	// Body sent with the request along with its content type.
+	Body        string `bson:"body" json:"body" structs:"body"`
+	ContentType string `bson:"contentType" json:"contentType" structs:"contentType"`
+
+	// Timeout of the check in seconds. Defaults to CHECK_TIMEOUT_SECONDS.
+	Timeout int32 `bson:"timeout" json:"timeout" structs:"timeout"`
+
+	// UserAgent overrides the User-Agent header.
+	UserAgent string `bson:"userAgent" json:"userAgent" structs:"userAgent"`
+
+	// Assertions run against the response. The url is DOWN if any of them fails.
+	Assertions []Assertion `bson:"assertions" json:"assertions" structs:"assertions"`
+
+	// CertificateExpiryThresholds are the days before the certificate expiry
+	// at which a warning is sent, for https urls. Example: [30, 14, 7]
+	CertificateExpiryThresholds []int32 `bson:"certificateExpiryThresholds" json:"certificateExpiryThresholds" structs:"certificateExpiryThresholds"`
+
+	// CertificateExpiresAt is the expiry of the certificate last seen.
+	CertificateExpiresAt time.Time `bson:"certificateExpiresAt" json:"certificateExpiresAt" structs:"certificateExpiresAt,omitnested"`
+
+	// CertificateNotifiedThreshold is the last threshold a warning was sent for,
+	// for the certificate expiring at CertificateExpiresAt. 0 if none was sent,
+	// -1 if the certificate was reported invalid.
+	CertificateNotifiedThreshold int32 `bson:"certificateNotifiedThreshold" json:"certificateNotifiedThreshold" structs:"certificateNotifiedThreshold"`
+}
+
+// Assertion is a check run against the response of a monitor url.
+type Assertion struct {
+	// Type of the assertion. contains, notContains, regex, jsonPath, header or responseTime.
+	Type string `bson:"type" json:"type" structs:"type"`
+
+	// Property is the json path or the header name, for jsonPath & header assertions.
+	Property string `bson:"property" json:"property" structs:"property"`
+
+	// Comparison for jsonPath & header assertions. equals, notEquals, contains, greaterThan or lessThan.
+	Comparison string `bson:"comparison" json:"comparison" structs:"comparison"`
+
+	// Value expected. For responseTime it is the limit in milliseconds.
+	Value string `bson:"value" json:"value" structs:"value"`
 }
 
 // MonitorResult contains the ping result.
@@ -49,9 +183,42 @@ type MonitorResult struct {
 	// Status code of the response.
 	StatusDescription string `bson:"statusDescription" json:"statusDescription" structs:"statusDescription"`
 
+	// StatusCode of the http response, 0 for other monitor types.
+	StatusCode int `bson:"statusCode" json:"statusCode" structs:"statusCode"`
+
 	// Response time
 	ResponseTime float64 `bson:"responseTime" json:"responseTime" structs:"responseTime"`
 
 	// Timestamp when the ping was run.
 	Time string `bson:"time" json:"time" structs:"time"`
+
+	// ResolvedValues are the answers of a dns monitor.
+	ResolvedValues []string `bson:"resolvedValues" json:"resolvedValues" structs:"resolvedValues"`
+
+	// FailedAssertion describes the assertion which marked the result DOWN.
+	FailedAssertion string `bson:"failedAssertion" json:"failedAssertion" structs:"failedAssertion"`
+
+	// Maintenance is set on the results recorded during a maintenance window.
+	// They don't change the status and are excluded from the uptime.
+	Maintenance bool `bson:"maintenance" json:"maintenance" structs:"maintenance"`
+
+	// Certificates is the chain presented by a https url, leaf first.
+	Certificates []Certificate `bson:"certificates,omitempty" json:"certificates,omitempty" structs:"certificates,omitempty"`
+}
+
+// Certificate describes a certificate presented by a https url.
+type Certificate struct {
+	Subject   string    `bson:"subject" json:"subject" structs:"subject"`
+	Issuer    string    `bson:"issuer" json:"issuer" structs:"issuer"`
+	NotBefore time.Time `bson:"notBefore" json:"notBefore" structs:"notBefore,omitnested"`
+	NotAfter  time.Time `bson:"notAfter" json:"notAfter" structs:"notAfter,omitnested"`
+
+	// DNSNames are the subject alternative names.
+	DNSNames []string `bson:"dnsNames" json:"dnsNames" structs:"dnsNames"`
+
+	// HostnameMatch is set on the leaf when it is valid for the host of the url.
+	HostnameMatch bool `bson:"hostnameMatch" json:"hostnameMatch" structs:"hostnameMatch"`
+
+	// VerificationError is set on the leaf when the chain isn't trusted, e.g. expired or self signed.
+	VerificationError string `bson:"verificationError" json:"verificationError,omitempty" structs:"verificationError"`
 }
@@ -0,0 +1,204 @@
+package db
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/defraglabs/uptime/internal/utils"
+	log "github.com/sirupsen/logrus"
+)
+
+const (
+	// MSTeamsFormatMessageCard is the card of the office 365 connectors. It is the default format.
+	MSTeamsFormatMessageCard = "messageCard"
+
+	// MSTeamsFormatAdaptiveCard is the card of the webhooks created with workflows.
+	MSTeamsFormatAdaptiveCard = "adaptiveCard"
+)
+
+// MSTeamsFormats lists the card formats of teams integrations.
+var MSTeamsFormats = []string{
+	MSTeamsFormatMessageCard,
+	MSTeamsFormatAdaptiveCard,
+}
+
+// msTeamsAdaptiveColors maps the colors of the alerts to the colors of adaptive cards, which
+// only has named colors.
+var msTeamsAdaptiveColors = map[string]string{
+	alertColorUp:      "Good",
+	alertColorDown:    "Attention",
+	alertColorWarning: "Warning",
+}
+
+// msTeamsNotifier posts the alerts as cards to a microsoft teams incoming webhook.
+type msTeamsNotifier struct {
+	WebhookURL string `bson:"webhookURL" json:"webhookURL"`
+
+	// Format of the card, messageCard (default) or adaptiveCard.
+	Format string `bson:"format" json:"format"`
+}
+
+type msTeamsMessageCard struct {
+	Type            string                 `json:"@type"`
+	Context         string                 `json:"@context"`
+	ThemeColor      string                 `json:"themeColor"`
+	Summary         string                 `json:"summary"`
+	Sections        []msTeamsSection       `json:"sections"`
+	PotentialAction []msTeamsOpenURIAction `json:"potentialAction,omitempty"`
+}
+
+type msTeamsSection struct {
+	ActivityTitle string        `json:"activityTitle"`
+	Facts         []msTeamsFact `json:"facts"`
+}
+
+type msTeamsFact struct {
+	Name  string `json:"name,omitempty"`
+	Title string `json:"title,omitempty"`
+	Value string `json:"value"`
+}
+
+type msTeamsOpenURIAction struct {
+	Type    string             `json:"@type"`
+	Name    string             `json:"name"`
+	Targets []msTeamsURITarget `json:"targets"`
+}
+
+type msTeamsURITarget struct {
+	OS  string `json:"os"`
+	URI string `json:"uri"`
+}
+
+type msTeamsAdaptiveMessage struct {
+	Type        string                      `json:"type"`
+	Attachments []msTeamsAdaptiveAttachment `json:"attachments"`
+}
+
+type msTeamsAdaptiveAttachment struct {
+	ContentType string              `json:"contentType"`
+	Content     msTeamsAdaptiveCard `json:"content"`
+}
+
+type msTeamsAdaptiveCard struct {
+	Schema  string                   `json:"$schema"`
+	Type    string                   `json:"type"`
+	Version string                   `json:"version"`
+	Body    []map[string]interface{} `json:"body"`
+	Actions []map[string]interface{} `json:"actions,omitempty"`
+}
+
+// Validate validates the teams config.
+func (notifier *msTeamsNotifier) Validate() string {
+	if validationMessage := validateWebhookURL(notifier.WebhookURL, MSTeamsIntegration); validationMessage != "" {
+		return validationMessage
+	} else if notifier.Format != "" && !utils.StringInList(notifier.Format, MSTeamsFormats) {
+		return "invalid teams card format. Should be messageCard/adaptiveCard"
+	}
+
+	return ""
+}
+
+// newMSTeamsMessageCard builds the message card of the alert.
+func newMSTeamsMessageCard(alert Alert) msTeamsMessageCard {
+	facts := []msTeamsFact{}
+	for _, detail := range alert.Details() {
+		facts = append(facts, msTeamsFact{Name: detail.Name, Value: detail.Value})
+	}
+
+	card := msTeamsMessageCard{
+		Type:       "MessageCard",
+		Context:    "https://schema.org/extensions",
+		ThemeColor: strings.TrimPrefix(alert.Color(), "#"),
+		Summary:    alert.Summary(),
+		Sections:   []msTeamsSection{{ActivityTitle: alert.Summary(), Facts: facts}},
+	}
+
+	if link := alert.Link(); link != "" {
+		card.PotentialAction = []msTeamsOpenURIAction{{
+			Type:    "OpenUri",
+			Name:    "View monitor",
+			Targets: []msTeamsURITarget{{OS: "default", URI: link}},
+		}}
+	}
+
+	return card
+}
+
+// newMSTeamsAdaptiveMessage builds the message with the adaptive card of the alert.
+func newMSTeamsAdaptiveMessage(alert Alert) msTeamsAdaptiveMessage {
+	facts := []msTeamsFact{}
+	for _, detail := range alert.Details() {
+		facts = append(facts, msTeamsFact{Title: detail.Name, Value: detail.Value})
+	}
+
+	card := msTeamsAdaptiveCard{
+		Schema:  "http://adaptivecards.io/schemas/adaptive-card.json",
+		Type:    "AdaptiveCard",
+		Version: "1.4",
+		Body: []map[string]interface{}{
+			{
+				"type":   "TextBlock",
+				"text":   alert.Summary(),
+				"size":   "Medium",
+				"weight": "Bolder",
+				"color":  msTeamsAdaptiveColors[alert.Color()],
+				"wrap":   true,
+			},
+			{
+				"type":  "FactSet",
+				"facts": facts,
+			},
+		},
+	}
+
+	if link := alert.Link(); link != "" {
+		card.Actions = []map[string]interface{}{{
+			"type":  "Action.OpenUrl",
+			"title": "View monitor",
+			"url":   link,
+		}}
+	}
+
+	return msTeamsAdaptiveMessage{
+		Type: "message",
+		Attachments: []msTeamsAdaptiveAttachment{{
+			ContentType: "application/vnd.microsoft.card.adaptive",
+			Content:     card,
+		}},
+	}
+}
+
+// Send posts the card of the alert to the teams webhook.
+func (notifier *msTeamsNotifier) Send(integration Integration, alert Alert) (Delivery, error) {
+	if notifier.WebhookURL == "" {
+		log.Infof("Invalid integration. Webhook url not found for integration %s", integration.ID)
+
+		return Delivery{}, errors.New("invalid integration. webhook url not found")
+	}
+
+	var payload interface{}
+	if notifier.Format == MSTeamsFormatAdaptiveCard {
+		payload = newMSTeamsAdaptiveMessage(alert)
+	} else {
+		payload = newMSTeamsMessageCard(alert)
+	}
+
+	delivery, err := postJSON(notifier.WebhookURL, payload)
+	if err != nil {
+		return delivery, fmt.Errorf("teams notification send failed: %s", err)
+	}
+
+	return delivery, nil
+}
+
+func init() {
+	RegisterNotifier(NotifierType{
+		Name: MSTeamsIntegration,
+		Fields: []NotifierField{
+			{Name: "webhookURL", Type: FieldTypeURL, Required: true, Secret: true, Description: "Incoming webhook url of the teams channel"},
+			{Name: "format", Type: FieldTypeEnum, Options: MSTeamsFormats, Description: "Card format, messageCard for connectors (default) or adaptiveCard for workflows"},
+		},
+		New: func() Notifier { return &msTeamsNotifier{} },
+	})
+}
@@ -0,0 +1,105 @@
+package db
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"testing"
+
+	"github.com/defraglabs/uptime/internal/forms"
+	"github.com/defraglabs/uptime/internal/utils"
+)
+
+// startChatStandIn serves a chat webhook locally & decodes the posted payloads into the received payloads.
+func startChatStandIn(payloads *[]map[string]interface{}) *httptest.Server {
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		payload := map[string]interface{}{}
+		json.NewDecoder(r.Body).Decode(&payload)
+		*payloads = append(*payloads, payload)
+
+		w.WriteHeader(http.StatusOK)
+		w.Write([]byte("1"))
+	}))
+}
+
+func newTestIntegration(t *testing.T, integrationType string, config map[string]string) Integration {
+	encodedConfig, _ := json.Marshal(config)
+	integration, validationMessage := NewIntegration(forms.IntegrationForm{
+		ID:     "integration",
+		Type:   integrationType,
+		Config: encodedConfig,
+	})
+	if validationMessage != "" {
+		t.Fatalf("unexpected validation message %s", validationMessage)
+	}
+
+	return integration
+}
+
+func TestMSTeamsMessageCard(t *testing.T) {
+	payloads := []map[string]interface{}{}
+	server := startChatStandIn(&payloads)
+	defer server.Close()
+
+	integration := newTestIntegration(t, MSTeamsIntegration, map[string]string{"webhookURL": server.URL})
+
+	monitorURL := MonitorURL{ID: "monitor", Name: "example", URL: "example.com", Protocol: "https"}
+	alert := NewStatusAlert(monitorURL, utils.StatusUp, MonitorResult{Status: utils.StatusDown, StatusCode: 503})
+	if _, err := integration.Send(alert); err != nil {
+		t.Fatalf("unable to send the alert: %s", err)
+	}
+
+	if len(payloads) != 1 {
+		t.Fatalf("expected 1 card, got %d", len(payloads))
+	}
+
+	card := payloads[0]
+	if card["@type"] != "MessageCard" || card["themeColor"] != "E01E5A" {
+		t.Errorf("expected a red message card, got %v", card)
+	}
+
+	actions, _ := card["potentialAction"].([]interface{})
+	if len(actions) != 1 {
+		t.Fatalf("expected a link to the monitor, got %v", card["potentialAction"])
+	}
+
+	targets := actions[0].(map[string]interface{})["targets"].([]interface{})
+	if uri := targets[0].(map[string]interface{})["uri"]; uri != "https://example.com" {
+		t.Errorf("expected the card to link to https://example.com, got %v", uri)
+	}
+}
+
+func TestMSTeamsAdaptiveCardLinksToDashboard(t *testing.T) {
+	payloads := []map[string]interface{}{}
+	server := startChatStandIn(&payloads)
+	defer server.Close()
+
+	os.Setenv("DASHBOARD_URL", "https://uptime.example.com/")
+	defer os.Unsetenv("DASHBOARD_URL")
+
+	integration := newTestIntegration(
+		t, MSTeamsIntegration, map[string]string{"webhookURL": server.URL, "format": MSTeamsFormatAdaptiveCard},
+	)
+
+	monitorURL := MonitorURL{ID: "monitor", Name: "example", URL: "example.com", Protocol: "https"}
+	alert := NewStatusAlert(monitorURL, utils.StatusDown, MonitorResult{Status: utils.StatusUp})
+	integration.Send(alert)
+
+	if len(payloads) != 1 {
+		t.Fatalf("expected 1 card, got %d", len(payloads))
+	}
+
+	attachment := payloads[0]["attachments"].([]interface{})[0].(map[string]interface{})
+	content := attachment["content"].(map[string]interface{})
+
+	title := content["body"].([]interface{})[0].(map[string]interface{})
+	if title["color"] != "Good" {
+		t.Errorf("expected a good title, got %v", title["color"])
+	}
+
+	action := content["actions"].([]interface{})[0].(map[string]interface{})
+	if action["url"] != "https://uptime.example.com/monitoring-urls/monitor" {
+		t.Errorf("expected the card to link to the dashboard, got %v", action["url"])
+	}
+}
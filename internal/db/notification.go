@@ -0,0 +1,98 @@
+package db
+
+import (
+	"time"
+)
+
+const (
+	// NotificationStatusPending is a notification waiting for its next delivery attempt.
+	NotificationStatusPending = "pending"
+
+	// NotificationStatusDelivered is a notification accepted by the integration.
+	NotificationStatusDelivered = "delivered"
+
+	// NotificationStatusFailed is a notification given up on after the last attempt.
+	NotificationStatusFailed = "failed"
+)
+
+// Notification is an alert to deliver through an integration. Alerts are written to
+// the outbox first and delivered in the background, so that they survive outages of
+// the integrations.
+type Notification struct {
+	ID            string `bson:"_id" json:"id" structs:"id"`
+	UserID        string `bson:"userID" json:"userID" structs:"userID"`
+	IntegrationID string `bson:"integrationID" json:"integrationID" structs:"integrationID"`
+	MonitorURLID  string `bson:"monitorURLID" json:"monitorURLID" structs:"monitorURLID"`
+
+	// IntegrationType is kept for the history of deleted integrations.
+	IntegrationType string `bson:"integrationType" json:"integrationType" structs:"integrationType"`
+
+	Alert Alert `bson:"alert" json:"alert" structs:"alert,omitnested"`
+
+	// Status is pending, delivered or failed.
+	Status string `bson:"status" json:"status" structs:"status"`
+
+	Attempts []DeliveryAttempt `bson:"attempts" json:"attempts" structs:"attempts"`
+
+	// NextAttemptAt is when a pending notification is delivered next.
+	NextAttemptAt time.Time `bson:"nextAttemptAt" json:"nextAttemptAt" structs:"nextAttemptAt,omitnested"`
+
+	CreatedAt time.Time `bson:"createdAt" json:"createdAt" structs:"createdAt,omitnested"`
+	UpdatedAt time.Time `bson:"updatedAt" json:"updatedAt" structs:"updatedAt,omitnested"`
+}
+
+// DeliveryAttempt records a single delivery of a notification.
+type DeliveryAttempt struct {
+	Time time.Time `bson:"time" json:"time" structs:"time,omitnested"`
+
+	// StatusCode of the http response, 0 for email integrations & connection errors.
+	StatusCode int `bson:"statusCode" json:"statusCode" structs:"statusCode"`
+
+	// Response is the beginning of the response body.
+	Response string `bson:"response" json:"response" structs:"response"`
+
+	// Error is empty when the attempt succeeded.
+	Error string `bson:"error" json:"error" structs:"error"`
+
+	// Latency of the attempt in milliseconds.
+	Latency float64 `bson:"latency" json:"latency" structs:"latency"`
+}
+
+// Attempt sends the alert through the integration and returns the attempt with the response
+// of the integration, or the error.
+func (integration *Integration) Attempt(alert Alert) (DeliveryAttempt, error) {
+	start := time.Now()
+	delivery, err := integration.Send(alert)
+
+	attempt := DeliveryAttempt{
+		Time:       start,
+		StatusCode: delivery.StatusCode,
+		Response:   delivery.Response,
+		Latency:    float64(time.Since(start).Nanoseconds()) / 1000000,
+	}
+
+	if err != nil {
+		attempt.Error = err.Error()
+	}
+
+	return attempt, err
+}
+
+// NewNotification creates the pending notification of the alert for the integration.
+func NewNotification(integration Integration, alert Alert) Notification {
+	now := time.Now()
+
+	return Notification{
+		ID:              GenerateObjectID().Hex(),
+		UserID:          integration.UserID,
+		IntegrationID:   integration.ID,
+		IntegrationType: integration.Type,
+		MonitorURLID:    alert.MonitorURL.ID,
+		Alert:           alert,
+		Status:          NotificationStatusPending,
+		Attempts:        []DeliveryAttempt{},
+		NextAttemptAt:   now,
+		CreatedAt:       now,
+		UpdatedAt:       now,
+	}
+}
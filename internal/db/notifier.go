@@ -0,0 +1,215 @@
+package db
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"github.com/defraglabs/uptime/internal/forms"
+	"github.com/fatih/structs"
+	"github.com/mongodb/mongo-go-driver/bson"
+)
+
+const (
+	// FieldTypeString is a free form config field.
+	FieldTypeString = "string"
+
+	// FieldTypeEmail is an email address.
+	FieldTypeEmail = "email"
+
+	// FieldTypeURL is a http/https url.
+	FieldTypeURL = "url"
+
+	// FieldTypeEnum is one of the options of the field.
+	FieldTypeEnum = "enum"
+
+	// FieldTypeMap is an object of string values, e.g. http headers.
+	FieldTypeMap = "map"
+
+	// FieldTypeList is a list of values.
+	FieldTypeList = "list"
+)
+
+// maskedSecretSuffix is the number of characters of the secrets shown once masked.
+const maskedSecretSuffix = 4
+
+// Notifier sends the alerts through an integration. The config of the integration is
+// decoded into the notifier, so its fields are the config of the integration type.
+type Notifier interface {
+	// Validate validates the config, returns the validation message.
+	Validate() string
+
+	// Send sends the alert through the integration.
+	Send(integration Integration, alert Alert) (Delivery, error)
+}
+
+// notifierDefaults is implemented by the notifiers which fill in config fields left empty,
+// e.g. generated secrets.
+type notifierDefaults interface {
+	SetDefaults()
+}
+
+// NotifierField describes a config field of an integration type.
+type NotifierField struct {
+	Name        string   `json:"name"`
+	Type        string   `json:"type"`
+	Required    bool     `json:"required"`
+	Secret      bool     `json:"secret"`
+	Options     []string `json:"options,omitempty"`
+	Description string   `json:"description"`
+}
+
+// NotifierType is an integration type: its config schema & the notifier decoding the config.
+type NotifierType struct {
+	Name   string          `json:"name"`
+	Fields []NotifierField `json:"fields"`
+
+	// New returns an empty notifier to decode the config into.
+	New func() Notifier `json:"-"`
+}
+
+// notifierTypes are the registered integration types, in registration order.
+var notifierTypes []NotifierType
+
+// RegisterNotifier registers an integration type. Types register themselves on init.
+func RegisterNotifier(notifierType NotifierType) {
+	if _, ok := GetNotifierType(notifierType.Name); ok {
+		panic(fmt.Sprintf("integration type %s is already registered", notifierType.Name))
+	}
+
+	notifierTypes = append(notifierTypes, notifierType)
+}
+
+// GetNotifierType returns the registered integration type with the given name.
+func GetNotifierType(name string) (NotifierType, bool) {
+	for _, notifierType := range notifierTypes {
+		if notifierType.Name == name {
+			return notifierType, true
+		}
+	}
+
+	return NotifierType{}, false
+}
+
+// GetNotifierTypes returns the registered integration types.
+func GetNotifierTypes() []NotifierType {
+	return notifierTypes
+}
+
+// getNotifierTypeNames returns the names of the registered integration types.
+func getNotifierTypeNames() []string {
+	names := make([]string, len(notifierTypes))
+	for i, notifierType := range notifierTypes {
+		names[i] = notifierType.Name
+	}
+
+	return names
+}
+
+// NewIntegration validates the config of the form against the integration type and builds the
+// integration. Returns the validation message when the form is invalid.
+func NewIntegration(integrationForm forms.IntegrationForm) (Integration, string) {
+	notifierType, ok := GetNotifierType(integrationForm.Type)
+	if !ok {
+		return Integration{}, fmt.Sprintf("invalid integration type. Should be %s", strings.Join(getNotifierTypeNames(), "/"))
+	}
+
+	notifier := notifierType.New()
+	err := json.Unmarshal(integrationForm.GetConfig(), notifier)
+	if err != nil {
+		return Integration{}, "Invalid config format"
+	}
+
+	validationMessage := notifier.Validate()
+	if validationMessage != "" {
+		return Integration{}, validationMessage
+	}
+
+	if defaults, ok := notifier.(notifierDefaults); ok {
+		defaults.SetDefaults()
+	}
+
+	config, err := bson.Marshal(notifier)
+	if err != nil {
+		return Integration{}, "Invalid config format"
+	}
+
+	integration := Integration{
+		ID:               integrationForm.ID,
+		UserID:           integrationForm.UserID,
+		Type:             integrationForm.Type,
+		Config:           config,
+		ReminderInterval: integrationForm.ReminderInterval,
+		ReminderMaxCount: integrationForm.ReminderMaxCount,
+	}
+
+	return integration, ""
+}
+
+// GetNotifier decodes the config of the integration into the notifier of its type.
+func (integration Integration) GetNotifier() (Notifier, error) {
+	notifierType, ok := GetNotifierType(integration.Type)
+	if !ok {
+		return nil, fmt.Errorf("unknown integration type %s", integration.Type)
+	}
+
+	notifier := notifierType.New()
+	if len(integration.Config) > 0 {
+		err := bson.Unmarshal(integration.Config, notifier)
+		if err != nil {
+			return nil, fmt.Errorf("invalid config of integration %s: %s", integration.ID, err)
+		}
+	}
+
+	return notifier, nil
+}
+
+// Map returns the integration as exposed by the api, the config fields alongside the others
+// as in the flat integrations of the older api. Secret fields are masked unless showSecrets is
+// set, which is only the case in the response creating the integration.
+func (integration Integration) Map(showSecrets bool) map[string]interface{} {
+	data := structs.Map(integration)
+
+	notifier, err := integration.GetNotifier()
+	if err != nil {
+		return data
+	}
+
+	var config map[string]interface{}
+	encodedConfig, _ := json.Marshal(notifier)
+	json.Unmarshal(encodedConfig, &config)
+
+	notifierType, _ := GetNotifierType(integration.Type)
+	for _, field := range notifierType.Fields {
+		if !field.Secret || showSecrets {
+			continue
+		}
+
+		switch value := config[field.Name].(type) {
+		case string:
+			config[field.Name] = maskSecret(value)
+		case map[string]interface{}:
+			// The values of secret maps are masked, e.g. the credentials in headers.
+			for name, secret := range value {
+				if secret, ok := secret.(string); ok {
+					value[name] = maskSecret(secret)
+				}
+			}
+		}
+	}
+
+	for name, value := range config {
+		data[name] = value
+	}
+
+	return data
+}
+
+// maskSecret hides all but the last characters of the secret.
+func maskSecret(secret string) string {
+	if len(secret) <= maskedSecretSuffix {
+		return strings.Repeat("*", len(secret))
+	}
+
+	return strings.Repeat("*", len(secret)-maskedSecretSuffix) + secret[len(secret)-maskedSecretSuffix:]
+}
@@ -0,0 +1,225 @@
+package db
+
+import (
+	"encoding/json"
+	"reflect"
+	"testing"
+
+	"github.com/defraglabs/uptime/internal/forms"
+	"github.com/mongodb/mongo-go-driver/bson"
+)
+
+func TestNewIntegrationWithLegacyConfig(t *testing.T) {
+	cases := []struct {
+		name     string
+		form     forms.IntegrationForm
+		expected Notifier
+	}{
+		{
+			"slack",
+			forms.IntegrationForm{Type: SlackIntegration, WebhookURL: "https://hooks.slack.com/services/T0/B0/secret"},
+			&slackNotifier{WebhookURL: "https://hooks.slack.com/services/T0/B0/secret"},
+		},
+		{
+			"pagerduty",
+			forms.IntegrationForm{Type: PagerDutyIntegration, PDRoutingKey: "routing-key", PDSeverity: "warning", PDAction: "trigger"},
+			&pagerDutyNotifier{PDRoutingKey: "routing-key", PDSeverity: "warning"},
+		},
+		{
+			"email",
+			forms.IntegrationForm{Type: EmailIntegration, Email: "ops@example.com"},
+			&emailNotifier{Email: "ops@example.com"},
+		},
+		{
+			"webhook",
+			forms.IntegrationForm{
+				Type:          WebhookIntegration,
+				WebhookURL:    "https://example.com/hook",
+				Headers:       map[string]string{"X-Team": "ops"},
+				WebhookSecret: "secret",
+			},
+			&webhookNotifier{WebhookURL: "https://example.com/hook", Headers: map[string]string{"X-Team": "ops"}, WebhookSecret: "secret"},
+		},
+		{
+			"null config",
+			forms.IntegrationForm{Type: EmailIntegration, Email: "ops@example.com", Config: json.RawMessage("null")},
+			&emailNotifier{Email: "ops@example.com"},
+		},
+		{
+			"config over the flat fields",
+			forms.IntegrationForm{Type: EmailIntegration, Email: "ops@example.com", Config: json.RawMessage(`{"email": "oncall@example.com"}`)},
+			&emailNotifier{Email: "oncall@example.com"},
+		},
+	}
+
+	for _, c := range cases {
+		integration, validationMessage := NewIntegration(c.form)
+		if validationMessage != "" {
+			t.Errorf("%s: unexpected validation message %s", c.name, validationMessage)
+			continue
+		}
+
+		notifier, err := integration.GetNotifier()
+		if err != nil {
+			t.Errorf("%s: unable to decode the config: %s", c.name, err)
+		} else if !reflect.DeepEqual(notifier, c.expected) {
+			t.Errorf("%s: expected %+v, got %+v", c.name, c.expected, notifier)
+		}
+	}
+}
+
+func TestNewIntegrationWithInvalidLegacyConfig(t *testing.T) {
+	cases := []struct {
+		form     forms.IntegrationForm
+		expected string
+	}{
+		{forms.IntegrationForm{Type: SlackIntegration, Email: "ops@example.com"}, "webhookURL is required for slack integration"},
+		{forms.IntegrationForm{Type: PagerDutyIntegration}, "pagerduty routing key is required"},
+		{forms.IntegrationForm{Type: PagerDutyIntegration, PDRoutingKey: "key", PDSeverity: "high"}, "invalid pagerduty severity. Should be critical/error/warning/info"},
+		{forms.IntegrationForm{Type: EmailIntegration, WebhookURL: "https://example.com"}, "email id is required for mail integration"},
+		{forms.IntegrationForm{Type: EmailIntegration, Config: json.RawMessage(`"ops@example.com"`)}, "Invalid config format"},
+	}
+
+	for _, c := range cases {
+		if _, validationMessage := NewIntegration(c.form); validationMessage != c.expected {
+			t.Errorf("%s: expected %q, got %q", c.form.Type, c.expected, validationMessage)
+		}
+	}
+}
+
+func TestMigrateLegacyIntegrationDocument(t *testing.T) {
+	// An integration stored before the config was introduced, decoded as MigrateIntegrations does.
+	document, err := bson.Marshal(bson.D{
+		{"_id", "integration"},
+		{"userID", "user"},
+		{"type", PagerDutyIntegration},
+		{"pdRoutingKey", "routing-key"},
+		{"pdAction", "trigger"},
+		{"pdSeverity", "critical"},
+	})
+	if err != nil {
+		t.Fatalf("unable to encode the document: %s", err)
+	}
+
+	integrationForm := forms.IntegrationForm{}
+	if err := bson.Unmarshal(document, &integrationForm); err != nil {
+		t.Fatalf("unable to decode the document: %s", err)
+	}
+
+	integration, validationMessage := NewIntegration(integrationForm)
+	if validationMessage != "" {
+		t.Fatalf("unexpected validation message %s", validationMessage)
+	}
+	if integration.ID != "integration" || integration.UserID != "user" {
+		t.Errorf("expected the ids to be kept, got %s of %s", integration.ID, integration.UserID)
+	}
+
+	notifier, _ := integration.GetNotifier()
+	expected := &pagerDutyNotifier{PDRoutingKey: "routing-key", PDSeverity: "critical"}
+	if !reflect.DeepEqual(notifier, expected) {
+		t.Errorf("expected %+v, got %+v", expected, notifier)
+	}
+}
+
+func TestIntegrationMap(t *testing.T) {
+	cases := []struct {
+		name        string
+		form        forms.IntegrationForm
+		showSecrets bool
+		expected    map[string]interface{}
+	}{
+		{
+			"slack webhook url masked",
+			forms.IntegrationForm{Type: SlackIntegration, WebhookURL: "https://hooks.slack.com/abcd1234"},
+			false,
+			map[string]interface{}{"webhookURL": "****************************1234"},
+		},
+		{
+			"slack webhook url shown",
+			forms.IntegrationForm{Type: SlackIntegration, WebhookURL: "https://hooks.slack.com/abcd1234"},
+			true,
+			map[string]interface{}{"webhookURL": "https://hooks.slack.com/abcd1234"},
+		},
+		{
+			"short secret fully masked",
+			forms.IntegrationForm{Type: PagerDutyIntegration, PDRoutingKey: "key", PDSeverity: "error"},
+			false,
+			map[string]interface{}{"pdRoutingKey": "***", "pdSeverity": "error"},
+		},
+		{
+			"email not a secret",
+			forms.IntegrationForm{Type: EmailIntegration, Email: "ops@example.com"},
+			false,
+			map[string]interface{}{"email": "ops@example.com"},
+		},
+		{
+			"header values masked",
+			forms.IntegrationForm{Type: WebhookIntegration, Config: json.RawMessage(
+				`{"webhookURL": "https://example.com/hook", "headers": {"Authorization": "Bearer 0123456789", "X-Team": "ops"}, "webhookSecret": "secret"}`,
+			)},
+			false,
+			map[string]interface{}{
+				"webhookURL":    "https://example.com/hook",
+				"headers":       map[string]interface{}{"Authorization": "*************6789", "X-Team": "***"},
+				"webhookSecret": "**cret",
+			},
+		},
+		{
+			"header values shown",
+			forms.IntegrationForm{Type: WebhookIntegration, Config: json.RawMessage(
+				`{"webhookURL": "https://example.com/hook", "headers": {"Authorization": "Bearer 0123456789"}, "webhookSecret": "secret"}`,
+			)},
+			true,
+			map[string]interface{}{
+				"headers":       map[string]interface{}{"Authorization": "Bearer 0123456789"},
+				"webhookSecret": "secret",
+			},
+		},
+		{
+			"only the secret fields masked",
+			forms.IntegrationForm{Type: OpsgenieIntegration, Config: json.RawMessage(`{"apiKey": "0123456789", "region": "eu"}`)},
+			false,
+			map[string]interface{}{"apiKey": "******6789", "region": "eu"},
+		},
+	}
+
+	for _, c := range cases {
+		c.form.ID, c.form.UserID, c.form.ReminderInterval = "integration", "user", 15
+
+		integration, validationMessage := NewIntegration(c.form)
+		if validationMessage != "" {
+			t.Errorf("%s: unexpected validation message %s", c.name, validationMessage)
+			continue
+		}
+
+		data := integration.Map(c.showSecrets)
+		for name, value := range c.expected {
+			if !reflect.DeepEqual(data[name], value) {
+				t.Errorf("%s: expected %s to be %v, got %v", c.name, name, value, data[name])
+			}
+		}
+
+		if data["id"] != "integration" || data["type"] != c.form.Type || data["reminderInterval"] != int32(15) {
+			t.Errorf("%s: expected the fields of the integration, got %v", c.name, data)
+		}
+		if _, ok := data["config"]; ok {
+			t.Errorf("%s: expected the raw config not to be exposed", c.name)
+		}
+	}
+}
+
+func TestMaskSecret(t *testing.T) {
+	cases := map[string]string{
+		"":           "",
+		"abc":        "***",
+		"abcd":       "****",
+		"abcde":      "*bcde",
+		"0123456789": "******6789",
+	}
+
+	for secret, expected := range cases {
+		if masked := maskSecret(secret); masked != expected {
+			t.Errorf("%q: expected %q, got %q", secret, expected, masked)
+		}
+	}
+}
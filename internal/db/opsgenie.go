@@ -0,0 +1,243 @@
+package db
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+
+	"github.com/defraglabs/uptime/internal/utils"
+	log "github.com/sirupsen/logrus"
+)
+
+const (
+	// OpsgenieRegionUS is the default api region of opsgenie.
+	OpsgenieRegionUS = "us"
+
+	// OpsgenieRegionEU is the api region of the accounts hosted in europe.
+	OpsgenieRegionEU = "eu"
+)
+
+// opsgenieAPIURLs are the urls of the alerts api per region.
+var opsgenieAPIURLs = map[string]string{
+	OpsgenieRegionUS: "https://api.opsgenie.com",
+	OpsgenieRegionEU: "https://api.eu.opsgenie.com",
+}
+
+const (
+	// opsgenieMaxMessageLength is the maximum length in characters of the message of an alert.
+	opsgenieMaxMessageLength = 130
+
+	// opsgenieMaxTags is the maximum number of tags of an alert.
+	opsgenieMaxTags = 20
+
+	// opsgenieMaxTagLength is the maximum length of a tag.
+	opsgenieMaxTagLength = 50
+)
+
+// OpsgeniePriorities lists the priorities of opsgenie alerts, P1 being the highest.
+var OpsgeniePriorities = []string{"P1", "P2", "P3", "P4", "P5"}
+
+// OpsgenieResponderTypes lists the types of the responders of opsgenie alerts.
+var OpsgenieResponderTypes = []string{"team", "user", "escalation", "schedule"}
+
+// opsgeniePriorities maps the severities of the monitor urls to opsgenie priorities.
+var opsgeniePriorities = map[string]string{
+	utils.SeverityCritical: "P1",
+	utils.SeverityError:    "P2",
+	utils.SeverityWarning:  "P3",
+	utils.SeverityInfo:     "P5",
+}
+
+// OpsgenieResponder is notified of the alerts. Teams, escalations & schedules are
+// identified by id or name, users by id or username.
+type OpsgenieResponder struct {
+	Type     string `bson:"type" json:"type"`
+	ID       string `bson:"id" json:"id,omitempty"`
+	Name     string `bson:"name" json:"name,omitempty"`
+	Username string `bson:"username" json:"username,omitempty"`
+}
+
+// opsgenieNotifier creates an alert on opsgenie when a monitor url goes DOWN and closes it when UP.
+type opsgenieNotifier struct {
+	APIKey string `bson:"apiKey" json:"apiKey"`
+
+	// Region of the account, us (default) or eu.
+	Region string `bson:"region" json:"region"`
+
+	// Priority of the alerts. The severity of the monitor url takes precedence.
+	Priority string `bson:"priority" json:"priority"`
+
+	Responders []OpsgenieResponder `bson:"responders" json:"responders"`
+	Tags       []string            `bson:"tags" json:"tags"`
+}
+
+// opsgenieAlert is the request creating an alert.
+type opsgenieAlert struct {
+	Message     string              `json:"message"`
+	Alias       string              `json:"alias"`
+	Description string              `json:"description,omitempty"`
+	Responders  []OpsgenieResponder `json:"responders,omitempty"`
+	Tags        []string            `json:"tags,omitempty"`
+	Details     map[string]string   `json:"details,omitempty"`
+	Entity      string              `json:"entity,omitempty"`
+	Source      string              `json:"source"`
+	Priority    string              `json:"priority"`
+}
+
+// opsgenieCloseRequest is the request closing an alert.
+type opsgenieCloseRequest struct {
+	Source string `json:"source"`
+	Note   string `json:"note,omitempty"`
+}
+
+// Validate validates the opsgenie config.
+func (notifier *opsgenieNotifier) Validate() string {
+	if notifier.APIKey == "" {
+		return "opsgenie api key is required"
+	} else if notifier.Region != "" && opsgenieAPIURLs[notifier.Region] == "" {
+		return "invalid opsgenie region. Should be us/eu"
+	} else if notifier.Priority != "" && !utils.StringInList(notifier.Priority, OpsgeniePriorities) {
+		return "invalid opsgenie priority. Should be P1/P2/P3/P4/P5"
+	} else if len(notifier.Tags) > opsgenieMaxTags {
+		return fmt.Sprintf("opsgenie alerts can have at most %d tags", opsgenieMaxTags)
+	}
+
+	for _, tag := range notifier.Tags {
+		if tag == "" || len(tag) > opsgenieMaxTagLength {
+			return fmt.Sprintf("opsgenie tags should be between 1 and %d characters", opsgenieMaxTagLength)
+		}
+	}
+
+	for _, responder := range notifier.Responders {
+		if !utils.StringInList(responder.Type, OpsgenieResponderTypes) {
+			return "invalid opsgenie responder type. Should be team/user/escalation/schedule"
+		} else if responder.ID == "" && responder.Name == "" && responder.Username == "" {
+			return "opsgenie responder requires an id, a name or a username"
+		} else if responder.Username != "" && responder.Type != "user" {
+			return "only opsgenie responders of type user have a username"
+		}
+	}
+
+	return ""
+}
+
+// getOpsgenieAPIURL returns the url of the alerts api of the region.
+func (notifier *opsgenieNotifier) getOpsgenieAPIURL() string {
+	if apiURL, ok := opsgenieAPIURLs[notifier.Region]; ok {
+		return apiURL
+	}
+
+	return opsgenieAPIURLs[OpsgenieRegionUS]
+}
+
+// getOpsgeniePriority returns the priority of the severity of the monitor url, then of the integration.
+// Test notifications are informational. Warnings about certificates & flapping default to P3, failures to P1.
+func (notifier *opsgenieNotifier) getOpsgeniePriority(alert Alert) string {
+	if alert.Type == AlertTypeTest {
+		return opsgeniePriorities[utils.SeverityInfo]
+	} else if priority, ok := opsgeniePriorities[alert.MonitorURL.Severity]; ok {
+		return priority
+	} else if notifier.Priority != "" {
+		return notifier.Priority
+	} else if alert.Type == AlertTypeCertificate || alert.Type == AlertTypeFlapping {
+		return opsgeniePriorities[utils.SeverityWarning]
+	}
+
+	return opsgeniePriorities[utils.SeverityCritical]
+}
+
+// newOpsgenieAlert builds the request creating the alert.
+func (notifier *opsgenieNotifier) newOpsgenieAlert(alert Alert) opsgenieAlert {
+	message := truncateMessage(alert.Summary(), opsgenieMaxMessageLength)
+
+	details := make(map[string]string)
+	for _, detail := range alert.Details() {
+		details[detail.Name] = detail.Value
+	}
+
+	return opsgenieAlert{
+		Message:     message,
+		Alias:       alert.DedupKey(),
+		Description: alert.Text(),
+		Responders:  notifier.Responders,
+		Tags:        notifier.Tags,
+		Details:     details,
+		Entity:      getSiteName(alert.MonitorURL),
+		Source:      "Uptime",
+		Priority:    notifier.getOpsgeniePriority(alert),
+	}
+}
+
+// Send creates the alert on opsgenie, or closes the alert with the same alias when the alert
+// resolves the outage. The alert of a test notification is closed right away, so it is not left open.
+func (notifier *opsgenieNotifier) Send(integration Integration, alert Alert) (Delivery, error) {
+	if notifier.APIKey == "" {
+		log.Infof("Invalid integration. Opsgenie api key not found for integration %s", integration.ID)
+
+		return Delivery{}, errors.New("invalid integration. opsgenie api key not found")
+	}
+
+	if alert.Resolves() {
+		return notifier.closeAlert(integration, alert)
+	}
+
+	body, _ := json.Marshal(notifier.newOpsgenieAlert(alert))
+	delivery, err := notifier.post(integration, fmt.Sprintf("%s/v2/alerts", notifier.getOpsgenieAPIURL()), body)
+	if err != nil || alert.Type != AlertTypeTest {
+		return delivery, err
+	}
+
+	if _, err := notifier.closeAlert(integration, alert); err != nil {
+		return delivery, fmt.Errorf("unable to close the test alert: %s", err)
+	}
+
+	return delivery, nil
+}
+
+// closeAlert closes the alert with the alias of the alert.
+func (notifier *opsgenieNotifier) closeAlert(integration Integration, alert Alert) (Delivery, error) {
+	requestURL := fmt.Sprintf(
+		"%s/v2/alerts/%s/close?identifierType=alias", notifier.getOpsgenieAPIURL(), url.PathEscape(alert.DedupKey()),
+	)
+	body, _ := json.Marshal(opsgenieCloseRequest{Source: "Uptime", Note: alert.Summary()})
+
+	return notifier.post(integration, requestURL, body)
+}
+
+// post sends the request to the alerts api.
+func (notifier *opsgenieNotifier) post(integration Integration, requestURL string, body []byte) (Delivery, error) {
+	req, err := http.NewRequest(http.MethodPost, requestURL, bytes.NewReader(body))
+	if err != nil {
+		return Delivery{}, err
+	}
+
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("Authorization", fmt.Sprintf("GenieKey %s", notifier.APIKey))
+
+	resp, err := integrationClient.Do(req)
+	if err != nil {
+		log.Infof("Opsgenie request failed for integration %s", integration.ID)
+
+		return Delivery{}, fmt.Errorf("opsgenie request failed: %s", err)
+	}
+	defer resp.Body.Close()
+
+	return readDelivery(resp)
+}
+
+func init() {
+	RegisterNotifier(NotifierType{
+		Name: OpsgenieIntegration,
+		Fields: []NotifierField{
+			{Name: "apiKey", Type: FieldTypeString, Required: true, Secret: true, Description: "Key of the api integration"},
+			{Name: "region", Type: FieldTypeEnum, Options: []string{OpsgenieRegionUS, OpsgenieRegionEU}, Description: "Api region of the account, us by default"},
+			{Name: "priority", Type: FieldTypeEnum, Options: OpsgeniePriorities, Description: "Priority of the alerts, the severity of the monitor url takes precedence"},
+			{Name: "responders", Type: FieldTypeList, Description: "Responders of the alerts, each with a type (team/user/escalation/schedule) and an id, a name or a username"},
+			{Name: "tags", Type: FieldTypeList, Description: "Tags of the alerts"},
+		},
+		New: func() Notifier { return &opsgenieNotifier{} },
+	})
+}
@@ -0,0 +1,253 @@
+package db
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+	"unicode/utf8"
+
+	"github.com/defraglabs/uptime/internal/forms"
+	"github.com/defraglabs/uptime/internal/utils"
+)
+
+// opsgenieRequest is a request received by the opsgenie stand-in.
+type opsgenieRequest struct {
+	Method         string
+	Path           string
+	IdentifierType string
+	Authorization  string
+	Body           map[string]interface{}
+}
+
+// startOpsgenieStandIn serves the alerts api of the eu region locally & records the requests.
+// The returned func stops it.
+func startOpsgenieStandIn() (func(), *[]opsgenieRequest) {
+	requests := []opsgenieRequest{}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		request := opsgenieRequest{
+			Method:         r.Method,
+			Path:           r.URL.Path,
+			IdentifierType: r.URL.Query().Get("identifierType"),
+			Authorization:  r.Header.Get("Authorization"),
+		}
+		json.NewDecoder(r.Body).Decode(&request.Body)
+		requests = append(requests, request)
+
+		w.WriteHeader(http.StatusAccepted)
+		w.Write([]byte(`{"result":"Request will be processed","requestId":"42"}`))
+	}))
+
+	euURL := opsgenieAPIURLs[OpsgenieRegionEU]
+	opsgenieAPIURLs[OpsgenieRegionEU] = server.URL
+	stop := func() {
+		opsgenieAPIURLs[OpsgenieRegionEU] = euURL
+		server.Close()
+	}
+
+	return stop, &requests
+}
+
+func newTestOpsgenieIntegration(t *testing.T, config string) Integration {
+	integration, validationMessage := NewIntegration(forms.IntegrationForm{
+		ID:     "integration",
+		Type:   OpsgenieIntegration,
+		Config: json.RawMessage(config),
+	})
+	if validationMessage != "" {
+		t.Fatalf("unexpected validation message %s", validationMessage)
+	}
+
+	return integration
+}
+
+func TestOpsgenieCreatesAndClosesAlert(t *testing.T) {
+	stop, requests := startOpsgenieStandIn()
+	defer stop()
+
+	integration := newTestOpsgenieIntegration(
+		t, `{"apiKey": "key", "region": "eu", "priority": "P2", "tags": ["web"], "responders": [{"type": "team", "name": "ops"}]}`,
+	)
+
+	monitorURL := MonitorURL{ID: "monitor", Name: "example", URL: "example.com", Protocol: "https"}
+	down := NewStatusAlert(monitorURL, utils.StatusUp, MonitorResult{Status: utils.StatusDown})
+
+	delivery, err := integration.Send(down)
+	if err != nil {
+		t.Fatalf("unable to send the DOWN alert: %s", err)
+	}
+	if delivery.StatusCode != http.StatusAccepted {
+		t.Errorf("expected status 202, got %d", delivery.StatusCode)
+	}
+
+	up := NewStatusAlert(monitorURL, utils.StatusDown, MonitorResult{Status: utils.StatusUp})
+	if _, err := integration.Send(up); err != nil {
+		t.Fatalf("unable to send the UP alert: %s", err)
+	}
+
+	if len(*requests) != 2 {
+		t.Fatalf("expected 2 requests, got %d", len(*requests))
+	}
+
+	create := (*requests)[0]
+	if create.Path != "/v2/alerts" || create.Authorization != "GenieKey key" {
+		t.Errorf("unexpected create request %s with %s", create.Path, create.Authorization)
+	}
+	if create.Body["alias"] != "uptime-monitor" || create.Body["priority"] != "P2" {
+		t.Errorf("unexpected alias %v or priority %v", create.Body["alias"], create.Body["priority"])
+	}
+	if responders, _ := create.Body["responders"].([]interface{}); len(responders) != 1 {
+		t.Errorf("expected 1 responder, got %v", create.Body["responders"])
+	}
+
+	closeRequest := (*requests)[1]
+	if closeRequest.Path != "/v2/alerts/uptime-monitor/close" || closeRequest.IdentifierType != "alias" {
+		t.Errorf("unexpected close request %s?identifierType=%s", closeRequest.Path, closeRequest.IdentifierType)
+	}
+}
+
+func TestOpsgenieFlappingThenStabilized(t *testing.T) {
+	stop, requests := startOpsgenieStandIn()
+	defer stop()
+
+	integration := newTestOpsgenieIntegration(t, `{"apiKey": "key", "region": "eu"}`)
+
+	// The url went DOWN, started flapping & stabilized DOWN, then UP.
+	monitorURL := MonitorURL{ID: "monitor", Name: "example", URL: "example.com"}
+	alerts := []Alert{
+		NewStatusAlert(monitorURL, utils.StatusUp, MonitorResult{Status: utils.StatusDown}),
+		NewFlappingAlert(monitorURL, true, utils.StatusDown, MonitorResult{Status: utils.StatusUp}),
+		NewFlappingAlert(monitorURL, false, utils.StatusDown, MonitorResult{Status: utils.StatusDown}),
+		NewFlappingAlert(monitorURL, false, utils.StatusUp, MonitorResult{Status: utils.StatusUp}),
+	}
+	for _, alert := range alerts {
+		if _, err := integration.Send(alert); err != nil {
+			t.Fatalf("unable to send the %s alert: %s", alert.Type, err)
+		}
+	}
+
+	if len(*requests) != 4 {
+		t.Fatalf("expected 4 requests, got %d", len(*requests))
+	}
+
+	// The alerts share the alias of the url, so opsgenie deduplicates them until the close.
+	for i, request := range (*requests)[:3] {
+		if request.Path != "/v2/alerts" || request.Body["alias"] != "uptime-monitor" {
+			t.Errorf("request %d: expected an alert on uptime-monitor, got %s with alias %v", i, request.Path, request.Body["alias"])
+		}
+	}
+	if priority := (*requests)[1].Body["priority"]; priority != "P3" {
+		t.Errorf("expected the flapping warning to be P3, got %v", priority)
+	}
+
+	closeRequest := (*requests)[3]
+	if closeRequest.Path != "/v2/alerts/uptime-monitor/close" || closeRequest.IdentifierType != "alias" {
+		t.Errorf("expected the stabilized UP alert to close uptime-monitor, got %s?identifierType=%s", closeRequest.Path, closeRequest.IdentifierType)
+	}
+}
+
+func TestOpsgenieTestAlertIsClosed(t *testing.T) {
+	stop, requests := startOpsgenieStandIn()
+	defer stop()
+
+	integration := newTestOpsgenieIntegration(t, `{"apiKey": "key", "region": "eu", "priority": "P1"}`)
+
+	if _, err := integration.Send(NewTestAlert(integration, time.Now())); err != nil {
+		t.Fatalf("unable to send the test alert: %s", err)
+	}
+
+	if len(*requests) != 2 {
+		t.Fatalf("expected the test alert to be created & closed, got %d requests", len(*requests))
+	}
+
+	create, closeRequest := (*requests)[0], (*requests)[1]
+	if create.Path != "/v2/alerts" || create.Body["alias"] != "uptime-test-integration" || create.Body["priority"] != "P5" {
+		t.Errorf("unexpected create request %s with alias %v & priority %v", create.Path, create.Body["alias"], create.Body["priority"])
+	}
+	if closeRequest.Path != "/v2/alerts/uptime-test-integration/close" || closeRequest.IdentifierType != "alias" {
+		t.Errorf("expected the test alert to be closed, got %s?identifierType=%s", closeRequest.Path, closeRequest.IdentifierType)
+	}
+}
+
+func TestOpsgenieRejectedTestAlertIsNotClosed(t *testing.T) {
+	requests := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests++
+		w.WriteHeader(http.StatusUnauthorized)
+		w.Write([]byte(`{"message":"Key format is not valid!"}`))
+	}))
+	defer server.Close()
+
+	euURL := opsgenieAPIURLs[OpsgenieRegionEU]
+	opsgenieAPIURLs[OpsgenieRegionEU] = server.URL
+	defer func() { opsgenieAPIURLs[OpsgenieRegionEU] = euURL }()
+
+	integration := newTestOpsgenieIntegration(t, `{"apiKey": "key", "region": "eu"}`)
+
+	delivery, err := integration.Send(NewTestAlert(integration, time.Now()))
+	if err == nil || delivery.StatusCode != http.StatusUnauthorized {
+		t.Errorf("expected the test alert to be rejected, got %d: %v", delivery.StatusCode, err)
+	}
+	if requests != 1 {
+		t.Errorf("expected no close request after the rejected alert, got %d requests", requests)
+	}
+}
+
+func TestOpsgeniePriorityFollowsSeverity(t *testing.T) {
+	stop, requests := startOpsgenieStandIn()
+	defer stop()
+
+	integration := newTestOpsgenieIntegration(t, `{"apiKey": "key", "region": "eu", "priority": "P2"}`)
+
+	monitorURL := MonitorURL{ID: "monitor", Name: "example", URL: "example.com", Severity: utils.SeverityWarning}
+	alert := NewStatusAlert(monitorURL, utils.StatusUp, MonitorResult{Status: utils.StatusDown})
+	integration.Send(alert)
+
+	if len(*requests) != 1 || (*requests)[0].Body["priority"] != "P3" {
+		t.Errorf("expected priority P3 of the warning severity, got %v", *requests)
+	}
+}
+
+func TestOpsgenieMessageIsTruncatedOnCharacters(t *testing.T) {
+	stop, requests := startOpsgenieStandIn()
+	defer stop()
+
+	integration := newTestOpsgenieIntegration(t, `{"apiKey": "key", "region": "eu"}`)
+
+	monitorURL := MonitorURL{ID: "monitor", Name: strings.Repeat("Überwachung ", 20)}
+	alert := NewStatusAlert(monitorURL, utils.StatusUp, MonitorResult{Status: utils.StatusDown})
+	if _, err := integration.Send(alert); err != nil {
+		t.Fatalf("unable to send the alert: %s", err)
+	}
+
+	if len(*requests) != 1 {
+		t.Fatalf("expected 1 request, got %d", len(*requests))
+	}
+
+	message, _ := (*requests)[0].Body["message"].(string)
+	if !utf8.ValidString(message) || utf8.RuneCountInString(message) != opsgenieMaxMessageLength {
+		t.Errorf("expected a valid message of %d characters, got %d: %q", opsgenieMaxMessageLength, utf8.RuneCountInString(message), message)
+	}
+	if !strings.HasSuffix(message, "...") || !strings.HasPrefix(message, alert.Summary()[:20]) {
+		t.Errorf("expected the summary to be truncated, got %q", message)
+	}
+}
+
+func TestOpsgenieValidation(t *testing.T) {
+	configs := map[string]string{
+		`{}`:                                 "opsgenie api key is required",
+		`{"apiKey": "key", "region": "ap"}`:  "invalid opsgenie region. Should be us/eu",
+		`{"apiKey": "key", "priority": "1"}`: "invalid opsgenie priority. Should be P1/P2/P3/P4/P5",
+		`{"apiKey": "key", "responders": [{"type": "group", "name": "ops"}]}`: "invalid opsgenie responder type. Should be team/user/escalation/schedule",
+		`{"apiKey": "key", "responders": [{"type": "team"}]}`:                 "opsgenie responder requires an id, a name or a username",
+	}
+
+	for config, expected := range configs {
+		_, validationMessage := NewIntegration(forms.IntegrationForm{Type: OpsgenieIntegration, Config: json.RawMessage(config)})
+		if validationMessage != expected {
+			t.Errorf("expected %q for %s, got %q", expected, config, validationMessage)
+		}
+	}
+}
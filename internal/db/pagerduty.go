@@ -0,0 +1,190 @@
+package db
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/PagerDuty/go-pagerduty"
+	"github.com/defraglabs/uptime/internal/utils"
+	log "github.com/sirupsen/logrus"
+)
+
+// pagerDutyEventsURL is the endpoint of the events api v2.
+var pagerDutyEventsURL = "https://events.pagerduty.com/v2/enqueue"
+
+const (
+	// PagerDutyActionTrigger opens an incident, or adds to the open one with the same dedup key.
+	PagerDutyActionTrigger = "trigger"
+
+	// PagerDutyActionResolve resolves the incident with the dedup key.
+	PagerDutyActionResolve = "resolve"
+)
+
+// pagerDutyEvent is an event of the events api v2. pagerduty.V2Event has no links.
+type pagerDutyEvent struct {
+	RoutingKey string               `json:"routing_key"`
+	Action     string               `json:"event_action"`
+	DedupKey   string               `json:"dedup_key"`
+	Client     string               `json:"client,omitempty"`
+	Payload    *pagerduty.V2Payload `json:"payload,omitempty"`
+	Links      []pagerDutyLink      `json:"links,omitempty"`
+}
+
+type pagerDutyLink struct {
+	Href string `json:"href"`
+	Text string `json:"text"`
+}
+
+// pagerDutyNotifier sends the alerts as events v2 to pagerduty.
+type pagerDutyNotifier struct {
+	// PDRoutingKey is the routing key generated from PD integration.
+	PDRoutingKey string `bson:"pdRoutingKey" json:"pdRoutingKey"`
+
+	// PDSeverity can be one of info, warning, error or critical.
+	// The severity of the monitor url takes precedence.
+	PDSeverity string `bson:"pdSeverity" json:"pdSeverity"`
+}
+
+// Validate validates the pagerduty config.
+func (notifier *pagerDutyNotifier) Validate() string {
+	if notifier.PDRoutingKey == "" {
+		return "pagerduty routing key is required"
+	} else if notifier.PDSeverity != "" && !utils.StringInList(notifier.PDSeverity, utils.Severities) {
+		return "invalid pagerduty severity. Should be critical/error/warning/info"
+	}
+
+	return ""
+}
+
+// getPagerDutyAction returns the action of the alert, the alerts resolving the outage resolve the incident.
+func getPagerDutyAction(alert Alert) string {
+	if alert.Resolves() {
+		return PagerDutyActionResolve
+	}
+
+	return PagerDutyActionTrigger
+}
+
+// getPagerDutySeverity returns the severity of the monitor url, then of the integration.
+// Test notifications are informational. Warnings about certificates & flapping default to warning, failures to critical.
+func (notifier *pagerDutyNotifier) getPagerDutySeverity(alert Alert) string {
+	if alert.Type == AlertTypeTest {
+		return utils.SeverityInfo
+	} else if alert.MonitorURL.Severity != "" {
+		return alert.MonitorURL.Severity
+	} else if notifier.PDSeverity != "" {
+		return notifier.PDSeverity
+	} else if alert.Type == AlertTypeCertificate || alert.Type == AlertTypeFlapping {
+		return utils.SeverityWarning
+	}
+
+	return utils.SeverityCritical
+}
+
+// getPagerDutyDetails returns the custom details of the alert.
+func getPagerDutyDetails(alert Alert) map[string]interface{} {
+	details := map[string]interface{}{
+		"monitor": alert.MonitorURL.Name,
+		"status":  alert.Status,
+	}
+
+	if alert.MonitorURL.URL != "" {
+		details["url"] = alert.MonitorURL.URL
+	}
+	if alert.Result.StatusCode != 0 {
+		details["statusCode"] = alert.Result.StatusCode
+	}
+	if alert.Result.StatusDescription != "" {
+		details["statusDescription"] = alert.Result.StatusDescription
+	}
+	if alert.Result.ResponseTime > 0 {
+		details["responseTime"] = alert.Result.ResponseTime
+	}
+	if alert.Result.FailedAssertion != "" {
+		details["failedAssertion"] = alert.Result.FailedAssertion
+	}
+	if downtime := alert.Downtime(); downtime > 0 {
+		details["downtime"] = utils.FormatDuration(downtime)
+	}
+
+	return details
+}
+
+// newPagerDutyEvent builds the event of the alert.
+func (notifier *pagerDutyNotifier) newPagerDutyEvent(alert Alert) pagerDutyEvent {
+	source := getSiteName(alert.MonitorURL)
+
+	event := pagerDutyEvent{
+		RoutingKey: notifier.PDRoutingKey,
+		Action:     getPagerDutyAction(alert),
+		DedupKey:   alert.DedupKey(),
+		Client:     "Uptime",
+		Payload: &pagerduty.V2Payload{
+			Summary:   alert.Summary(),
+			Source:    source,
+			Severity:  notifier.getPagerDutySeverity(alert),
+			Timestamp: alert.Time.UTC().Format(time.RFC3339),
+			Component: alert.MonitorURL.Name,
+			Class:     alert.Type,
+			Details:   getPagerDutyDetails(alert),
+		},
+	}
+
+	if alert.MonitorURL.Protocol != "" && (alert.MonitorURL.Type == "" || alert.MonitorURL.Type == utils.MonitorTypeHTTP) {
+		url := fmt.Sprintf("%s://%s", alert.MonitorURL.Protocol, alert.MonitorURL.URL)
+		event.Links = []pagerDutyLink{{Href: url, Text: alert.MonitorURL.Name}}
+	}
+
+	return event
+}
+
+// Send sends an event v2 to pagerduty. The incident of a test notification is resolved right
+// away, so it is not left open.
+func (notifier *pagerDutyNotifier) Send(integration Integration, alert Alert) (Delivery, error) {
+	if notifier.PDRoutingKey == "" {
+		log.Infof("Invalid integration. PDRoutingKey not found.")
+
+		return Delivery{}, errors.New("invalid integration. PDRoutingKey not found")
+	}
+
+	event := notifier.newPagerDutyEvent(alert)
+	delivery, err := notifier.sendEvent(integration, event)
+	if err != nil || alert.Type != AlertTypeTest {
+		return delivery, err
+	}
+
+	event.Action = PagerDutyActionResolve
+	if _, err := notifier.sendEvent(integration, event); err != nil {
+		return delivery, fmt.Errorf("unable to resolve the test incident: %s", err)
+	}
+
+	return delivery, nil
+}
+
+// sendEvent posts the event to the events api.
+func (notifier *pagerDutyNotifier) sendEvent(integration Integration, event pagerDutyEvent) (Delivery, error) {
+	body, _ := json.Marshal(event)
+	resp, err := integrationClient.Post(pagerDutyEventsURL, "application/json", bytes.NewReader(body))
+	if err != nil {
+		log.Infof("Pagerduty event send failed for integration %s", integration.ID)
+
+		return Delivery{}, fmt.Errorf("pagerduty event send failed: %s", err)
+	}
+	defer resp.Body.Close()
+
+	return readDelivery(resp)
+}
+
+func init() {
+	RegisterNotifier(NotifierType{
+		Name: PagerDutyIntegration,
+		Fields: []NotifierField{
+			{Name: "pdRoutingKey", Type: FieldTypeString, Required: true, Secret: true, Description: "Routing key of the events api v2 integration"},
+			{Name: "pdSeverity", Type: FieldTypeEnum, Options: utils.Severities, Description: "Severity of the events, the severity of the monitor url takes precedence"},
+		},
+		New: func() Notifier { return &pagerDutyNotifier{} },
+	})
+}
@@ -0,0 +1,162 @@
+package db
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/defraglabs/uptime/internal/utils"
+)
+
+// startPagerDutyStandIn serves the events api locally & records the events.
+// The returned func stops it.
+func startPagerDutyStandIn() (func(), *[]pagerDutyEvent) {
+	events := []pagerDutyEvent{}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		event := pagerDutyEvent{}
+		json.NewDecoder(r.Body).Decode(&event)
+		events = append(events, event)
+
+		w.WriteHeader(http.StatusAccepted)
+		w.Write([]byte(`{"status":"success","message":"Event processed","dedup_key":"` + event.DedupKey + `"}`))
+	}))
+
+	eventsURL := pagerDutyEventsURL
+	pagerDutyEventsURL = server.URL
+	stop := func() {
+		pagerDutyEventsURL = eventsURL
+		server.Close()
+	}
+
+	return stop, &events
+}
+
+func TestPagerDutyTriggersAndResolvesIncident(t *testing.T) {
+	stop, events := startPagerDutyStandIn()
+	defer stop()
+
+	integration := newTestIntegration(t, PagerDutyIntegration, map[string]string{"pdRoutingKey": "key", "pdSeverity": "error"})
+
+	monitorURL := MonitorURL{ID: "monitor", Name: "example", URL: "example.com", Protocol: "https"}
+	down := NewStatusAlert(monitorURL, utils.StatusUp, MonitorResult{Status: utils.StatusDown, StatusCode: 503})
+	up := NewStatusAlert(monitorURL, utils.StatusDown, MonitorResult{Status: utils.StatusUp, StatusCode: 200})
+
+	for _, alert := range []Alert{down, up} {
+		delivery, err := integration.Send(alert)
+		if err != nil {
+			t.Fatalf("unable to send the %s alert: %s", alert.Status, err)
+		}
+		if delivery.StatusCode != http.StatusAccepted {
+			t.Errorf("expected status 202, got %d", delivery.StatusCode)
+		}
+	}
+
+	if len(*events) != 2 {
+		t.Fatalf("expected 2 events, got %d", len(*events))
+	}
+
+	trigger, resolve := (*events)[0], (*events)[1]
+	if trigger.Action != PagerDutyActionTrigger || trigger.RoutingKey != "key" || trigger.Payload.Severity != "error" {
+		t.Errorf("unexpected trigger %s with routing key %s & severity %s", trigger.Action, trigger.RoutingKey, trigger.Payload.Severity)
+	}
+	if resolve.Action != PagerDutyActionResolve {
+		t.Errorf("expected the UP alert to resolve, got %s", resolve.Action)
+	}
+	if trigger.DedupKey != "uptime-monitor" || resolve.DedupKey != trigger.DedupKey {
+		t.Errorf("expected both events on uptime-monitor, got %s & %s", trigger.DedupKey, resolve.DedupKey)
+	}
+	if len(trigger.Links) != 1 || trigger.Links[0].Href != "https://example.com" {
+		t.Errorf("expected a link to https://example.com, got %v", trigger.Links)
+	}
+}
+
+func TestPagerDutyDedupKeys(t *testing.T) {
+	monitorURL := MonitorURL{ID: "monitor", Name: "example", URL: "example.com"}
+	result := MonitorResult{Status: utils.StatusDown}
+
+	cases := []struct {
+		name     string
+		alert    Alert
+		action   string
+		dedupKey string
+	}{
+		{"down", NewStatusAlert(monitorURL, utils.StatusUp, result), PagerDutyActionTrigger, "uptime-monitor"},
+		{"certificate", Alert{Type: AlertTypeCertificate, MonitorURL: monitorURL, Status: utils.StatusUp}, PagerDutyActionTrigger, "uptime-monitor-certificate"},
+		{"flapping", NewFlappingAlert(monitorURL, true, utils.StatusUp, result), PagerDutyActionTrigger, "uptime-monitor"},
+		{"stabilized up", NewFlappingAlert(monitorURL, false, utils.StatusUp, result), PagerDutyActionResolve, "uptime-monitor"},
+		{"stabilized down", NewFlappingAlert(monitorURL, false, utils.StatusDown, result), PagerDutyActionTrigger, "uptime-monitor"},
+	}
+
+	notifier := &pagerDutyNotifier{PDRoutingKey: "key"}
+	for _, c := range cases {
+		event := notifier.newPagerDutyEvent(c.alert)
+		if event.Action != c.action || event.DedupKey != c.dedupKey {
+			t.Errorf("%s: expected %s on %s, got %s on %s", c.name, c.action, c.dedupKey, event.Action, event.DedupKey)
+		}
+	}
+}
+
+func TestPagerDutyFlappingThenStabilized(t *testing.T) {
+	stop, events := startPagerDutyStandIn()
+	defer stop()
+
+	integration := newTestIntegration(t, PagerDutyIntegration, map[string]string{"pdRoutingKey": "key"})
+
+	// The url went DOWN, started flapping & stabilized UP: the incident is resolved.
+	monitorURL := MonitorURL{ID: "monitor", Name: "example", URL: "example.com"}
+	alerts := []Alert{
+		NewStatusAlert(monitorURL, utils.StatusUp, MonitorResult{Status: utils.StatusDown}),
+		NewFlappingAlert(monitorURL, true, utils.StatusDown, MonitorResult{Status: utils.StatusUp}),
+		NewFlappingAlert(monitorURL, false, utils.StatusUp, MonitorResult{Status: utils.StatusUp}),
+	}
+	for _, alert := range alerts {
+		integration.Send(alert)
+	}
+
+	if len(*events) != 3 {
+		t.Fatalf("expected 3 events, got %d", len(*events))
+	}
+
+	for i, expected := range []string{PagerDutyActionTrigger, PagerDutyActionTrigger, PagerDutyActionResolve} {
+		event := (*events)[i]
+		if event.Action != expected || event.DedupKey != "uptime-monitor" {
+			t.Errorf("event %d: expected %s on uptime-monitor, got %s on %s", i, expected, event.Action, event.DedupKey)
+		}
+	}
+
+	if severity := (*events)[1].Payload.Severity; severity != utils.SeverityWarning {
+		t.Errorf("expected the flapping warning, got %s", severity)
+	}
+}
+
+func TestPagerDutyTestAlertIsResolved(t *testing.T) {
+	stop, events := startPagerDutyStandIn()
+	defer stop()
+
+	integration := newTestIntegration(t, PagerDutyIntegration, map[string]string{"pdRoutingKey": "key"})
+
+	delivery, err := integration.Send(NewTestAlert(integration, time.Now()))
+	if err != nil {
+		t.Fatalf("unable to send the test alert: %s", err)
+	}
+	if delivery.StatusCode != http.StatusAccepted {
+		t.Errorf("expected status 202, got %d", delivery.StatusCode)
+	}
+
+	if len(*events) != 2 {
+		t.Fatalf("expected the test incident to be triggered & resolved, got %d events", len(*events))
+	}
+
+	trigger, resolve := (*events)[0], (*events)[1]
+	if trigger.Action != PagerDutyActionTrigger || trigger.Payload.Severity != utils.SeverityInfo {
+		t.Errorf("expected an info trigger, got %s with severity %s", trigger.Action, trigger.Payload.Severity)
+	}
+	if resolve.Action != PagerDutyActionResolve {
+		t.Errorf("expected the test incident to be resolved, got %s", resolve.Action)
+	}
+	if trigger.DedupKey != "uptime-test-integration" || resolve.DedupKey != trigger.DedupKey {
+		t.Errorf("expected both events on uptime-test-integration, got %s & %s", trigger.DedupKey, resolve.DedupKey)
+	}
+}
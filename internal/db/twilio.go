@@ -0,0 +1,244 @@
+package db
+
+import (
+	"bytes"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"regexp"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/defraglabs/uptime/internal/utils"
+	log "github.com/sirupsen/logrus"
+)
+
+// twilioBaseURL is the default base url of the twilio compatible api.
+const twilioBaseURL = "https://api.twilio.com"
+
+const (
+	// maxSMSLength is the maximum length of an sms, a single segment.
+	maxSMSLength = 160
+
+	// maxCallMessageLength is the maximum length of the message read during a call.
+	maxCallMessageLength = 300
+
+	// phoneRateLimitWindow is the window of the rate limit of the phone numbers.
+	phoneRateLimitWindow = time.Hour
+)
+
+// phoneNumberRegex matches phone numbers in E.164 format.
+var phoneNumberRegex = regexp.MustCompile(`^\+[1-9][0-9]{6,14}$`)
+
+// ErrPhoneRateLimited is returned when the phone number has received too many sms & calls, see
+// utils.GetPhoneRateLimit. The alert is dropped.
+var ErrPhoneRateLimited = errors.New("phone number rate limited")
+
+// phoneRateLimiter counts the sms & calls sent to each phone number within the window.
+// The deliveries run on the leader, so the counts are kept in memory.
+type phoneRateLimiter struct {
+	mutex sync.Mutex
+	sent  map[string][]time.Time
+}
+
+var phoneLimiter = &phoneRateLimiter{sent: make(map[string][]time.Time)}
+
+// reserve records a send to the number at t unless the number reached the limit within the window.
+// Forced sends are recorded whatever the count.
+func (limiter *phoneRateLimiter) reserve(number string, limit int, t time.Time, force bool) bool {
+	limiter.mutex.Lock()
+	defer limiter.mutex.Unlock()
+
+	sent := []time.Time{}
+	for _, sentAt := range limiter.sent[number] {
+		if t.Sub(sentAt) < phoneRateLimitWindow {
+			sent = append(sent, sentAt)
+		}
+	}
+
+	allowed := force || len(sent) < limit
+	if allowed {
+		sent = append(sent, t)
+	}
+
+	limiter.sent[number] = sent
+	return allowed
+}
+
+// release forgets the send to the number reserved at t, which the api did not accept.
+func (limiter *phoneRateLimiter) release(number string, t time.Time) {
+	limiter.mutex.Lock()
+	defer limiter.mutex.Unlock()
+
+	sent := limiter.sent[number]
+	for i, sentAt := range sent {
+		if sentAt.Equal(t) {
+			limiter.sent[number] = append(sent[:i], sent[i+1:]...)
+			return
+		}
+	}
+}
+
+// twilioNotifier sends the alerts as sms, or calls and reads them, through a twilio compatible api.
+type twilioNotifier struct {
+	AccountSID string `bson:"accountSID" json:"accountSID"`
+	AuthToken  string `bson:"authToken" json:"authToken"`
+	FromNumber string `bson:"fromNumber" json:"fromNumber"`
+	ToNumber   string `bson:"toNumber" json:"toNumber"`
+
+	// BaseURL of the api, https://api.twilio.com by default.
+	BaseURL string `bson:"baseURL" json:"baseURL"`
+
+	// call is set for voice integrations.
+	call bool
+}
+
+// getType returns the integration type of the notifier.
+func (notifier *twilioNotifier) getType() string {
+	if notifier.call {
+		return VoiceIntegration
+	}
+
+	return SMSIntegration
+}
+
+// Validate validates the sms & voice config.
+func (notifier *twilioNotifier) Validate() string {
+	if notifier.AccountSID == "" {
+		return fmt.Sprintf("accountSID is required for %s integration", notifier.getType())
+	} else if notifier.AuthToken == "" {
+		return fmt.Sprintf("authToken is required for %s integration", notifier.getType())
+	} else if !phoneNumberRegex.MatchString(notifier.FromNumber) {
+		return "fromNumber should be a phone number in E.164 format, e.g. +14155550100"
+	} else if !phoneNumberRegex.MatchString(notifier.ToNumber) {
+		return "toNumber should be a phone number in E.164 format, e.g. +14155550100"
+	}
+
+	if notifier.BaseURL != "" {
+		baseURL, err := url.Parse(notifier.BaseURL)
+		if err != nil || (baseURL.Scheme != "http" && baseURL.Scheme != "https") || baseURL.Host == "" {
+			return "baseURL should be a valid http/https url"
+		}
+	}
+
+	return ""
+}
+
+// getBaseURL returns the base url of the api, without trailing slash.
+func (notifier *twilioNotifier) getBaseURL() string {
+	if notifier.BaseURL == "" {
+		return twilioBaseURL
+	}
+
+	return strings.TrimSuffix(notifier.BaseURL, "/")
+}
+
+// truncateMessage caps the message at the length in characters, ending it with `...` when truncated.
+func truncateMessage(message string, length int) string {
+	characters := []rune(message)
+	if len(characters) <= length {
+		return message
+	}
+
+	return string(characters[:length-3]) + "..."
+}
+
+// getPhoneMessage returns the message of the alert sent by sms or read during the call.
+func getPhoneMessage(alert Alert) string {
+	message := alert.Summary()
+	if alert.Result.StatusDescription != "" && alert.Status == utils.StatusDown {
+		message = fmt.Sprintf("%s: %s", message, alert.Result.StatusDescription)
+	}
+
+	return fmt.Sprintf("Uptime alert. %s", message)
+}
+
+// newCallTwiML returns the instructions of the call, reading the message twice.
+func newCallTwiML(message string) string {
+	var escaped bytes.Buffer
+	xml.EscapeText(&escaped, []byte(truncateMessage(message, maxCallMessageLength)))
+
+	return fmt.Sprintf(`<Response><Say loop="2">%s</Say></Response>`, escaped.String())
+}
+
+// Send sends the sms, or places the call, unless the number is rate limited. The alerts resolving
+// an outage are sent regardless, so the outage is never left open. Only the sends accepted by the
+// api count towards the limit.
+func (notifier *twilioNotifier) Send(integration Integration, alert Alert) (Delivery, error) {
+	if notifier.AccountSID == "" || notifier.ToNumber == "" {
+		log.Infof("Invalid integration. Account sid or number not found for integration %s", integration.ID)
+
+		return Delivery{}, errors.New("invalid integration. account sid or number not found")
+	}
+
+	sentAt := time.Now()
+	if !phoneLimiter.reserve(notifier.ToNumber, utils.GetPhoneRateLimit(), sentAt, alert.Resolves()) {
+		log.Infof("Not sending %s for integration %s, %s is rate limited", notifier.getType(), integration.ID, notifier.ToNumber)
+
+		return Delivery{}, ErrPhoneRateLimited
+	}
+
+	form := url.Values{}
+	form.Set("To", notifier.ToNumber)
+	form.Set("From", notifier.FromNumber)
+
+	resource := "Messages"
+	if notifier.call {
+		resource = "Calls"
+		form.Set("Twiml", newCallTwiML(getPhoneMessage(alert)))
+	} else {
+		form.Set("Body", truncateMessage(getPhoneMessage(alert), maxSMSLength))
+	}
+
+	requestURL := fmt.Sprintf(
+		"%s/2010-04-01/Accounts/%s/%s.json", notifier.getBaseURL(), url.PathEscape(notifier.AccountSID), resource,
+	)
+	req, err := http.NewRequest(http.MethodPost, requestURL, strings.NewReader(form.Encode()))
+	if err != nil {
+		phoneLimiter.release(notifier.ToNumber, sentAt)
+		return Delivery{}, err
+	}
+
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	req.SetBasicAuth(notifier.AccountSID, notifier.AuthToken)
+
+	resp, err := integrationClient.Do(req)
+	if err != nil {
+		phoneLimiter.release(notifier.ToNumber, sentAt)
+		return Delivery{}, fmt.Errorf("%s send failed: %s", notifier.getType(), err)
+	}
+	defer resp.Body.Close()
+
+	delivery, err := readDelivery(resp)
+	if err != nil {
+		phoneLimiter.release(notifier.ToNumber, sentAt)
+	}
+
+	return delivery, err
+}
+
+// twilioFields are the config fields of the sms & voice integrations.
+var twilioFields = []NotifierField{
+	{Name: "accountSID", Type: FieldTypeString, Required: true, Description: "Account sid of the api"},
+	{Name: "authToken", Type: FieldTypeString, Required: true, Secret: true, Description: "Auth token of the account"},
+	{Name: "fromNumber", Type: FieldTypeString, Required: true, Description: "Number the alerts are sent from, in E.164 format"},
+	{Name: "toNumber", Type: FieldTypeString, Required: true, Description: "Number the alerts are sent to, in E.164 format"},
+	{Name: "baseURL", Type: FieldTypeURL, Description: "Base url of the twilio compatible api, https://api.twilio.com by default"},
+}
+
+func init() {
+	RegisterNotifier(NotifierType{
+		Name:   SMSIntegration,
+		Fields: twilioFields,
+		New:    func() Notifier { return &twilioNotifier{} },
+	})
+
+	RegisterNotifier(NotifierType{
+		Name:   VoiceIntegration,
+		Fields: twilioFields,
+		New:    func() Notifier { return &twilioNotifier{call: true} },
+	})
+}
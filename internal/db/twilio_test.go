@@ -0,0 +1,234 @@
+package db
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+	"unicode/utf8"
+
+	"github.com/defraglabs/uptime/internal/utils"
+)
+
+// twilioRequest is a request received by the twilio stub.
+type twilioRequest struct {
+	Path     string
+	Username string
+	Password string
+	Form     url.Values
+}
+
+// startTwilioStub serves the messages & calls of the twilio api locally & records the requests.
+func startTwilioStub(requests *[]twilioRequest) *httptest.Server {
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		r.ParseForm()
+		username, password, _ := r.BasicAuth()
+		*requests = append(*requests, twilioRequest{Path: r.URL.Path, Username: username, Password: password, Form: r.PostForm})
+
+		w.WriteHeader(http.StatusCreated)
+		w.Write([]byte(`{"sid": "SM42", "status": "queued"}`))
+	}))
+}
+
+func TestSMSIsCappedAndRateLimited(t *testing.T) {
+	requests := []twilioRequest{}
+	server := startTwilioStub(&requests)
+	defer server.Close()
+
+	integration := newTestIntegration(t, SMSIntegration, map[string]string{
+		"accountSID": "AC42",
+		"authToken":  "token",
+		"fromNumber": "+14155550100",
+		"toNumber":   "+14155550101",
+		"baseURL":    server.URL + "/",
+	})
+
+	monitorURL := MonitorURL{ID: "monitor", Name: "example", URL: strings.Repeat("example", 30) + ".com"}
+	alert := NewStatusAlert(monitorURL, utils.StatusUp, MonitorResult{Status: utils.StatusDown})
+
+	for i := 0; i < utils.GetPhoneRateLimit(); i++ {
+		if _, err := integration.Send(alert); err != nil {
+			t.Fatalf("unable to send the sms: %s", err)
+		}
+	}
+
+	if _, err := integration.Send(alert); err != ErrPhoneRateLimited {
+		t.Errorf("expected the number to be rate limited, got %v", err)
+	}
+
+	if len(requests) != utils.GetPhoneRateLimit() {
+		t.Fatalf("expected %d sms, got %d", utils.GetPhoneRateLimit(), len(requests))
+	}
+
+	sms := requests[0]
+	if sms.Path != "/2010-04-01/Accounts/AC42/Messages.json" || sms.Username != "AC42" || sms.Password != "token" {
+		t.Errorf("unexpected sms request %s authenticated as %s", sms.Path, sms.Username)
+	}
+	if sms.Form.Get("To") != "+14155550101" || sms.Form.Get("From") != "+14155550100" {
+		t.Errorf("unexpected numbers %s & %s", sms.Form.Get("To"), sms.Form.Get("From"))
+	}
+	if body := sms.Form.Get("Body"); len(body) != maxSMSLength || !strings.HasSuffix(body, "...") {
+		t.Errorf("expected the sms to be capped at %d characters, got %q", maxSMSLength, body)
+	}
+}
+
+func TestVoiceCallReadsAlert(t *testing.T) {
+	requests := []twilioRequest{}
+	server := startTwilioStub(&requests)
+	defer server.Close()
+
+	integration := newTestIntegration(t, VoiceIntegration, map[string]string{
+		"accountSID": "AC42",
+		"authToken":  "token",
+		"fromNumber": "+14155550100",
+		"toNumber":   "+14155550102",
+		"baseURL":    server.URL,
+	})
+
+	monitorURL := MonitorURL{ID: "monitor", Name: "example", URL: "example.com"}
+	alert := NewStatusAlert(monitorURL, utils.StatusUp, MonitorResult{Status: utils.StatusDown, StatusDescription: "<timeout>"})
+	if _, err := integration.Send(alert); err != nil {
+		t.Fatalf("unable to place the call: %s", err)
+	}
+
+	if len(requests) != 1 || requests[0].Path != "/2010-04-01/Accounts/AC42/Calls.json" {
+		t.Fatalf("expected a call, got %v", requests)
+	}
+
+	expected := `<Response><Say loop="2">Uptime alert. Site example.com DOWN: &lt;timeout&gt;</Say></Response>`
+	if twiml := requests[0].Form.Get("Twiml"); twiml != expected {
+		t.Errorf("expected %s, got %s", expected, twiml)
+	}
+}
+
+func TestSMSRejectedByTheAPIDontCount(t *testing.T) {
+	// The api rejects the sms until the limit is exceeded.
+	requests := []twilioRequest{}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests = append(requests, twilioRequest{Path: r.URL.Path})
+		if len(requests) <= utils.GetPhoneRateLimit()+1 {
+			w.WriteHeader(http.StatusBadRequest)
+			w.Write([]byte(`{"code": 21211, "message": "Invalid 'To' Phone Number"}`))
+			return
+		}
+
+		w.WriteHeader(http.StatusCreated)
+	}))
+	defer server.Close()
+
+	integration := newTestIntegration(t, SMSIntegration, map[string]string{
+		"accountSID": "AC42",
+		"authToken":  "token",
+		"fromNumber": "+14155550100",
+		"toNumber":   "+14155550103",
+		"baseURL":    server.URL,
+	})
+
+	alert := NewStatusAlert(MonitorURL{ID: "monitor", URL: "example.com"}, utils.StatusUp, MonitorResult{Status: utils.StatusDown})
+	for i := 0; i < utils.GetPhoneRateLimit()+1; i++ {
+		if _, err := integration.Send(alert); err == nil || err == ErrPhoneRateLimited {
+			t.Fatalf("expected the sms to be rejected by the api, got %v", err)
+		}
+	}
+
+	if _, err := integration.Send(alert); err != nil {
+		t.Errorf("expected the rejected sms not to count towards the limit, got %v", err)
+	}
+	if len(requests) != utils.GetPhoneRateLimit()+2 {
+		t.Errorf("expected %d requests, got %d", utils.GetPhoneRateLimit()+2, len(requests))
+	}
+}
+
+func TestSMSResolvingTheOutageIsSentBeyondTheLimit(t *testing.T) {
+	requests := []twilioRequest{}
+	server := startTwilioStub(&requests)
+	defer server.Close()
+
+	integration := newTestIntegration(t, SMSIntegration, map[string]string{
+		"accountSID": "AC42",
+		"authToken":  "token",
+		"fromNumber": "+14155550100",
+		"toNumber":   "+14155550104",
+		"baseURL":    server.URL,
+	})
+
+	monitorURL := MonitorURL{ID: "monitor", URL: "example.com"}
+	down := NewStatusAlert(monitorURL, utils.StatusUp, MonitorResult{Status: utils.StatusDown})
+	for i := 0; i < utils.GetPhoneRateLimit(); i++ {
+		integration.Send(down)
+	}
+
+	if _, err := integration.Send(down); err != ErrPhoneRateLimited {
+		t.Fatalf("expected the DOWN alert to be rate limited, got %v", err)
+	}
+
+	alerts := []Alert{
+		NewStatusAlert(monitorURL, utils.StatusDown, MonitorResult{Status: utils.StatusUp}),
+		NewFlappingAlert(monitorURL, false, utils.StatusUp, MonitorResult{Status: utils.StatusUp}),
+	}
+	for _, alert := range alerts {
+		if _, err := integration.Send(alert); err != nil {
+			t.Errorf("expected the %s alert resolving the outage to be sent, got %v", alert.Type, err)
+		}
+	}
+
+	if len(requests) != utils.GetPhoneRateLimit()+2 {
+		t.Errorf("expected %d sms, got %d", utils.GetPhoneRateLimit()+2, len(requests))
+	}
+}
+
+func TestPhoneRateLimiter(t *testing.T) {
+	limiter := &phoneRateLimiter{sent: make(map[string][]time.Time)}
+	number := "+14155550105"
+	start := time.Now()
+
+	for i := 0; i < 2; i++ {
+		if !limiter.reserve(number, 2, start.Add(time.Duration(i)*time.Minute), false) {
+			t.Fatalf("expected send %d to be allowed", i)
+		}
+	}
+
+	last := start.Add(2 * time.Minute)
+	if limiter.reserve(number, 2, last, false) {
+		t.Errorf("expected the third send within the hour to be limited")
+	}
+	if !limiter.reserve(number, 2, last, true) {
+		t.Errorf("expected the forced send to be allowed")
+	}
+
+	limiter.release(number, last)
+	limiter.release(number, start)
+	if !limiter.reserve(number, 2, last, false) {
+		t.Errorf("expected a released send not to count")
+	}
+
+	if !limiter.reserve(number, 2, start.Add(phoneRateLimitWindow+time.Minute), false) {
+		t.Errorf("expected the sends older than the window not to count")
+	}
+}
+
+func TestTruncateMessage(t *testing.T) {
+	cases := []struct {
+		message  string
+		length   int
+		expected string
+	}{
+		{"Site example.com DOWN", 160, "Site example.com DOWN"},
+		{"Site example.com DOWN", 10, "Site ex..."},
+		{"Site café.fr DOWN", 17, "Site café.fr DOWN"},
+		{"Site café.fr DOWN", 12, "Site café..."},
+		{"Site 例え.jp DOWN", 10, "Site 例え..."},
+		{"Site 🔥🔥🔥🔥 DOWN", 9, "Site 🔥..."},
+	}
+
+	for _, c := range cases {
+		truncated := truncateMessage(c.message, c.length)
+		if truncated != c.expected {
+			t.Errorf("%q at %d: expected %q, got %q", c.message, c.length, c.expected, truncated)
+		} else if !utf8.ValidString(truncated) || utf8.RuneCountInString(truncated) > c.length {
+			t.Errorf("%q at %d: expected at most %d valid characters, got %q", c.message, c.length, c.length, truncated)
+		}
+	}
+}
@@ -10,6 +10,7 @@ import (
 	"github.com/mongodb/mongo-go-driver/options"
 
 	"github.com/defraglabs/uptime/internal/forms"
+	"github.com/defraglabs/uptime/internal/utils"
 	"github.com/mongodb/mongo-go-driver/bson"
 	"github.com/mongodb/mongo-go-driver/bson/objectid"
 	log "github.com/sirupsen/logrus"
@@ -32,6 +33,18 @@ const (
 
 	// IntegrationCollection stores all the integrations configured by an user
 	IntegrationCollection = "integration"
+
+	// NotificationCollection is the outbox of the alerts sent through the integrations.
+	NotificationCollection = "notification"
+
+	// EscalationPolicyCollection stores the escalation policies configured by an user.
+	EscalationPolicyCollection = "escalationPolicy"
+
+	// IncidentCollection stores the incidents escalated through the escalation policies.
+	IncidentCollection = "incident"
+
+	// MaintenanceWindowCollection stores the maintenance windows configured by an user.
+	MaintenanceWindowCollection = "maintenanceWindow"
 )
 
 // AddIndexes adds mongo indexes.
@@ -40,6 +53,9 @@ func (datastore *Datastore) AddIndexes() {
 
 	addIndexesOnMonitorResultCollection(dbClient, datastore)
 	addTextIndexesOnMonitorURLCollection(dbClient, datastore)
+	addHeartbeatIndexOnMonitorURLCollection(dbClient, datastore)
+	addIndexesOnNotificationCollection(dbClient, datastore)
+	addIndexesOnIncidentCollection(dbClient, datastore)
 
 	log.Info("Added db indexes")
 }
@@ -71,6 +87,63 @@ func addTextIndexesOnMonitorURLCollection(dbClient *mongo.Client, datastore *Dat
 	)
 }
 
+func addHeartbeatIndexOnMonitorURLCollection(dbClient *mongo.Client, datastore *Datastore) {
+	monitorURLCollection := dbClient.Database(datastore.DatabaseName).Collection(MonitorURLCollection)
+
+	indexes := monitorURLCollection.Indexes()
+	indexes.CreateOne(
+		context.Background(),
+		mongo.IndexModel{
+			Keys: bsonx.Doc{{"heartbeatToken", bsonx.Int32(1)}},
+			// The other monitor urls have an empty token.
+			Options: mongo.NewIndexOptionsBuilder().
+				Unique(true).
+				PartialFilterExpression(bsonx.Doc{{"heartbeatToken", bsonx.Document(bsonx.Doc{{"$gt", bsonx.String("")}})}}).
+				Build(),
+		},
+	)
+}
+
+func addIndexesOnNotificationCollection(dbClient *mongo.Client, datastore *Datastore) {
+	notificationCollection := dbClient.Database(datastore.DatabaseName).Collection(NotificationCollection)
+
+	indexes := notificationCollection.Indexes()
+	indexes.CreateMany(
+		context.Background(),
+		[]mongo.IndexModel{
+			mongo.IndexModel{
+				Keys: bsonx.Doc{{"status", bsonx.Int32(1)}, {"nextAttemptAt", bsonx.Int32(1)}},
+			},
+			mongo.IndexModel{
+				Keys: bsonx.Doc{{"integrationID", bsonx.Int32(1)}, {"createdAt", bsonx.Int32(-1)}},
+			},
+			mongo.IndexModel{
+				Keys: bsonx.Doc{{"monitorURLID", bsonx.Int32(1)}, {"createdAt", bsonx.Int32(-1)}},
+			},
+		},
+	)
+}
+
+func addIndexesOnIncidentCollection(dbClient *mongo.Client, datastore *Datastore) {
+	incidentCollection := dbClient.Database(datastore.DatabaseName).Collection(IncidentCollection)
+
+	indexes := incidentCollection.Indexes()
+	indexes.CreateMany(
+		context.Background(),
+		[]mongo.IndexModel{
+			mongo.IndexModel{
+				Keys: bsonx.Doc{{"status", bsonx.Int32(1)}, {"nextEscalationAt", bsonx.Int32(1)}},
+			},
+			mongo.IndexModel{
+				Keys: bsonx.Doc{{"monitorURLID", bsonx.Int32(1)}, {"status", bsonx.Int32(1)}},
+			},
+			mongo.IndexModel{
+				Keys: bsonx.Doc{{"userID", bsonx.Int32(1)}, {"createdAt", bsonx.Int32(-1)}},
+			},
+		},
+	)
+}
+
 // GenerateObjectID generates a new objectid.
 func GenerateObjectID() objectid.ObjectID {
 	return objectid.New()
@@ -178,6 +251,9 @@ func (datastore *Datastore) AddMonitoringURL(monitorURLForm forms.MonitorURLForm
 	dbClient := datastore.Client
 	collection := dbClient.Database(datastore.DatabaseName).Collection(MonitorURLCollection)
 	monitorURLForm.MonitoringStatus = MonitoringStatusRunning
+	if monitorURLForm.Type == "" {
+		monitorURLForm.Type = utils.MonitorTypeHTTP
+	}
 
 	_, err := collection.InsertOne(
 		context.Background(),
@@ -196,13 +272,53 @@ func (datastore *Datastore) AddMonitoringURL(monitorURLForm forms.MonitorURLForm
 			Frequency:        monitorURLForm.Frequency,
 			Unit:             monitorURLForm.Unit,
 			Name:             monitorURLForm.Name,
+			Type:             monitorURLForm.Type,
 			MonitoringStatus: MonitoringStatusRunning,
+			Payload:          monitorURLForm.Payload,
+			ExpectedBanner:   monitorURLForm.ExpectedBanner,
+			RecordType:       monitorURLForm.RecordType,
+			Resolver:         monitorURLForm.Resolver,
+			ExpectedValues:   monitorURLForm.ExpectedValues,
+			HeartbeatToken:   monitorURLForm.HeartbeatToken,
+			CronExpression:   monitorURLForm.CronExpression,
+			GracePeriod:      monitorURLForm.GracePeriod,
+			Method:           monitorURLForm.Method,
+			Headers:          monitorURLForm.Headers,
+			Body:             monitorURLForm.Body,
+			ContentType:      monitorURLForm.ContentType,
+			Timeout:          monitorURLForm.Timeout,
+			UserAgent:        monitorURLForm.UserAgent,
+			Assertions:       getAssertions(monitorURLForm.Assertions),
+
+			CertificateExpiryThresholds: monitorURLForm.CertificateExpiryThresholds,
+			Tags:                        monitorURLForm.Tags,
+			ReminderInterval:            monitorURLForm.ReminderInterval,
+			ReminderMaxCount:            monitorURLForm.ReminderMaxCount,
+			IntegrationIDs:              monitorURLForm.IntegrationIDs,
+			EscalationPolicyID:          monitorURLForm.EscalationPolicyID,
+			Severity:                    monitorURLForm.Severity,
+			FailureThreshold:            monitorURLForm.FailureThreshold,
+			RecoveryThreshold:           monitorURLForm.RecoveryThreshold,
 		}
 	}
 
 	return monitorURL
 }
 
+func getAssertions(assertionForms []forms.AssertionForm) []Assertion {
+	assertions := make([]Assertion, len(assertionForms))
+	for i, assertionForm := range assertionForms {
+		assertions[i] = Assertion{
+			Type:       assertionForm.Type,
+			Property:   assertionForm.Property,
+			Comparison: assertionForm.Comparison,
+			Value:      assertionForm.Value,
+		}
+	}
+
+	return assertions
+}
+
 // GetMonitoringURLS  gets all added url's
 func (datastore *Datastore) GetMonitoringURLS() []MonitorURL {
 	dbClient := datastore.Client
@@ -283,6 +399,115 @@ func (datastore *Datastore) GetMonitoringURLSByUserIDAndStatus(userID, status st
 	return count
 }
 
+// GetMonitoringURLSByIntegrationID gets the monitoring urls of an user whose alerts are
+// sent through the integration, including the ones sent through all the integrations.
+func (datastore *Datastore) GetMonitoringURLSByIntegrationID(userID, integrationID string) []MonitorURL {
+	dbClient := datastore.Client
+	collection := dbClient.Database(datastore.DatabaseName).Collection(MonitorURLCollection)
+
+	cursor, err := collection.Find(
+		context.Background(),
+		bson.D{
+			{"userID", userID},
+			{"$or", bson.A{
+				bson.D{{"integrationIDs", integrationID}},
+				bson.D{{"integrationIDs", bson.D{{"$size", 0}}}},
+				bson.D{{"integrationIDs", nil}},
+			}},
+		},
+	)
+	if err != nil {
+		log.Warnf("Unable to get monitoring urls of integration %s: %s", integrationID, err)
+		return []MonitorURL{}
+	}
+
+	monitorURLS := []MonitorURL{}
+	for cursor.Next(context.Background()) {
+		monitorURL := MonitorURL{}
+		err := cursor.Decode(&monitorURL)
+		if err != nil {
+			log.Fatal("error while parsing cursor for monitor urls")
+		}
+
+		monitorURLS = append(monitorURLS, monitorURL)
+	}
+
+	return monitorURLS
+}
+
+// SetMonitoringURLIntegrations sets the integrations the alerts of the monitoring url are sent through.
+func (datastore *Datastore) SetMonitoringURLIntegrations(userID, monitoringURLID string, integrationIDs []string) {
+	dbClient := datastore.Client
+	collection := dbClient.Database(datastore.DatabaseName).Collection(MonitorURLCollection)
+
+	collection.FindOneAndUpdate(
+		context.Background(),
+		bson.D{
+			{"_id", monitoringURLID},
+			{"userID", userID},
+		},
+		bson.D{
+			{"$set", bson.D{
+				{"integrationIDs", integrationIDs},
+			}},
+		},
+	)
+}
+
+// GetMonitoringURLSRoutedOnlyToIntegrationCount gets the count of monitoring urls of an user whose
+// alerts are only sent through the integration.
+func (datastore *Datastore) GetMonitoringURLSRoutedOnlyToIntegrationCount(userID, integrationID string) int64 {
+	dbClient := datastore.Client
+	collection := dbClient.Database(datastore.DatabaseName).Collection(MonitorURLCollection)
+
+	count, _ := collection.Count(
+		context.Background(),
+		bson.D{
+			{"userID", userID},
+			{"integrationIDs", bson.A{integrationID}},
+		},
+	)
+
+	return count
+}
+
+// RemoveIntegrationFromMonitoringURLS stops sending the alerts of the monitoring urls through
+// a deleted integration. Monitoring urls left without integrations would use all of them, so the
+// integration shouldn't be the only one of a monitoring url, see GetMonitoringURLSRoutedOnlyToIntegrationCount.
+func (datastore *Datastore) RemoveIntegrationFromMonitoringURLS(userID, integrationID string) {
+	dbClient := datastore.Client
+	collection := dbClient.Database(datastore.DatabaseName).Collection(MonitorURLCollection)
+
+	collection.UpdateMany(
+		context.Background(),
+		bson.D{
+			{"userID", userID},
+			{"integrationIDs", integrationID},
+		},
+		bson.D{
+			{"$pull", bson.D{
+				{"integrationIDs", integrationID},
+			}},
+		},
+	)
+}
+
+// GetFlappingMonitoringURLSByUserIDCount gets the count of flapping monitoring urls of an user.
+func (datastore *Datastore) GetFlappingMonitoringURLSByUserIDCount(userID string) int64 {
+	dbClient := datastore.Client
+	collection := dbClient.Database(datastore.DatabaseName).Collection(MonitorURLCollection)
+
+	count, _ := collection.Count(
+		context.Background(),
+		bson.D{
+			{"userID", userID},
+			{"flapping", true},
+		},
+	)
+
+	return count
+}
+
 // GetMonitoringURLSByUserID gets all URL's for user.
 func (datastore *Datastore) GetMonitoringURLSByUserID(userID string) []MonitorURL {
 	dbClient := datastore.Client
@@ -340,18 +565,188 @@ func (datastore *Datastore) UpdateMonitoringURLByUserID(userID, monitoringURLID
 	dbClient := datastore.Client
 	collection := dbClient.Database(datastore.DatabaseName).Collection(MonitorURLCollection)
 
+	update := bson.D{
+		{"name", monitorURLForm.Name},
+		{"protocol", monitorURLForm.Protocol},
+		{"frequency", monitorURLForm.Frequency},
+		{"unit", monitorURLForm.Unit},
+		// Frequency might have changed, let the scheduler pick the url right away.
+		{"nextCheckAt", time.Time{}},
+	}
+
+	// Options & assertions are kept as is when they are not part of the update.
+	if monitorURLForm.Payload != "" {
+		update = append(update, bson.E{"payload", monitorURLForm.Payload})
+	}
+	if monitorURLForm.ExpectedBanner != "" {
+		update = append(update, bson.E{"expectedBanner", monitorURLForm.ExpectedBanner})
+	}
+	if monitorURLForm.RecordType != "" {
+		update = append(update, bson.E{"recordType", monitorURLForm.RecordType})
+	}
+	if monitorURLForm.Resolver != "" {
+		update = append(update, bson.E{"resolver", monitorURLForm.Resolver})
+	}
+	if monitorURLForm.ExpectedValues != nil {
+		update = append(update, bson.E{"expectedValues", monitorURLForm.ExpectedValues})
+	}
+	if monitorURLForm.RecordType != "" || monitorURLForm.Resolver != "" || monitorURLForm.ResetAcceptedValues {
+		// The answers of the next lookup are accepted.
+		update = append(update, bson.E{"acceptedValues", []string{}})
+	}
+	if monitorURLForm.CronExpression != "" {
+		update = append(update, bson.E{"cronExpression", monitorURLForm.CronExpression})
+	}
+	if monitorURLForm.GracePeriod != 0 {
+		update = append(update, bson.E{"gracePeriod", monitorURLForm.GracePeriod})
+	}
+	if monitorURLForm.Method != "" {
+		update = append(update, bson.E{"method", monitorURLForm.Method})
+	}
+	if monitorURLForm.Headers != nil {
+		update = append(update, bson.E{"headers", monitorURLForm.Headers})
+	}
+	if monitorURLForm.Body != "" {
+		update = append(update, bson.E{"body", monitorURLForm.Body})
+	}
+	if monitorURLForm.ContentType != "" {
+		update = append(update, bson.E{"contentType", monitorURLForm.ContentType})
+	}
+	if monitorURLForm.Timeout != 0 {
+		update = append(update, bson.E{"timeout", monitorURLForm.Timeout})
+	}
+	if monitorURLForm.UserAgent != "" {
+		update = append(update, bson.E{"userAgent", monitorURLForm.UserAgent})
+	}
+	if monitorURLForm.Assertions != nil {
+		update = append(update, bson.E{"assertions", monitorURLForm.Assertions})
+	}
+	if monitorURLForm.CertificateExpiryThresholds != nil {
+		update = append(update, bson.E{"certificateExpiryThresholds", monitorURLForm.CertificateExpiryThresholds})
+	}
+	if monitorURLForm.Tags != nil {
+		update = append(update, bson.E{"tags", monitorURLForm.Tags})
+	}
+	if monitorURLForm.IntegrationIDs != nil {
+		update = append(update, bson.E{"integrationIDs", monitorURLForm.IntegrationIDs})
+	}
+	if monitorURLForm.EscalationPolicyID != "" || monitorURLForm.ClearEscalationPolicy {
+		update = append(update, bson.E{"escalationPolicyID", monitorURLForm.EscalationPolicyID})
+	}
+	if monitorURLForm.Severity != "" {
+		update = append(update, bson.E{"severity", monitorURLForm.Severity})
+	}
+	if monitorURLForm.ReminderInterval != 0 || monitorURLForm.ClearReminder {
+		update = append(update, bson.E{"reminderInterval", monitorURLForm.ReminderInterval})
+	}
+	if monitorURLForm.ReminderMaxCount != 0 || monitorURLForm.ClearReminder {
+		update = append(update, bson.E{"reminderMaxCount", monitorURLForm.ReminderMaxCount})
+	}
+	if monitorURLForm.FailureThreshold != 0 {
+		update = append(update, bson.E{"failureThreshold", monitorURLForm.FailureThreshold})
+	}
+	if monitorURLForm.RecoveryThreshold != 0 {
+		update = append(update, bson.E{"recoveryThreshold", monitorURLForm.RecoveryThreshold})
+	}
+
 	collection.FindOneAndUpdate(
 		context.Background(),
 		bson.D{
 			{"userID", userID},
 			{"_id", monitoringURLID},
 		},
+		bson.D{
+			{"$set", update},
+		},
+	)
+}
+
+// UpdateMonitoringURLSchedule stores when the url was last checked & when it should be checked next.
+func (datastore *Datastore) UpdateMonitoringURLSchedule(monitoringURLID string, lastCheckedAt, nextCheckAt time.Time) {
+	dbClient := datastore.Client
+	collection := dbClient.Database(datastore.DatabaseName).Collection(MonitorURLCollection)
+
+	collection.FindOneAndUpdate(
+		context.Background(),
+		bson.D{
+			{"_id", monitoringURLID},
+		},
+		bson.D{
+			{"$set", bson.D{
+				{"lastCheckedAt", lastCheckedAt},
+				{"nextCheckAt", nextCheckAt},
+			}},
+		},
+	)
+}
+
+// GetMonitoringURLByHeartbeatToken gets the heartbeat monitor the token belongs to.
+func (datastore *Datastore) GetMonitoringURLByHeartbeatToken(heartbeatToken string) MonitorURL {
+	dbClient := datastore.Client
+	collection := dbClient.Database(datastore.DatabaseName).Collection(MonitorURLCollection)
+
+	monitorURL := MonitorURL{}
+	collection.FindOne(
+		context.Background(),
+		bson.D{
+			{"heartbeatToken", heartbeatToken},
+		},
+	).Decode(&monitorURL)
+
+	return monitorURL
+}
+
+// UpdateHeartbeatPing stores when the job last pinged & when the monitor should be checked next.
+func (datastore *Datastore) UpdateHeartbeatPing(monitoringURLID string, lastPingAt, nextCheckAt time.Time) {
+	dbClient := datastore.Client
+	collection := dbClient.Database(datastore.DatabaseName).Collection(MonitorURLCollection)
+
+	collection.FindOneAndUpdate(
+		context.Background(),
+		bson.D{
+			{"_id", monitoringURLID},
+		},
+		bson.D{
+			{"$set", bson.D{
+				{"lastPingAt", lastPingAt},
+				{"nextCheckAt", nextCheckAt},
+			}},
+		},
+	)
+}
+
+// UpdateHeartbeatStart stores when the job last reported its start.
+func (datastore *Datastore) UpdateHeartbeatStart(monitoringURLID string, lastStartAt time.Time) {
+	dbClient := datastore.Client
+	collection := dbClient.Database(datastore.DatabaseName).Collection(MonitorURLCollection)
+
+	collection.FindOneAndUpdate(
+		context.Background(),
+		bson.D{
+			{"_id", monitoringURLID},
+		},
+		bson.D{
+			{"$set", bson.D{
+				{"lastStartAt", lastStartAt},
+			}},
+		},
+	)
+}
+
+// UpdateCertificateNotification stores the certificate expiry & the last threshold a warning was sent for.
+func (datastore *Datastore) UpdateCertificateNotification(monitoringURLID string, expiresAt time.Time, notifiedThreshold int32) {
+	dbClient := datastore.Client
+	collection := dbClient.Database(datastore.DatabaseName).Collection(MonitorURLCollection)
+
+	collection.FindOneAndUpdate(
+		context.Background(),
+		bson.D{
+			{"_id", monitoringURLID},
+		},
 		bson.D{
 			{"$set", bson.D{
-				{"name", monitorURLForm.Name},
-				{"protocol", monitorURLForm.Protocol},
-				{"frequency", monitorURLForm.Frequency},
-				{"unit", monitorURLForm.Unit},
+				{"certificateExpiresAt", expiresAt},
+				{"certificateNotifiedThreshold", notifiedThreshold},
 			}},
 		},
 	)
@@ -441,29 +836,24 @@ func (datastore *Datastore) SearchMonitoringURL(userID, searchText string) []Mon
 // AddMonitorDetail add monitor url detail to the db.
 // Status UP/DOWN, statusCode is the http response code
 func (datastore *Datastore) AddMonitorDetail(monitorURL MonitorURL, statusCode, status, time string, responseTime float64) MonitorResult {
-	dbClient := datastore.Client
-	collection := dbClient.Database(datastore.DatabaseName).Collection(MonitorURLCollection)
-
-	objectID := GenerateObjectID()
-
 	result := MonitorResult{
-		ID:                objectID.Hex(),
-		MonitorURLID:      monitorURL.ID,
 		Status:            status,
 		StatusDescription: statusCode,
 		ResponseTime:      responseTime,
 		Time:              time,
 	}
 
-	// Update status in monitorURL
-	monitorURL.Status = status
-	collection.FindOneAndReplace(
-		context.Background(),
-		bson.D{
-			{"_id", monitorURL.ID},
-		},
-		monitorURL,
-	)
+	datastore.UpdateMonitoringURLStatus(monitorURL.ID, status, 0, status)
+	return datastore.AddMonitorResult(monitorURL, result)
+}
+
+// AddMonitorResult adds the result of a check to the db.
+func (datastore *Datastore) AddMonitorResult(monitorURL MonitorURL, result MonitorResult) MonitorResult {
+	dbClient := datastore.Client
+
+	objectID := GenerateObjectID()
+	result.ID = objectID.Hex()
+	result.MonitorURLID = monitorURL.ID
 
 	monitorResultCollection := dbClient.Database(datastore.DatabaseName).Collection(MonitorResultCollection)
 	monitorResultCollection.InsertOne(
@@ -474,27 +864,155 @@ func (datastore *Datastore) AddMonitorDetail(monitorURL MonitorURL, statusCode,
 	return result
 }
 
-// GetMonitoringURLStats gets the stats for given monitorURLID
-func (datastore *Datastore) GetMonitoringURLStats(monitorURLID string) []MonitorResult {
+// UpdateMonitoringURLFlapping sets whether the monitor url is flapping.
+func (datastore *Datastore) UpdateMonitoringURLFlapping(monitoringURLID string, flapping bool) {
 	dbClient := datastore.Client
-	collection := dbClient.Database(datastore.DatabaseName).Collection(MonitorResultCollection)
+	collection := dbClient.Database(datastore.DatabaseName).Collection(MonitorURLCollection)
 
-	count, _ := collection.Count(
+	collection.FindOneAndUpdate(
 		context.Background(),
 		bson.D{
-			{"monitorURLID", monitorURLID},
+			{"_id", monitoringURLID},
+		},
+		bson.D{
+			{"$set", bson.D{
+				{"flapping", flapping},
+			}},
 		},
 	)
+}
 
-	cursor, _ := collection.Find(
+// UpdateMonitoringURLAcceptedValues sets the dns answers the monitor url is compared with.
+func (datastore *Datastore) UpdateMonitoringURLAcceptedValues(monitoringURLID string, values []string) {
+	dbClient := datastore.Client
+	collection := dbClient.Database(datastore.DatabaseName).Collection(MonitorURLCollection)
+
+	collection.FindOneAndUpdate(
 		context.Background(),
 		bson.D{
-			{"monitorURLID", monitorURLID},
+			{"_id", monitoringURLID},
+		},
+		bson.D{
+			{"$set", bson.D{
+				{"acceptedValues", values},
+			}},
 		},
 	)
+}
+
+// UpdateMonitoringURLStatus updates the status of the monitor url. Only the status is set
+// so that the fields updated by the scheduler & the user in the meantime are kept.
+func (datastore *Datastore) UpdateMonitoringURLStatus(monitoringURLID, status string, pendingChecks int32, statusDetail string) {
+	dbClient := datastore.Client
+	collection := dbClient.Database(datastore.DatabaseName).Collection(MonitorURLCollection)
+
+	collection.FindOneAndUpdate(
+		context.Background(),
+		bson.D{
+			{"_id", monitoringURLID},
+		},
+		bson.D{
+			{"$set", bson.D{
+				{"status", status},
+				{"pendingChecks", pendingChecks},
+				{"statusDetail", statusDetail},
+			}},
+		},
+	)
+}
+
+// UpdateMonitoringURLDownSince stores when the outage of the monitoring url started, zero once it is back UP.
+// The reminders start over.
+func (datastore *Datastore) UpdateMonitoringURLDownSince(monitoringURLID string, downSince time.Time) {
+	dbClient := datastore.Client
+	collection := dbClient.Database(datastore.DatabaseName).Collection(MonitorURLCollection)
+
+	collection.FindOneAndUpdate(
+		context.Background(),
+		bson.D{
+			{"_id", monitoringURLID},
+		},
+		bson.D{
+			{"$set", bson.D{
+				{"downSince", downSince},
+				{"remindersSent", bson.D{}},
+			}},
+		},
+	)
+}
+
+// GetDownMonitoringURLS gets the running monitoring urls which are DOWN.
+func (datastore *Datastore) GetDownMonitoringURLS() []MonitorURL {
+	dbClient := datastore.Client
+	collection := dbClient.Database(datastore.DatabaseName).Collection(MonitorURLCollection)
+
+	cursor, err := collection.Find(
+		context.Background(),
+		bson.D{
+			{"status", utils.StatusDown},
+			{"monitoringStatus", MonitoringStatusRunning},
+			{"downSince", bson.D{{"$gt", time.Time{}}}},
+		},
+	)
+	if err != nil {
+		log.Warnf("Unable to get down monitoring urls: %s", err)
+		return []MonitorURL{}
+	}
+
+	monitorURLS := []MonitorURL{}
+	for cursor.Next(context.Background()) {
+		monitorURL := MonitorURL{}
+		err := cursor.Decode(&monitorURL)
+		if err != nil {
+			log.Warnf("Unable to decode monitoring url: %s", err)
+			continue
+		}
+
+		monitorURLS = append(monitorURLS, monitorURL)
+	}
+
+	return monitorURLS
+}
+
+// SetMonitoringURLReminderSent stores the number of reminders sent through the integration.
+func (datastore *Datastore) SetMonitoringURLReminderSent(monitoringURLID, integrationID string, remindersSent int32) {
+	dbClient := datastore.Client
+	collection := dbClient.Database(datastore.DatabaseName).Collection(MonitorURLCollection)
+
+	collection.FindOneAndUpdate(
+		context.Background(),
+		bson.D{
+			{"_id", monitoringURLID},
+		},
+		bson.D{
+			{"$set", bson.D{
+				{"remindersSent." + integrationID, remindersSent},
+			}},
+		},
+	)
+}
+
+// GetMonitoringURLStats gets the stats for given monitorURLID
+func (datastore *Datastore) GetMonitoringURLStats(monitorURLID string) []MonitorResult {
+	dbClient := datastore.Client
+	collection := dbClient.Database(datastore.DatabaseName).Collection(MonitorResultCollection)
+
+	count, _ := collection.Count(
+		context.Background(),
+		bson.D{
+			{"monitorURLID", monitorURLID},
+		},
+	)
+
+	cursor, _ := collection.Find(
+		context.Background(),
+		bson.D{
+			{"monitorURLID", monitorURLID},
+		},
+	)
+
+	monitorResults := make([]MonitorResult, count)
 
-	monitorResults := make([]MonitorResult, count)
-
 	i := 0
 	for cursor.Next(context.Background()) {
 		monitorResult := MonitorResult{}
@@ -522,9 +1040,9 @@ func (datastore *Datastore) GetMonitoringURLStatsInInterval(monitorURLID string,
 	currentTime := now.UTC().String()
 
 	var fromTime string
-	if unit == "hour" {
+	if unit == utils.HOUR {
 		fromTime = now.Add(-(time.Duration(value) * time.Hour)).UTC().String()
-	} else if unit == "day" {
+	} else if unit == utils.DAY {
 		fromTime = now.Add(-(time.Duration(value) * 24 * time.Hour)).UTC().String()
 	} else if unit == "week" {
 		fromTime = now.Add(-(time.Duration(value) * 24 * 7 * time.Hour)).UTC().String()
@@ -638,28 +1156,65 @@ func (datastore *Datastore) GetResetPassword(uid, code string) ResetPassword {
 	return resetPassword
 }
 
-// AddIntegration adds an integration to db
+// AddIntegration adds an integration to db. The form is expected to be validated, see NewIntegration.
 func (datastore *Datastore) AddIntegration(integrationForm forms.IntegrationForm) Integration {
 	dbClient := datastore.Client
 	collection := dbClient.Database(datastore.DatabaseName).Collection(IntegrationCollection)
 
+	integration, _ := NewIntegration(integrationForm)
 	collection.InsertOne(
 		context.Background(),
-		integrationForm,
+		integration,
 	)
 
-	integration := Integration{
-		ID:           integrationForm.ID,
-		UserID:       integrationForm.UserID,
-		Email:        integrationForm.Email,
-		Type:         integrationForm.Type,
-		WebhookURL:   integrationForm.WebhookURL,
-		PDRoutingKey: integrationForm.PDRoutingKey,
-		PDAction:     integrationForm.PDAction,
-		PDSeverity:   integrationForm.PDSeverity,
+	return integration
+}
+
+// MigrateIntegrations moves the config of the integrations stored with flat fields to their config.
+// Integrations which are not valid for their type are left as they are.
+func (datastore *Datastore) MigrateIntegrations() {
+	dbClient := datastore.Client
+	collection := dbClient.Database(datastore.DatabaseName).Collection(IntegrationCollection)
+
+	cursor, err := collection.Find(
+		context.Background(),
+		bson.D{
+			{"config", bson.D{{"$exists", false}}},
+		},
+	)
+	if err != nil {
+		log.Warnf("Unable to find the integrations to migrate: %s", err)
+		return
 	}
 
-	return integration
+	for cursor.Next(context.Background()) {
+		integrationForm := forms.IntegrationForm{}
+		err := cursor.Decode(&integrationForm)
+		if err != nil {
+			log.Warnf("Unable to decode the integration to migrate: %s", err)
+			continue
+		}
+
+		integration, validationMessage := NewIntegration(integrationForm)
+		if validationMessage != "" {
+			log.Warnf("Integration %s not migrated: %s", integrationForm.ID, validationMessage)
+			continue
+		}
+
+		_, err = collection.ReplaceOne(
+			context.Background(),
+			bson.D{
+				{"_id", integration.ID},
+			},
+			integration,
+		)
+		if err != nil {
+			log.Warnf("Unable to migrate integration %s: %s", integration.ID, err)
+			continue
+		}
+
+		log.Infof("Integration %s migrated", integration.ID)
+	}
 }
 
 // GetIntegrations gets all integrations.
@@ -761,3 +1316,564 @@ func (datastore *Datastore) DeleteIntegration(userID string, integrationID strin
 		},
 	)
 }
+
+// AddNotification adds a notification to the outbox.
+func (datastore *Datastore) AddNotification(notification Notification) Notification {
+	dbClient := datastore.Client
+	collection := dbClient.Database(datastore.DatabaseName).Collection(NotificationCollection)
+
+	collection.InsertOne(
+		context.Background(),
+		notification,
+	)
+
+	return notification
+}
+
+// GetDueNotifications gets the pending notifications whose next attempt is due, oldest first.
+func (datastore *Datastore) GetDueNotifications(now time.Time, limit int64) []Notification {
+	dbClient := datastore.Client
+	collection := dbClient.Database(datastore.DatabaseName).Collection(NotificationCollection)
+
+	findOptions := options.Find()
+	findOptions.Sort = bson.D{
+		{"nextAttemptAt", 1},
+	}
+	findOptions.Limit = &limit
+
+	cursor, err := collection.Find(
+		context.Background(),
+		bson.D{
+			{"status", NotificationStatusPending},
+			{"nextAttemptAt", bson.D{{"$lte", now}}},
+		},
+		findOptions,
+	)
+	if err != nil {
+		log.Warnf("Unable to get due notifications: %s", err)
+		return nil
+	}
+
+	return decodeNotifications(cursor)
+}
+
+// ClaimNotification postpones the next attempt of a due notification to claimUntil, so
+// that no other dispatcher delivers it meanwhile. Returns false if it was already claimed.
+func (datastore *Datastore) ClaimNotification(notificationID string, now, claimUntil time.Time) bool {
+	dbClient := datastore.Client
+	collection := dbClient.Database(datastore.DatabaseName).Collection(NotificationCollection)
+
+	notification := Notification{}
+	err := collection.FindOneAndUpdate(
+		context.Background(),
+		bson.D{
+			{"_id", notificationID},
+			{"status", NotificationStatusPending},
+			{"nextAttemptAt", bson.D{{"$lte", now}}},
+		},
+		bson.D{
+			{"$set", bson.D{
+				{"nextAttemptAt", claimUntil},
+			}},
+		},
+	).Decode(&notification)
+
+	return err == nil
+}
+
+// AddNotificationAttempt records a delivery attempt & the resulting status of the notification.
+func (datastore *Datastore) AddNotificationAttempt(notificationID string, attempt DeliveryAttempt, status string, nextAttemptAt time.Time) {
+	dbClient := datastore.Client
+	collection := dbClient.Database(datastore.DatabaseName).Collection(NotificationCollection)
+
+	collection.FindOneAndUpdate(
+		context.Background(),
+		bson.D{
+			{"_id", notificationID},
+		},
+		bson.D{
+			{"$push", bson.D{
+				{"attempts", attempt},
+			}},
+			{"$set", bson.D{
+				{"status", status},
+				{"nextAttemptAt", nextAttemptAt},
+				{"updatedAt", time.Now()},
+			}},
+		},
+	)
+}
+
+// GetNotificationsByIntegrationID gets the latest notifications sent through an integration of the user.
+func (datastore *Datastore) GetNotificationsByIntegrationID(userID, integrationID string, limit int64) []Notification {
+	return datastore.getLatestNotifications(
+		bson.D{
+			{"userID", userID},
+			{"integrationID", integrationID},
+		},
+		limit,
+	)
+}
+
+// GetNotificationsByMonitorURLID gets the latest notifications sent for a monitor url of the user.
+func (datastore *Datastore) GetNotificationsByMonitorURLID(userID, monitorURLID string, limit int64) []Notification {
+	return datastore.getLatestNotifications(
+		bson.D{
+			{"userID", userID},
+			{"monitorURLID", monitorURLID},
+		},
+		limit,
+	)
+}
+
+func (datastore *Datastore) getLatestNotifications(filter bson.D, limit int64) []Notification {
+	dbClient := datastore.Client
+	collection := dbClient.Database(datastore.DatabaseName).Collection(NotificationCollection)
+
+	findOptions := options.Find()
+	findOptions.Sort = bson.D{
+		{"createdAt", -1},
+	}
+	findOptions.Limit = &limit
+
+	cursor, err := collection.Find(
+		context.Background(),
+		filter,
+		findOptions,
+	)
+	if err != nil {
+		log.Warnf("Unable to get notifications: %s", err)
+		return []Notification{}
+	}
+
+	return decodeNotifications(cursor)
+}
+
+func decodeNotifications(cursor mongo.Cursor) []Notification {
+	notifications := []Notification{}
+	for cursor.Next(context.Background()) {
+		notification := Notification{}
+		err := cursor.Decode(&notification)
+		if err != nil {
+			log.Warnf("Unable to decode notification: %s", err)
+			continue
+		}
+
+		notifications = append(notifications, notification)
+	}
+
+	return notifications
+}
+
+// AddEscalationPolicy adds an escalation policy to db.
+func (datastore *Datastore) AddEscalationPolicy(escalationPolicyForm forms.EscalationPolicyForm) EscalationPolicy {
+	dbClient := datastore.Client
+	collection := dbClient.Database(datastore.DatabaseName).Collection(EscalationPolicyCollection)
+
+	steps := make([]EscalationStep, len(escalationPolicyForm.Steps))
+	for i, stepForm := range escalationPolicyForm.Steps {
+		steps[i] = EscalationStep{
+			IntegrationIDs: stepForm.IntegrationIDs,
+			Delay:          stepForm.Delay,
+		}
+	}
+
+	escalationPolicy := EscalationPolicy{
+		ID:        escalationPolicyForm.ID,
+		UserID:    escalationPolicyForm.UserID,
+		Name:      escalationPolicyForm.Name,
+		Steps:     steps,
+		CreatedAt: time.Now(),
+	}
+
+	collection.InsertOne(
+		context.Background(),
+		escalationPolicy,
+	)
+
+	return escalationPolicy
+}
+
+// GetEscalationPoliciesByUserID gets all escalation policies added by an user.
+func (datastore *Datastore) GetEscalationPoliciesByUserID(userID string) []EscalationPolicy {
+	dbClient := datastore.Client
+	collection := dbClient.Database(datastore.DatabaseName).Collection(EscalationPolicyCollection)
+
+	cursor, err := collection.Find(
+		context.Background(),
+		bson.D{
+			{"userID", userID},
+		},
+	)
+	if err != nil {
+		log.Warnf("Unable to get escalation policies: %s", err)
+		return []EscalationPolicy{}
+	}
+
+	escalationPolicies := []EscalationPolicy{}
+	for cursor.Next(context.Background()) {
+		escalationPolicy := EscalationPolicy{}
+		err := cursor.Decode(&escalationPolicy)
+		if err != nil {
+			log.Warnf("Unable to decode escalation policy: %s", err)
+			continue
+		}
+
+		escalationPolicies = append(escalationPolicies, escalationPolicy)
+	}
+
+	return escalationPolicies
+}
+
+// GetEscalationPolicyByUserID gets a specific escalation policy added by an user.
+func (datastore *Datastore) GetEscalationPolicyByUserID(userID, escalationPolicyID string) EscalationPolicy {
+	dbClient := datastore.Client
+	collection := dbClient.Database(datastore.DatabaseName).Collection(EscalationPolicyCollection)
+
+	escalationPolicy := EscalationPolicy{}
+
+	collection.FindOne(
+		context.Background(),
+		bson.D{
+			{"userID", userID},
+			{"_id", escalationPolicyID},
+		},
+	).Decode(&escalationPolicy)
+
+	return escalationPolicy
+}
+
+// DeleteEscalationPolicy deletes an escalation policy and removes it from the monitoring urls.
+// Open incidents stop escalating at their current step.
+func (datastore *Datastore) DeleteEscalationPolicy(userID, escalationPolicyID string) {
+	dbClient := datastore.Client
+	database := dbClient.Database(datastore.DatabaseName)
+
+	database.Collection(EscalationPolicyCollection).FindOneAndDelete(
+		context.Background(),
+		bson.D{
+			{"userID", userID},
+			{"_id", escalationPolicyID},
+		},
+	)
+
+	database.Collection(MonitorURLCollection).UpdateMany(
+		context.Background(),
+		bson.D{
+			{"userID", userID},
+			{"escalationPolicyID", escalationPolicyID},
+		},
+		bson.D{
+			{"$set", bson.D{
+				{"escalationPolicyID", ""},
+			}},
+		},
+	)
+}
+
+// AddIncident adds an incident to db.
+func (datastore *Datastore) AddIncident(incident Incident) Incident {
+	dbClient := datastore.Client
+	collection := dbClient.Database(datastore.DatabaseName).Collection(IncidentCollection)
+
+	collection.InsertOne(
+		context.Background(),
+		incident,
+	)
+
+	return incident
+}
+
+// GetOpenIncidentByMonitorURLID gets the triggered or acknowledged incident of a monitor url.
+func (datastore *Datastore) GetOpenIncidentByMonitorURLID(monitorURLID string) Incident {
+	dbClient := datastore.Client
+	collection := dbClient.Database(datastore.DatabaseName).Collection(IncidentCollection)
+
+	incident := Incident{}
+
+	collection.FindOne(
+		context.Background(),
+		bson.D{
+			{"monitorURLID", monitorURLID},
+			{"status", bson.D{{"$in", bson.A{IncidentStatusTriggered, IncidentStatusAcknowledged}}}},
+		},
+	).Decode(&incident)
+
+	return incident
+}
+
+// GetDueIncidents gets the triggered incidents due for their next escalation.
+func (datastore *Datastore) GetDueIncidents(now time.Time, limit int64) []Incident {
+	dbClient := datastore.Client
+	collection := dbClient.Database(datastore.DatabaseName).Collection(IncidentCollection)
+
+	findOptions := options.Find()
+	findOptions.Sort = bson.D{
+		{"nextEscalationAt", 1},
+	}
+	findOptions.Limit = &limit
+
+	cursor, err := collection.Find(
+		context.Background(),
+		bson.D{
+			{"status", IncidentStatusTriggered},
+			{"nextEscalationAt", bson.D{{"$gt", time.Time{}}, {"$lte", now}}},
+		},
+		findOptions,
+	)
+	if err != nil {
+		log.Warnf("Unable to get due incidents: %s", err)
+		return nil
+	}
+
+	return decodeIncidents(cursor)
+}
+
+// EscalateIncident moves a triggered incident from a step to the next one and records the
+// notified integrations. Returns false if the incident was escalated, acknowledged or
+// resolved meanwhile.
+func (datastore *Datastore) EscalateIncident(incidentID string, fromStep, toStep int32, integrationIDs []string, nextEscalationAt time.Time) bool {
+	dbClient := datastore.Client
+	collection := dbClient.Database(datastore.DatabaseName).Collection(IncidentCollection)
+
+	incident := Incident{}
+	err := collection.FindOneAndUpdate(
+		context.Background(),
+		bson.D{
+			{"_id", incidentID},
+			{"status", IncidentStatusTriggered},
+			{"step", fromStep},
+		},
+		bson.D{
+			{"$addToSet", bson.D{
+				{"notifiedIntegrationIDs", bson.D{{"$each", integrationIDs}}},
+			}},
+			{"$set", bson.D{
+				{"step", toStep},
+				{"nextEscalationAt", nextEscalationAt},
+				{"updatedAt", time.Now()},
+			}},
+		},
+	).Decode(&incident)
+
+	return err == nil
+}
+
+// AcknowledgeIncident stops the escalation of a triggered incident of the user.
+// Returns false if the incident is not triggered.
+func (datastore *Datastore) AcknowledgeIncident(userID, incidentID string, acknowledgedAt time.Time) bool {
+	dbClient := datastore.Client
+	collection := dbClient.Database(datastore.DatabaseName).Collection(IncidentCollection)
+
+	incident := Incident{}
+	err := collection.FindOneAndUpdate(
+		context.Background(),
+		bson.D{
+			{"_id", incidentID},
+			{"userID", userID},
+			{"status", IncidentStatusTriggered},
+		},
+		bson.D{
+			{"$set", bson.D{
+				{"status", IncidentStatusAcknowledged},
+				{"acknowledgedAt", acknowledgedAt},
+				{"acknowledgedBy", userID},
+				{"nextEscalationAt", time.Time{}},
+				{"updatedAt", time.Now()},
+			}},
+		},
+	).Decode(&incident)
+
+	return err == nil
+}
+
+// ResolveIncident resolves an open incident.
+func (datastore *Datastore) ResolveIncident(incidentID string, resolvedAt time.Time) {
+	dbClient := datastore.Client
+	collection := dbClient.Database(datastore.DatabaseName).Collection(IncidentCollection)
+
+	collection.FindOneAndUpdate(
+		context.Background(),
+		bson.D{
+			{"_id", incidentID},
+			{"status", bson.D{{"$in", bson.A{IncidentStatusTriggered, IncidentStatusAcknowledged}}}},
+		},
+		bson.D{
+			{"$set", bson.D{
+				{"status", IncidentStatusResolved},
+				{"resolvedAt", resolvedAt},
+				{"nextEscalationAt", time.Time{}},
+				{"updatedAt", time.Now()},
+			}},
+		},
+	)
+}
+
+// GetIncidentByUserID gets a specific incident of an user.
+func (datastore *Datastore) GetIncidentByUserID(userID, incidentID string) Incident {
+	dbClient := datastore.Client
+	collection := dbClient.Database(datastore.DatabaseName).Collection(IncidentCollection)
+
+	incident := Incident{}
+
+	collection.FindOne(
+		context.Background(),
+		bson.D{
+			{"userID", userID},
+			{"_id", incidentID},
+		},
+	).Decode(&incident)
+
+	return incident
+}
+
+// GetIncidentsByUserID gets the latest incidents of an user.
+func (datastore *Datastore) GetIncidentsByUserID(userID string, limit int64) []Incident {
+	dbClient := datastore.Client
+	collection := dbClient.Database(datastore.DatabaseName).Collection(IncidentCollection)
+
+	findOptions := options.Find()
+	findOptions.Sort = bson.D{
+		{"createdAt", -1},
+	}
+	findOptions.Limit = &limit
+
+	cursor, err := collection.Find(
+		context.Background(),
+		bson.D{
+			{"userID", userID},
+		},
+		findOptions,
+	)
+	if err != nil {
+		log.Warnf("Unable to get incidents: %s", err)
+		return []Incident{}
+	}
+
+	return decodeIncidents(cursor)
+}
+
+func decodeIncidents(cursor mongo.Cursor) []Incident {
+	incidents := []Incident{}
+	for cursor.Next(context.Background()) {
+		incident := Incident{}
+		err := cursor.Decode(&incident)
+		if err != nil {
+			log.Warnf("Unable to decode incident: %s", err)
+			continue
+		}
+
+		incidents = append(incidents, incident)
+	}
+
+	return incidents
+}
+
+// AddMaintenanceWindow adds a maintenance window to db.
+func (datastore *Datastore) AddMaintenanceWindow(maintenanceWindowForm forms.MaintenanceWindowForm) MaintenanceWindow {
+	dbClient := datastore.Client
+	collection := dbClient.Database(datastore.DatabaseName).Collection(MaintenanceWindowCollection)
+
+	maintenanceWindow := MaintenanceWindow{
+		ID:            maintenanceWindowForm.ID,
+		UserID:        maintenanceWindowForm.UserID,
+		Name:          maintenanceWindowForm.Name,
+		MonitorURLIDs: maintenanceWindowForm.MonitorURLIDs,
+		Tags:          maintenanceWindowForm.Tags,
+		Action:        maintenanceWindowForm.Action,
+		StartAt:       maintenanceWindowForm.StartAt,
+		EndAt:         maintenanceWindowForm.EndAt,
+		Recurrence:    maintenanceWindowForm.Recurrence,
+		Duration:      maintenanceWindowForm.Duration,
+		Timezone:      maintenanceWindowForm.Timezone,
+		CreatedAt:     time.Now(),
+	}
+
+	collection.InsertOne(
+		context.Background(),
+		maintenanceWindow,
+	)
+
+	return maintenanceWindow
+}
+
+// GetMaintenanceWindows gets the maintenance windows which haven't ended yet.
+func (datastore *Datastore) GetMaintenanceWindows(now time.Time) []MaintenanceWindow {
+	return datastore.getMaintenanceWindows(
+		bson.D{
+			{"$or", bson.A{
+				bson.D{{"endAt", bson.D{{"$gt", now}}}},
+				bson.D{{"endAt", time.Time{}}},
+			}},
+		},
+	)
+}
+
+// GetMaintenanceWindowsByUserID gets all maintenance windows added by an user.
+func (datastore *Datastore) GetMaintenanceWindowsByUserID(userID string) []MaintenanceWindow {
+	return datastore.getMaintenanceWindows(
+		bson.D{
+			{"userID", userID},
+		},
+	)
+}
+
+func (datastore *Datastore) getMaintenanceWindows(filter bson.D) []MaintenanceWindow {
+	dbClient := datastore.Client
+	collection := dbClient.Database(datastore.DatabaseName).Collection(MaintenanceWindowCollection)
+
+	cursor, err := collection.Find(
+		context.Background(),
+		filter,
+	)
+	if err != nil {
+		log.Warnf("Unable to get maintenance windows: %s", err)
+		return []MaintenanceWindow{}
+	}
+
+	maintenanceWindows := []MaintenanceWindow{}
+	for cursor.Next(context.Background()) {
+		maintenanceWindow := MaintenanceWindow{}
+		err := cursor.Decode(&maintenanceWindow)
+		if err != nil {
+			log.Warnf("Unable to decode maintenance window: %s", err)
+			continue
+		}
+
+		maintenanceWindows = append(maintenanceWindows, maintenanceWindow)
+	}
+
+	return maintenanceWindows
+}
+
+// GetMaintenanceWindowByUserID gets a specific maintenance window added by an user.
+func (datastore *Datastore) GetMaintenanceWindowByUserID(userID, maintenanceWindowID string) MaintenanceWindow {
+	dbClient := datastore.Client
+	collection := dbClient.Database(datastore.DatabaseName).Collection(MaintenanceWindowCollection)
+
+	maintenanceWindow := MaintenanceWindow{}
+
+	collection.FindOne(
+		context.Background(),
+		bson.D{
+			{"userID", userID},
+			{"_id", maintenanceWindowID},
+		},
+	).Decode(&maintenanceWindow)
+
+	return maintenanceWindow
+}
+
+// DeleteMaintenanceWindow deletes a maintenance window, ending it if it is in progress.
+func (datastore *Datastore) DeleteMaintenanceWindow(userID, maintenanceWindowID string) {
+	dbClient := datastore.Client
+	collection := dbClient.Database(datastore.DatabaseName).Collection(MaintenanceWindowCollection)
+
+	collection.FindOneAndDelete(
+		context.Background(),
+		bson.D{
+			{"userID", userID},
+			{"_id", maintenanceWindowID},
+		},
+	)
+}
@@ -0,0 +1,198 @@
+package db
+
+import (
+	"bytes"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"time"
+
+	"github.com/defraglabs/uptime/internal/utils"
+	"github.com/gofrs/uuid"
+	log "github.com/sirupsen/logrus"
+)
+
+// WebhookEventVersion is the version of the webhook event payload.
+// It is bumped on breaking changes only, new fields can be added within a version.
+const WebhookEventVersion = "1"
+
+// WebhookEvent is the json payload posted by webhook integrations.
+type WebhookEvent struct {
+	Version string `json:"version"`
+
+	// Type of the alert, status or certificate.
+	Type    string         `json:"type"`
+	Summary string         `json:"summary"`
+	Monitor WebhookMonitor `json:"monitor"`
+
+	PreviousStatus    string  `json:"previousStatus"`
+	Status            string  `json:"status"`
+	StatusCode        int     `json:"statusCode"`
+	StatusDescription string  `json:"statusDescription"`
+	ResponseTime      float64 `json:"responseTime"`
+
+	// Downtime in seconds, how long the monitor url has been DOWN or was DOWN for the UP alert.
+	Downtime int64 `json:"downtime"`
+
+	// Timestamp of the alert in RFC 3339 format.
+	Timestamp string `json:"timestamp"`
+}
+
+// WebhookMonitor describes the monitor url in a webhook event.
+type WebhookMonitor struct {
+	ID   string `json:"id"`
+	Name string `json:"name"`
+	Type string `json:"type"`
+	URL  string `json:"url"`
+}
+
+// NewWebhookEvent creates the webhook payload of the alert.
+func NewWebhookEvent(alert Alert) WebhookEvent {
+	return WebhookEvent{
+		Version: WebhookEventVersion,
+		Type:    alert.Type,
+		Summary: alert.Summary(),
+		Monitor: WebhookMonitor{
+			ID:   alert.MonitorURL.ID,
+			Name: alert.MonitorURL.Name,
+			Type: alert.MonitorURL.Type,
+			URL:  alert.MonitorURL.URL,
+		},
+		PreviousStatus:    alert.PreviousStatus,
+		Status:            alert.Status,
+		StatusCode:        alert.Result.StatusCode,
+		StatusDescription: alert.Result.StatusDescription,
+		ResponseTime:      alert.Result.ResponseTime,
+		Downtime:          int64(alert.Downtime() / time.Second),
+		Timestamp:         alert.Time.UTC().Format(time.RFC3339),
+	}
+}
+
+// webhookNotifier posts the alerts as json, or as the rendered body template, to a webhook url.
+type webhookNotifier struct {
+	WebhookURL string `bson:"webhookURL" json:"webhookURL"`
+
+	// Headers are added to the requests.
+	Headers map[string]string `bson:"headers" json:"headers"`
+
+	// BodyTemplate is a go text/template rendered with the webhook event.
+	// The event is posted as json when empty.
+	BodyTemplate string `bson:"bodyTemplate" json:"bodyTemplate"`
+
+	// WebhookSecret signs the body of the requests.
+	WebhookSecret string `bson:"webhookSecret" json:"webhookSecret"`
+}
+
+// Validate validates the webhook config.
+func (notifier *webhookNotifier) Validate() string {
+	if validationMessage := validateWebhookURL(notifier.WebhookURL, WebhookIntegration); validationMessage != "" {
+		return validationMessage
+	}
+
+	for name := range notifier.Headers {
+		if !utils.HeaderNameRegex.MatchString(name) {
+			return fmt.Sprintf("Invalid header name %s", name)
+		}
+	}
+
+	if notifier.BodyTemplate != "" {
+		if _, err := utils.ParseWebhookTemplate(notifier.BodyTemplate); err != nil {
+			return fmt.Sprintf("Invalid body template: %s", err)
+		}
+	}
+
+	return ""
+}
+
+// validateWebhookURL validates the webhook url of the integrations posting to http/https urls.
+func validateWebhookURL(webhookURL string, integrationType string) string {
+	if webhookURL == "" {
+		return fmt.Sprintf("webhookURL is required for %s integration", integrationType)
+	}
+
+	parsedURL, err := url.Parse(webhookURL)
+	if err != nil || (parsedURL.Scheme != "http" && parsedURL.Scheme != "https") || parsedURL.Host == "" {
+		return "webhookURL should be a valid http/https url"
+	}
+
+	return ""
+}
+
+// SetDefaults generates the secret when none is provided.
+// Webhook receivers verify the payloads with the secret, so there always is one.
+func (notifier *webhookNotifier) SetDefaults() {
+	if notifier.WebhookSecret == "" {
+		notifier.WebhookSecret = hex.EncodeToString(uuid.Must(uuid.NewV4()).Bytes())
+	}
+}
+
+// buildWebhookBody renders the body template of the integration with the event,
+// or encodes the event as json when the integration has no template.
+func (notifier *webhookNotifier) buildWebhookBody(event WebhookEvent) ([]byte, error) {
+	if notifier.BodyTemplate == "" {
+		return json.Marshal(event)
+	}
+
+	bodyTemplate, err := utils.ParseWebhookTemplate(notifier.BodyTemplate)
+	if err != nil {
+		return nil, err
+	}
+
+	var body bytes.Buffer
+	err = bodyTemplate.Execute(&body, event)
+
+	return body.Bytes(), err
+}
+
+// Send posts the alert to the webhook url of the integration. The body is
+// signed with the secret of the integration, see utils.SignWebhookBody.
+func (notifier *webhookNotifier) Send(integration Integration, alert Alert) (Delivery, error) {
+	if notifier.WebhookURL == "" {
+		log.Infof("Invalid integration. Webhook url not found for integration %s", integration.ID)
+
+		return Delivery{}, fmt.Errorf("invalid integration. webhook url not found")
+	}
+
+	body, err := notifier.buildWebhookBody(NewWebhookEvent(alert))
+	if err != nil {
+		return Delivery{}, fmt.Errorf("unable to build webhook body: %s", err)
+	}
+
+	req, err := http.NewRequest(http.MethodPost, notifier.WebhookURL, bytes.NewReader(body))
+	if err != nil {
+		return Delivery{}, err
+	}
+
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("User-Agent", "Uptime-Webhook")
+	for name, value := range notifier.Headers {
+		req.Header.Set(name, value)
+	}
+
+	if notifier.WebhookSecret != "" {
+		req.Header.Set(utils.WebhookSignatureHeader, utils.SignWebhookBody(notifier.WebhookSecret, body))
+	}
+
+	resp, err := integrationClient.Do(req)
+	if err != nil {
+		return Delivery{}, fmt.Errorf("webhook send failed: %s", err)
+	}
+	defer resp.Body.Close()
+
+	return readDelivery(resp)
+}
+
+func init() {
+	RegisterNotifier(NotifierType{
+		Name: WebhookIntegration,
+		Fields: []NotifierField{
+			{Name: "webhookURL", Type: FieldTypeURL, Required: true, Description: "Http/https url the alerts are posted to"},
+			{Name: "headers", Type: FieldTypeMap, Secret: true, Description: "Headers added to the requests, their values are masked"},
+			{Name: "bodyTemplate", Type: FieldTypeString, Description: "Go text/template rendered with the event, the event is posted as json when empty"},
+			{Name: "webhookSecret", Type: FieldTypeString, Secret: true, Description: "Secret signing the body in the X-Uptime-Signature header, generated when empty"},
+		},
+		New: func() Notifier { return &webhookNotifier{} },
+	})
+}
@@ -0,0 +1,174 @@
+package db
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/defraglabs/uptime/internal/forms"
+	"github.com/defraglabs/uptime/internal/utils"
+)
+
+// webhookRequest is a request received by the webhook stand-in.
+type webhookRequest struct {
+	Header http.Header
+	Body   []byte
+}
+
+// startWebhookStandIn records the requests posted to it & responds with the status.
+func startWebhookStandIn(requests *[]webhookRequest, status int) *httptest.Server {
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := ioutil.ReadAll(r.Body)
+		*requests = append(*requests, webhookRequest{Header: r.Header, Body: body})
+
+		w.WriteHeader(status)
+	}))
+}
+
+func newTestWebhookIntegration(t *testing.T, config map[string]interface{}) Integration {
+	encodedConfig, _ := json.Marshal(config)
+	integration, validationMessage := NewIntegration(forms.IntegrationForm{
+		ID:     "integration",
+		Type:   WebhookIntegration,
+		Config: encodedConfig,
+	})
+	if validationMessage != "" {
+		t.Fatalf("unexpected validation message %s", validationMessage)
+	}
+
+	return integration
+}
+
+// getWebhookSignature computes the signature the receiver expects.
+func getWebhookSignature(secret string, body []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write(body)
+
+	return "sha256=" + hex.EncodeToString(mac.Sum(nil))
+}
+
+func TestWebhookPostsSignedEvent(t *testing.T) {
+	requests := []webhookRequest{}
+	server := startWebhookStandIn(&requests, http.StatusNoContent)
+	defer server.Close()
+
+	integration := newTestWebhookIntegration(t, map[string]interface{}{
+		"webhookURL":    server.URL,
+		"headers":       map[string]string{"Authorization": "Bearer token", "X-Team": "ops"},
+		"webhookSecret": "secret",
+	})
+
+	monitorURL := MonitorURL{ID: "monitor", Name: "example", URL: "example.com", Type: utils.MonitorTypeHTTP}
+	alert := NewStatusAlert(monitorURL, utils.StatusDown, MonitorResult{Status: utils.StatusUp, StatusCode: 200, ResponseTime: 42})
+
+	delivery, err := integration.Send(alert)
+	if err != nil {
+		t.Fatalf("unable to send the webhook: %s", err)
+	}
+	if delivery.StatusCode != http.StatusNoContent {
+		t.Errorf("expected status 204, got %d", delivery.StatusCode)
+	}
+
+	if len(requests) != 1 {
+		t.Fatalf("expected 1 request, got %d", len(requests))
+	}
+
+	request := requests[0]
+	if signature := request.Header.Get(utils.WebhookSignatureHeader); signature != getWebhookSignature("secret", request.Body) {
+		t.Errorf("expected the body to be signed with the secret, got %s", signature)
+	}
+	if request.Header.Get("Authorization") != "Bearer token" || request.Header.Get("X-Team") != "ops" {
+		t.Errorf("expected the headers of the integration, got %v", request.Header)
+	}
+	if request.Header.Get("Content-Type") != "application/json" || request.Header.Get("User-Agent") != "Uptime-Webhook" {
+		t.Errorf("unexpected content type %s or user agent %s", request.Header.Get("Content-Type"), request.Header.Get("User-Agent"))
+	}
+
+	event := WebhookEvent{}
+	if err := json.Unmarshal(request.Body, &event); err != nil {
+		t.Fatalf("unable to decode the event: %s", err)
+	}
+	if event.Version != "1" {
+		t.Errorf("expected version 1, got %s", event.Version)
+	}
+	if event.Type != AlertTypeStatus || event.PreviousStatus != utils.StatusDown || event.Status != utils.StatusUp {
+		t.Errorf("expected a status event from DOWN to UP, got %s from %s to %s", event.Type, event.PreviousStatus, event.Status)
+	}
+	if event.Monitor.ID != "monitor" || event.Monitor.URL != "example.com" || event.StatusCode != 200 || event.ResponseTime != 42 {
+		t.Errorf("unexpected monitor %+v or result %d in %vms", event.Monitor, event.StatusCode, event.ResponseTime)
+	}
+	if event.Summary != alert.Summary() || event.Timestamp == "" {
+		t.Errorf("unexpected summary %q or timestamp %q", event.Summary, event.Timestamp)
+	}
+}
+
+func TestWebhookRendersBodyTemplate(t *testing.T) {
+	requests := []webhookRequest{}
+	server := startWebhookStandIn(&requests, http.StatusOK)
+	defer server.Close()
+
+	integration := newTestWebhookIntegration(t, map[string]interface{}{
+		"webhookURL":    server.URL,
+		"bodyTemplate":  `{"text": {{json .Summary}}, "status": "{{.PreviousStatus}} to {{.Status}}", "monitor": {{json .Monitor.Name}}}`,
+		"webhookSecret": "secret",
+	})
+
+	monitorURL := MonitorURL{ID: "monitor", Name: "example \"api\"", URL: "example.com"}
+	alert := NewStatusAlert(monitorURL, utils.StatusUp, MonitorResult{Status: utils.StatusDown})
+	if _, err := integration.Send(alert); err != nil {
+		t.Fatalf("unable to send the webhook: %s", err)
+	}
+
+	if len(requests) != 1 {
+		t.Fatalf("expected 1 request, got %d", len(requests))
+	}
+
+	summary, _ := json.Marshal(alert.Summary())
+	expected := `{"text": ` + string(summary) + `, "status": "UP to DOWN", "monitor": "example \"api\""}`
+	if body := string(requests[0].Body); body != expected {
+		t.Errorf("expected %s, got %s", expected, body)
+	}
+	if signature := requests[0].Header.Get(utils.WebhookSignatureHeader); signature != getWebhookSignature("secret", requests[0].Body) {
+		t.Errorf("expected the rendered body to be signed, got %s", signature)
+	}
+}
+
+func TestWebhookSignsWithTheGeneratedSecret(t *testing.T) {
+	requests := []webhookRequest{}
+	server := startWebhookStandIn(&requests, http.StatusOK)
+	defer server.Close()
+
+	integration := newTestWebhookIntegration(t, map[string]interface{}{"webhookURL": server.URL})
+
+	notifier, _ := integration.GetNotifier()
+	secret := notifier.(*webhookNotifier).WebhookSecret
+	if len(secret) != 32 {
+		t.Fatalf("expected a generated secret of 32 characters, got %q", secret)
+	}
+
+	alert := NewStatusAlert(MonitorURL{ID: "monitor", URL: "example.com"}, utils.StatusUp, MonitorResult{Status: utils.StatusDown})
+	integration.Send(alert)
+
+	if len(requests) != 1 || requests[0].Header.Get(utils.WebhookSignatureHeader) != getWebhookSignature(secret, requests[0].Body) {
+		t.Errorf("expected the body to be signed with the generated secret, got %v", requests)
+	}
+}
+
+func TestWebhookRejectedByTheReceiver(t *testing.T) {
+	requests := []webhookRequest{}
+	server := startWebhookStandIn(&requests, http.StatusInternalServerError)
+	defer server.Close()
+
+	integration := newTestWebhookIntegration(t, map[string]interface{}{"webhookURL": server.URL, "webhookSecret": "secret"})
+
+	alert := NewStatusAlert(MonitorURL{ID: "monitor", URL: "example.com"}, utils.StatusUp, MonitorResult{Status: utils.StatusDown})
+	delivery, err := integration.Send(alert)
+	if err == nil || delivery.StatusCode != http.StatusInternalServerError {
+		t.Errorf("expected the delivery to fail with status 500, got %d: %v", delivery.StatusCode, err)
+	}
+}
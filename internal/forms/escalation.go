@@ -0,0 +1,48 @@
+package forms
+
+import (
+	"fmt"
+
+	"github.com/defraglabs/uptime/internal/utils"
+)
+
+// EscalationPolicyForm is used for input data for escalation policies.
+type EscalationPolicyForm struct {
+	ID     string               `bson:"_id" json:"id,omitempty"`
+	UserID string               `bson:"userID" json:"userID,omitempty"`
+	Name   string               `bson:"name" json:"name"`
+	Steps  []EscalationStepForm `bson:"steps" json:"steps"`
+}
+
+// EscalationStepForm is a step of an escalation policy.
+type EscalationStepForm struct {
+	IntegrationIDs []string `bson:"integrationIDs" json:"integrationIDs"`
+
+	// Delay in minutes before the next step is notified if the incident is not acknowledged.
+	// Ignored on the last step.
+	Delay int32 `bson:"delay" json:"delay"`
+}
+
+// Validate escalation policy form
+func (escalationPolicyForm EscalationPolicyForm) Validate() string {
+	if escalationPolicyForm.Name == "" {
+		return "Name is required"
+	} else if len(escalationPolicyForm.Steps) == 0 {
+		return "Escalation policy should have at least one step"
+	} else if len(escalationPolicyForm.Steps) > utils.MaxEscalationSteps {
+		return fmt.Sprintf("Escalation policy can have at most %d steps", utils.MaxEscalationSteps)
+	}
+
+	lastStep := len(escalationPolicyForm.Steps) - 1
+	for i, step := range escalationPolicyForm.Steps {
+		if len(step.IntegrationIDs) == 0 {
+			return fmt.Sprintf("Step %d should have at least one integration", i+1)
+		} else if i < lastStep && step.Delay < 1 {
+			return fmt.Sprintf("Step %d delay should be at least 1 minute", i+1)
+		} else if step.Delay > utils.MaxEscalationDelay {
+			return fmt.Sprintf("Step %d delay should be at most %d minutes", i+1, utils.MaxEscalationDelay)
+		}
+	}
+
+	return ""
+}
@@ -1,6 +1,23 @@
 package forms
 
+import (
+	"fmt"
+
+	"github.com/defraglabs/uptime/internal/utils"
+)
+
 // Form interface
 type Form interface {
 	Validate() string
 }
+
+// validateReminder validates the interval in minutes & the maximum count of the reminders of an outage.
+func validateReminder(reminderInterval, reminderMaxCount int32) string {
+	if reminderInterval < 0 || reminderInterval > utils.MaxReminderInterval {
+		return fmt.Sprintf("Reminder interval should be between 1 and %d minutes", utils.MaxReminderInterval)
+	} else if reminderMaxCount < 0 {
+		return "Reminder max count can't be negative"
+	}
+
+	return ""
+}
@@ -1,38 +1,81 @@
 package forms
 
+import (
+	"encoding/json"
+)
+
 // IntegrationForm struct is used for input data for integrations.
 type IntegrationForm struct {
-	ID         string `bson:"_id" json:"id,omitempty"`
-	UserID     string `bson:"userID" json:"userID,omitempty"`
-	Type       string `bson:"type" json:"type"`
+	ID     string `bson:"_id" json:"id,omitempty"`
+	UserID string `bson:"userID" json:"userID,omitempty"`
+	Type   string `bson:"type" json:"type"`
+
+	// Config of the integration, its fields depend on the type, see GET /integration-types.
+	Config json.RawMessage `bson:"-" json:"config,omitempty"`
+
+	// ReminderInterval & ReminderMaxCount configure the reminders sent while a monitor url is DOWN.
+	// The settings of the monitor url take precedence.
+	ReminderInterval int32 `bson:"reminderInterval" json:"reminderInterval,omitempty"`
+	ReminderMaxCount int32 `bson:"reminderMaxCount" json:"reminderMaxCount,omitempty"`
+
+	// The fields below are deprecated, they are the config when no config is provided.
+	// They also decode the integrations stored before the config was introduced.
 	Email      string `bson:"email" json:"email"`
 	WebhookURL string `bson:"webhookURL" json:"webhookURL"`
 
 	// PDRoutingKey is the routing key generated from PD integration.
 	PDRoutingKey string `bson:"pdRoutingKey" json:"pdRoutingKey,omitempty"`
 
-	// PDAction is the PD event action. Possible values are trigger, acknowledge and resolve.
+	// PDAction is no longer used, the action follows the alert: trigger when DOWN & resolve when UP.
 	PDAction string `bson:"pdAction" json:"pdAction,omitempty" structs:"pdAction"`
 
-	// PDSeverity can be one of info, warning, error, critical, or unknown.
+	// PDSeverity can be one of info, warning, error or critical.
+	// The severity of the monitor url takes precedence.
 	PDSeverity string `bson:"pdSeverity" json:"pdSeverity,omitempty"`
+
+	// Headers, BodyTemplate & WebhookSecret configure webhook integrations.
+	// A secret is generated when none is provided.
+	Headers       map[string]string `bson:"headers" json:"headers,omitempty"`
+	BodyTemplate  string            `bson:"bodyTemplate" json:"bodyTemplate,omitempty"`
+	WebhookSecret string            `bson:"webhookSecret" json:"webhookSecret,omitempty"`
 }
 
-// Validate integration form
+// legacyIntegrationConfig is the config made of the deprecated fields of the form.
+type legacyIntegrationConfig struct {
+	Email         string            `json:"email,omitempty"`
+	WebhookURL    string            `json:"webhookURL,omitempty"`
+	PDRoutingKey  string            `json:"pdRoutingKey,omitempty"`
+	PDSeverity    string            `json:"pdSeverity,omitempty"`
+	Headers       map[string]string `json:"headers,omitempty"`
+	BodyTemplate  string            `json:"bodyTemplate,omitempty"`
+	WebhookSecret string            `json:"webhookSecret,omitempty"`
+}
+
+// Validate integration form. The config is validated by the integration type.
 func (integrationForm IntegrationForm) Validate() string {
 	if integrationForm.Type == "" {
 		return "integration type is required"
-	} else if integrationForm.Type == "slack" && integrationForm.WebhookURL == "" {
-		return "webhookURL is required for slack integration"
-	} else if integrationForm.Type == "email" && integrationForm.Email == "" {
-		return "email id is required for mail integration"
-	} else if integrationForm.Type == "pagerduty" && (integrationForm.PDRoutingKey == "" || integrationForm.PDAction == "" || integrationForm.PDSeverity == "") {
-		return "pagerduty routing key, action & severity are required"
 	}
 
-	if integrationForm.Type != "slack" && integrationForm.Type != "email" && integrationForm.Type != "pagerduty" {
-		return "invalid integration type. Should be slack/email/pagerduty"
+	return validateReminder(integrationForm.ReminderInterval, integrationForm.ReminderMaxCount)
+}
+
+// GetConfig returns the json config of the integration, made of the deprecated fields
+// when no config is provided.
+func (integrationForm IntegrationForm) GetConfig() json.RawMessage {
+	if len(integrationForm.Config) > 0 && string(integrationForm.Config) != "null" {
+		return integrationForm.Config
 	}
 
-	return ""
+	config, _ := json.Marshal(legacyIntegrationConfig{
+		Email:         integrationForm.Email,
+		WebhookURL:    integrationForm.WebhookURL,
+		PDRoutingKey:  integrationForm.PDRoutingKey,
+		PDSeverity:    integrationForm.PDSeverity,
+		Headers:       integrationForm.Headers,
+		BodyTemplate:  integrationForm.BodyTemplate,
+		WebhookSecret: integrationForm.WebhookSecret,
+	})
+
+	return config
 }
@@ -0,0 +1,61 @@
+package forms
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/defraglabs/uptime/internal/utils"
+)
+
+// MaintenanceWindowForm is used for input data for maintenance windows.
+type MaintenanceWindowForm struct {
+	ID     string `bson:"_id" json:"id,omitempty"`
+	UserID string `bson:"userID" json:"userID,omitempty"`
+	Name   string `bson:"name" json:"name"`
+
+	MonitorURLIDs []string `bson:"monitorURLIDs" json:"monitorURLIDs"`
+	Tags          []string `bson:"tags" json:"tags"`
+
+	// Action is skipChecks or suppressAlerts.
+	Action string `bson:"action" json:"action"`
+
+	StartAt time.Time `bson:"startAt" json:"startAt"`
+	EndAt   time.Time `bson:"endAt" json:"endAt"`
+
+	// Recurrence is a cron expression or an RRULE, every occurrence lasts Duration minutes.
+	Recurrence string `bson:"recurrence" json:"recurrence,omitempty"`
+	Duration   int32  `bson:"duration" json:"duration,omitempty"`
+	Timezone   string `bson:"timezone" json:"timezone,omitempty"`
+}
+
+// Validate maintenance window form
+func (maintenanceWindowForm MaintenanceWindowForm) Validate() string {
+	location, err := time.LoadLocation(maintenanceWindowForm.Timezone)
+
+	if maintenanceWindowForm.Name == "" {
+		return "Name is required"
+	} else if !utils.StringInList(maintenanceWindowForm.Action, utils.MaintenanceActions) {
+		return "Invalid action. Should be skipChecks/suppressAlerts"
+	} else if err != nil {
+		return fmt.Sprintf("Invalid timezone %s", maintenanceWindowForm.Timezone)
+	} else if !maintenanceWindowForm.EndAt.IsZero() && !maintenanceWindowForm.EndAt.After(maintenanceWindowForm.StartAt) {
+		return "endAt should be after startAt"
+	}
+
+	if maintenanceWindowForm.Recurrence == "" {
+		if maintenanceWindowForm.StartAt.IsZero() || maintenanceWindowForm.EndAt.IsZero() {
+			return "startAt & endAt are required for one-off windows"
+		}
+
+		return ""
+	}
+
+	_, err = utils.ParseRecurrence(maintenanceWindowForm.Recurrence, maintenanceWindowForm.StartAt.In(location))
+	if err != nil {
+		return fmt.Sprintf("Invalid recurrence: %s", err)
+	} else if maintenanceWindowForm.Duration < 1 || maintenanceWindowForm.Duration > utils.MaxMaintenanceDuration {
+		return fmt.Sprintf("duration should be between 1 and %d minutes", utils.MaxMaintenanceDuration)
+	}
+
+	return ""
+}
@@ -2,7 +2,11 @@ package forms
 
 import (
 	"fmt"
+	"net"
 	"net/http"
+	"regexp"
+	"strconv"
+	"time"
 
 	"github.com/defraglabs/uptime/internal/utils"
 )
@@ -17,15 +21,154 @@ type MonitorURLForm struct {
 	URL              string `bson:"url" json:"url"`
 	Frequency        int32  `bson:"frequency" json:"frequency"`
 	Unit             string `bson:"unit" json:"unit"`
+
+	// Type of the monitor, http (default), tcp, dns or heartbeat.
+	// For tcp the url is `host:port`, for dns it is the name to resolve.
+	// Heartbeat monitors have no url.
+	Type string `bson:"type" json:"type,omitempty"`
+
+	// Payload sent once connected & the banner expected in reply, for tcp monitors.
+	Payload        string `bson:"payload" json:"payload,omitempty"`
+	ExpectedBanner string `bson:"expectedBanner" json:"expectedBanner,omitempty"`
+
+	// Record type to resolve, the resolver (`host:port`) to query & the expected answers, for dns monitors.
+	// Without expected answers the monitor goes DOWN when the answers differ from the first ones.
+	// ResetAcceptedValues accepts the next answers instead, on update.
+	RecordType          string   `bson:"recordType" json:"recordType,omitempty"`
+	Resolver            string   `bson:"resolver" json:"resolver,omitempty"`
+	ExpectedValues      []string `bson:"expectedValues" json:"expectedValues,omitempty"`
+	ResetAcceptedValues bool     `bson:"-" json:"resetAcceptedValues,omitempty"`
+
+	// Expected schedule of the pings for heartbeat monitors, when frequency & unit are not used.
+	// GracePeriod is how late, in seconds, a ping can be before the monitor goes DOWN.
+	CronExpression string `bson:"cronExpression" json:"cronExpression,omitempty"`
+	GracePeriod    int32  `bson:"gracePeriod" json:"gracePeriod,omitempty"`
+	HeartbeatToken string `bson:"heartbeatToken" json:"-"`
+
+	// Request options. Method defaults to GET & timeout (in seconds) to CHECK_TIMEOUT_SECONDS.
+	Method      string            `bson:"method" json:"method,omitempty"`
+	Headers     map[string]string `bson:"headers" json:"headers,omitempty"`
+	Body        string            `bson:"body" json:"body,omitempty"`
+	ContentType string            `bson:"contentType" json:"contentType,omitempty"`
+	Timeout     int32             `bson:"timeout" json:"timeout,omitempty"`
+	UserAgent   string            `bson:"userAgent" json:"userAgent,omitempty"`
+
+	Assertions []AssertionForm `bson:"assertions" json:"assertions"`
+
+	// CertificateExpiryThresholds are the days before the certificate expiry at which a warning is sent.
+	CertificateExpiryThresholds []int32 `bson:"certificateExpiryThresholds" json:"certificateExpiryThresholds,omitempty"`
+
+	// Tags group the monitor urls, e.g. to put them under maintenance together.
+	Tags []string `bson:"tags" json:"tags"`
+
+	// IntegrationIDs are the integrations the alerts are sent through. Empty means all of them.
+	IntegrationIDs []string `bson:"integrationIDs" json:"integrationIDs"`
+
+	// EscalationPolicyID escalates the DOWN alerts until they are acknowledged.
+	// ClearEscalationPolicy removes the policy of the url, on update.
+	EscalationPolicyID    string `bson:"escalationPolicyID" json:"escalationPolicyID,omitempty"`
+	ClearEscalationPolicy bool   `bson:"-" json:"clearEscalationPolicy,omitempty"`
+
+	// Severity of the failures, critical, error, warning or info.
+	Severity string `bson:"severity" json:"severity,omitempty"`
+
+	// ReminderInterval is the interval in minutes between the reminders sent while DOWN.
+	// ReminderMaxCount caps the number of reminders, 0 means no limit.
+	// ClearReminder replaces both with the given ones on update, clearing the omitted ones.
+	ReminderInterval int32 `bson:"reminderInterval" json:"reminderInterval,omitempty"`
+	ReminderMaxCount int32 `bson:"reminderMaxCount" json:"reminderMaxCount,omitempty"`
+	ClearReminder    bool  `bson:"-" json:"clearReminder,omitempty"`
+
+	// FailureThreshold & RecoveryThreshold are the consecutive failed & successful checks
+	// before the monitor goes DOWN & back UP. Both default to 1.
+	FailureThreshold  int32 `bson:"failureThreshold" json:"failureThreshold,omitempty"`
+	RecoveryThreshold int32 `bson:"recoveryThreshold" json:"recoveryThreshold,omitempty"`
+}
+
+// AssertionForm is a check run against the response of a monitor url.
+type AssertionForm struct {
+	Type       string `bson:"type" json:"type"`
+	Property   string `bson:"property" json:"property,omitempty"`
+	Comparison string `bson:"comparison" json:"comparison,omitempty"`
+	Value      string `bson:"value" json:"value"`
 }
 
-func validateURL(url string) bool {
-	resp, err := http.Get(url)
+// Validate assertion form
+func (assertionForm AssertionForm) Validate() string {
+	if !utils.StringInList(assertionForm.Type, utils.AssertionTypes) {
+		return fmt.Sprintf("invalid assertion type %s", assertionForm.Type)
+	}
+
+	switch assertionForm.Type {
+	case utils.AssertionContains, utils.AssertionNotContains:
+		if assertionForm.Value == "" {
+			return "value is required for contains assertions"
+		}
+	case utils.AssertionRegex:
+		if _, err := regexp.Compile(assertionForm.Value); err != nil {
+			return "invalid regex in assertion"
+		}
+	case utils.AssertionJSONPath, utils.AssertionHeader:
+		if assertionForm.Property == "" {
+			return "property is required for jsonPath & header assertions"
+		} else if !utils.StringInList(assertionForm.Comparison, utils.Comparisons) {
+			return fmt.Sprintf("invalid assertion comparison %s", assertionForm.Comparison)
+		}
+	case utils.AssertionResponseTime:
+		if value, err := strconv.Atoi(assertionForm.Value); err != nil || value <= 0 {
+			return "value should be the response time limit in milliseconds"
+		}
+	}
 
+	return ""
+}
+
+// validateTCPAddress checks that a connection can be opened to the address.
+func validateTCPAddress(address string) bool {
+	conn, err := net.DialTimeout("tcp", address, 5*time.Second)
+	if err != nil {
+		return false
+	}
+	conn.Close()
+
+	return true
+}
+
+// safeMethods are the methods sent when validating an url. The other methods might change
+// the state of the service, so the url is only parsed.
+var safeMethods = []string{"", http.MethodGet, http.MethodHead, http.MethodOptions}
+
+// validateURL checks the url responds with a 2xx status code within the timeout of the monitor url.
+func validateURL(monitorURLForm MonitorURLForm) bool {
+	url := fmt.Sprintf("%s://%s", monitorURLForm.Protocol, monitorURLForm.URL)
+	req, err := utils.NewMonitorRequest(
+		monitorURLForm.Method,
+		url,
+		monitorURLForm.Headers,
+		monitorURLForm.Body,
+		monitorURLForm.ContentType,
+		monitorURLForm.UserAgent,
+	)
 	if err != nil {
 		return false
 	}
 
+	if !utils.StringInList(monitorURLForm.Method, safeMethods) {
+		return true
+	}
+
+	timeout := utils.GetCheckTimeout()
+	if monitorURLForm.Timeout > 0 {
+		timeout = time.Duration(monitorURLForm.Timeout) * time.Second
+	}
+
+	client := &http.Client{Timeout: timeout}
+	resp, err := client.Do(req)
+	if err != nil {
+		return false
+	}
+	resp.Body.Close()
+
 	// Status codes should be in 2xx range.
 	if resp.StatusCode >= 300 {
 		return false
@@ -36,9 +179,16 @@ func validateURL(url string) bool {
 
 // Validate monitor url form input
 func (monitorURLForm MonitorURLForm) Validate() string {
+	isHTTP := monitorURLForm.Type == "" || monitorURLForm.Type == utils.MonitorTypeHTTP
+	isHeartbeat := monitorURLForm.Type == utils.MonitorTypeHeartbeat
+
 	if monitorURLForm.Name == "" {
 		return "Name is required"
-	} else if monitorURLForm.Protocol == "" {
+	} else if monitorURLForm.Type != "" && !utils.StringInList(monitorURLForm.Type, utils.MonitorTypes) {
+		return "Invalid type"
+	} else if isHeartbeat {
+		return monitorURLForm.validateHeartbeat()
+	} else if monitorURLForm.Protocol == "" && isHTTP {
 		return "Protocol is required"
 	} else if monitorURLForm.URL == "" {
 		return "URL is required"
@@ -46,6 +196,10 @@ func (monitorURLForm MonitorURLForm) Validate() string {
 		return "Frequency is required"
 	} else if monitorURLForm.Unit == "" {
 		return "Unit is required"
+	} else if monitorURLForm.Body != "" && monitorURLForm.Method == "" {
+		return "Method is required along with body"
+	} else if monitorURLForm.RecordType == "" && monitorURLForm.Type == utils.MonitorTypeDNS {
+		return "Record type is required for dns monitors"
 	}
 
 	// Validate if the provided frequency and units are valid.
@@ -57,10 +211,134 @@ func (monitorURLForm MonitorURLForm) Validate() string {
 		return "Invalid unit"
 	}
 
-	url := fmt.Sprintf("%s://%s", monitorURLForm.Protocol, monitorURLForm.URL)
-	if !validateURL(url) {
-		return "Make sure you've provided the correct url & protocol."
+	if len(monitorURLForm.CertificateExpiryThresholds) > 0 && (!isHTTP || monitorURLForm.Protocol != "https") {
+		return "Certificate expiry thresholds are only supported for https urls"
+	}
+
+	if !isHTTP {
+		for _, assertionForm := range monitorURLForm.Assertions {
+			if assertionForm.Type != utils.AssertionResponseTime {
+				return fmt.Sprintf("Only responseTime assertions are supported for %s monitors", monitorURLForm.Type)
+			}
+		}
 	}
+
+	validationMessage := monitorURLForm.ValidateUpdate()
+	if validationMessage != "" {
+		return validationMessage
+	}
+
+	switch monitorURLForm.Type {
+	case utils.MonitorTypeTCP:
+		if _, _, err := net.SplitHostPort(monitorURLForm.URL); err != nil {
+			return "URL should be of the format host:port for tcp monitors"
+		} else if !validateTCPAddress(monitorURLForm.URL) {
+			return "Make sure the host & port are reachable."
+		}
+	case utils.MonitorTypeDNS:
+		// The record might legitimately not exist yet, so it isn't resolved here.
+	default:
+		if !validateURL(monitorURLForm) {
+			return "Make sure you've provided the correct url & protocol."
+		}
+	}
+	return ""
+}
+
+// validateHeartbeat validates the form of a heartbeat monitor. The pings are expected
+// either every frequency & unit or on the cron expression.
+func (monitorURLForm MonitorURLForm) validateHeartbeat() string {
+	if monitorURLForm.CronExpression == "" {
+		if monitorURLForm.Frequency == 0 {
+			return "Frequency or cron expression is required"
+		} else if monitorURLForm.Unit == "" {
+			return "Unit is required"
+		}
+
+		if val, ok := utils.MonitoringConfig[monitorURLForm.Unit]; ok {
+			if !utils.FrequencyInMonitoringConfig(monitorURLForm.Frequency, val) {
+				return "Invalid frequency"
+			}
+		} else {
+			return "Invalid unit"
+		}
+	}
+
+	for _, assertionForm := range monitorURLForm.Assertions {
+		if assertionForm.Type != utils.AssertionResponseTime {
+			return "Only responseTime assertions are supported for heartbeat monitors"
+		}
+	}
+
+	return monitorURLForm.ValidateUpdate()
+}
+
+// ValidateUpdate validates the fields which can be changed on update.
+func (monitorURLForm MonitorURLForm) ValidateUpdate() string {
+	if monitorURLForm.Method != "" && !utils.StringInList(monitorURLForm.Method, utils.HTTPMethods) {
+		return "Invalid method"
+	} else if monitorURLForm.Timeout < 0 || monitorURLForm.Timeout > utils.MaxCheckTimeout {
+		return fmt.Sprintf("Timeout should be between 1 and %d seconds", utils.MaxCheckTimeout)
+	} else if monitorURLForm.Body != "" && (monitorURLForm.Method == http.MethodGet || monitorURLForm.Method == http.MethodHead) {
+		return "Body is not allowed for GET & HEAD requests"
+	}
+
+	if monitorURLForm.RecordType != "" && !utils.StringInList(monitorURLForm.RecordType, utils.DNSRecordTypes) {
+		return "Invalid record type. Should be A/AAAA/CNAME/MX/TXT"
+	} else if monitorURLForm.Resolver != "" {
+		if _, _, err := net.SplitHostPort(monitorURLForm.Resolver); err != nil {
+			return "Resolver should be of the format host:port"
+		}
+	}
+
+	if monitorURLForm.CronExpression != "" {
+		if _, err := utils.ParseCron(monitorURLForm.CronExpression); err != nil {
+			return fmt.Sprintf("Invalid cron expression: %s", err)
+		}
+	}
+
+	if monitorURLForm.GracePeriod < 0 {
+		return "Grace period can't be negative"
+	}
+
+	if monitorURLForm.Severity != "" && !utils.StringInList(monitorURLForm.Severity, utils.Severities) {
+		return "Invalid severity. Should be critical/error/warning/info"
+	}
+
+	if monitorURLForm.FailureThreshold < 0 || monitorURLForm.FailureThreshold > utils.MaxConfirmationThreshold {
+		return fmt.Sprintf("Failure threshold should be between 1 and %d", utils.MaxConfirmationThreshold)
+	} else if monitorURLForm.RecoveryThreshold < 0 || monitorURLForm.RecoveryThreshold > utils.MaxConfirmationThreshold {
+		return fmt.Sprintf("Recovery threshold should be between 1 and %d", utils.MaxConfirmationThreshold)
+	}
+
+	if validationMessage := validateReminder(monitorURLForm.ReminderInterval, monitorURLForm.ReminderMaxCount); validationMessage != "" {
+		return validationMessage
+	}
+
+	for _, threshold := range monitorURLForm.CertificateExpiryThresholds {
+		if threshold <= 0 || threshold > 365 {
+			return "Certificate expiry thresholds should be between 1 and 365 days"
+		}
+	}
+
+	for name := range monitorURLForm.Headers {
+		if !utils.HeaderNameRegex.MatchString(name) {
+			return fmt.Sprintf("Invalid header name %s", name)
+		}
+	}
+
+	return validateAssertions(monitorURLForm.Assertions)
+}
+
+// validateAssertions validates every assertion & returns the first error.
+func validateAssertions(assertions []AssertionForm) string {
+	for _, assertionForm := range assertions {
+		validationMessage := assertionForm.Validate()
+		if validationMessage != "" {
+			return validationMessage
+		}
+	}
+
 	return ""
 }
 
@@ -0,0 +1,98 @@
+package tasks
+
+import (
+	"fmt"
+	"net/http"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/defraglabs/uptime/internal/db"
+	"github.com/defraglabs/uptime/internal/utils"
+)
+
+// assertionResponse holds the parts of the response the assertions run against.
+type assertionResponse struct {
+	body         []byte
+	header       http.Header
+	responseTime float64
+}
+
+// evaluateAssertions runs the assertions in order and returns the description
+// of the first one which fails. Returns an empty string if all of them pass.
+func evaluateAssertions(assertions []db.Assertion, response assertionResponse) string {
+	for _, assertion := range assertions {
+		err := evaluateAssertion(assertion, response)
+		if err != nil {
+			return fmt.Sprintf("%s: %s", describeAssertion(assertion), err)
+		}
+	}
+
+	return ""
+}
+
+func evaluateAssertion(assertion db.Assertion, response assertionResponse) error {
+	switch assertion.Type {
+	case utils.AssertionContains:
+		if !strings.Contains(string(response.body), assertion.Value) {
+			return fmt.Errorf("body doesn't contain %q", assertion.Value)
+		}
+	case utils.AssertionNotContains:
+		if strings.Contains(string(response.body), assertion.Value) {
+			return fmt.Errorf("body contains %q", assertion.Value)
+		}
+	case utils.AssertionRegex:
+		matched, err := regexp.Match(assertion.Value, response.body)
+		if err != nil {
+			return err
+		} else if !matched {
+			return fmt.Errorf("body doesn't match")
+		}
+	case utils.AssertionJSONPath:
+		value, err := utils.GetJSONPathValue(response.body, assertion.Property)
+		if err != nil {
+			return err
+		}
+
+		return compare(value, assertion)
+	case utils.AssertionHeader:
+		return compare(response.header.Get(assertion.Property), assertion)
+	case utils.AssertionResponseTime:
+		limit, err := strconv.ParseFloat(assertion.Value, 64)
+		if err != nil {
+			return fmt.Errorf("invalid limit %s", assertion.Value)
+		} else if response.responseTime >= limit {
+			return fmt.Errorf("took %.0fms", response.responseTime)
+		}
+	default:
+		return fmt.Errorf("unknown assertion type")
+	}
+
+	return nil
+}
+
+func compare(value string, assertion db.Assertion) error {
+	ok, err := utils.Compare(value, assertion.Comparison, assertion.Value)
+	if err != nil {
+		return err
+	} else if !ok {
+		return fmt.Errorf("got %q", value)
+	}
+
+	return nil
+}
+
+// describeAssertion returns a human readable form of the assertion.
+// Example:
+//
+//	jsonPath $.healthy equals true
+func describeAssertion(assertion db.Assertion) string {
+	switch assertion.Type {
+	case utils.AssertionJSONPath, utils.AssertionHeader:
+		return fmt.Sprintf("%s %s %s %s", assertion.Type, assertion.Property, assertion.Comparison, assertion.Value)
+	case utils.AssertionResponseTime:
+		return fmt.Sprintf("%s below %sms", assertion.Type, assertion.Value)
+	}
+
+	return fmt.Sprintf("%s %s", assertion.Type, assertion.Value)
+}
@@ -0,0 +1,56 @@
+package tasks
+
+import (
+	"net/http"
+	"testing"
+
+	"github.com/defraglabs/uptime/internal/db"
+	"github.com/defraglabs/uptime/internal/utils"
+)
+
+func TestEvaluateAssertions(t *testing.T) {
+	response := assertionResponse{
+		body:         []byte(`{"status": "ok", "queue": {"size": 12}}`),
+		header:       http.Header{"Content-Type": []string{"application/json"}},
+		responseTime: 180,
+	}
+
+	cases := []struct {
+		assertion db.Assertion
+		failed    bool
+	}{
+		{db.Assertion{Type: utils.AssertionContains, Value: `"ok"`}, false},
+		{db.Assertion{Type: utils.AssertionContains, Value: "error"}, true},
+		{db.Assertion{Type: utils.AssertionNotContains, Value: "error"}, false},
+		{db.Assertion{Type: utils.AssertionNotContains, Value: "queue"}, true},
+		{db.Assertion{Type: utils.AssertionRegex, Value: `"size": \d+`}, false},
+		{db.Assertion{Type: utils.AssertionRegex, Value: `^ok$`}, true},
+		{db.Assertion{Type: utils.AssertionJSONPath, Property: "$.status", Comparison: utils.ComparisonEquals, Value: "ok"}, false},
+		{db.Assertion{Type: utils.AssertionJSONPath, Property: "$.queue.size", Comparison: utils.ComparisonLessThan, Value: "10"}, true},
+		{db.Assertion{Type: utils.AssertionJSONPath, Property: "$.unknown", Comparison: utils.ComparisonEquals, Value: "ok"}, true},
+		{db.Assertion{Type: utils.AssertionHeader, Property: "content-type", Comparison: utils.ComparisonContains, Value: "json"}, false},
+		{db.Assertion{Type: utils.AssertionHeader, Property: "X-Missing", Comparison: utils.ComparisonEquals, Value: "1"}, true},
+		{db.Assertion{Type: utils.AssertionResponseTime, Value: "200"}, false},
+		{db.Assertion{Type: utils.AssertionResponseTime, Value: "180"}, true},
+	}
+
+	for _, c := range cases {
+		failedAssertion := evaluateAssertions([]db.Assertion{c.assertion}, response)
+		if (failedAssertion != "") != c.failed {
+			t.Errorf("%+v: expected failed %v, got %q", c.assertion, c.failed, failedAssertion)
+		}
+	}
+}
+
+func TestEvaluateAssertionsReportsFirstFailure(t *testing.T) {
+	assertions := []db.Assertion{
+		{Type: utils.AssertionContains, Value: "ok"},
+		{Type: utils.AssertionJSONPath, Property: "$.status", Comparison: utils.ComparisonEquals, Value: "down"},
+		{Type: utils.AssertionContains, Value: "missing"},
+	}
+
+	failedAssertion := evaluateAssertions(assertions, assertionResponse{body: []byte(`{"status": "ok"}`)})
+	if failedAssertion != describeAssertion(assertions[1])+`: got "ok"` {
+		t.Errorf("expected the json path assertion to fail, got %q", failedAssertion)
+	}
+}
@@ -0,0 +1,161 @@
+package tasks
+
+import (
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"fmt"
+	"net"
+	"net/http"
+	"time"
+
+	"github.com/defraglabs/uptime/internal/db"
+)
+
+// invalidCertificateThreshold is stored as the notified threshold once an invalid certificate is reported.
+// Being below every threshold, the expiry warnings aren't sent for the certificate anymore.
+const invalidCertificateThreshold = -1
+
+// getRootCAs returns the roots the checks trust, nil for the system roots.
+func getRootCAs() *x509.CertPool {
+	if transport, ok := httpClient.Transport.(*http.Transport); ok && transport.TLSClientConfig != nil {
+		return transport.TLSClientConfig.RootCAs
+	}
+
+	return nil
+}
+
+// fetchCertificates does a handshake with the host without verifying its certificates & describes
+// the chain it presents. Used when the request failed, as the chain is only known once verified.
+func fetchCertificates(ctx context.Context, hostname, port string) ([]db.Certificate, error) {
+	if port == "" {
+		port = "443"
+	}
+
+	var dialer net.Dialer
+	conn, err := dialer.DialContext(ctx, "tcp", net.JoinHostPort(hostname, port))
+	if err != nil {
+		return nil, err
+	}
+	defer conn.Close()
+
+	if deadline, ok := ctx.Deadline(); ok {
+		conn.SetDeadline(deadline)
+	}
+
+	// The chain is verified by getCertificates.
+	tlsConn := tls.Client(conn, &tls.Config{ServerName: hostname, InsecureSkipVerify: true})
+	if err := tlsConn.Handshake(); err != nil {
+		return nil, err
+	}
+
+	return getCertificates(tlsConn.ConnectionState().PeerCertificates, hostname), nil
+}
+
+// getCertificates describes the chain presented by the server, leaf first. The chain is
+// verified against the roots of the checks & the hostname.
+func getCertificates(peerCertificates []*x509.Certificate, hostname string) []db.Certificate {
+	certificates := make([]db.Certificate, len(peerCertificates))
+
+	for i, peerCertificate := range peerCertificates {
+		certificates[i] = db.Certificate{
+			Subject:   peerCertificate.Subject.String(),
+			Issuer:    peerCertificate.Issuer.String(),
+			NotBefore: peerCertificate.NotBefore,
+			NotAfter:  peerCertificate.NotAfter,
+			DNSNames:  peerCertificate.DNSNames,
+		}
+	}
+
+	if len(peerCertificates) > 0 {
+		leaf := peerCertificates[0]
+		certificates[0].HostnameMatch = leaf.VerifyHostname(hostname) == nil
+
+		intermediates := x509.NewCertPool()
+		for _, peerCertificate := range peerCertificates[1:] {
+			intermediates.AddCert(peerCertificate)
+		}
+
+		_, err := leaf.Verify(x509.VerifyOptions{
+			DNSName:       hostname,
+			Roots:         getRootCAs(),
+			Intermediates: intermediates,
+		})
+		if err != nil {
+			certificates[0].VerificationError = err.Error()
+		}
+	}
+
+	return certificates
+}
+
+// getCrossedThreshold returns the smallest threshold the days left are within, 0 if none.
+func getCrossedThreshold(thresholds []int32, daysLeft int32) int32 {
+	var crossed int32
+	for _, threshold := range thresholds {
+		if daysLeft <= threshold && (crossed == 0 || threshold < crossed) {
+			crossed = threshold
+		}
+	}
+
+	return crossed
+}
+
+// getCertificateMessage returns the message of the certificate alert to send, if any, along with
+// the threshold notified so far. An invalid certificate is reported once, an expiring one once
+// per crossed threshold. A renewed certificate starts over from the largest threshold.
+func getCertificateMessage(monitorURL db.MonitorURL, leaf db.Certificate, t time.Time) (string, int32) {
+	daysLeft := int32(leaf.NotAfter.Sub(t).Hours() / 24)
+
+	notifiedThreshold := monitorURL.CertificateNotifiedThreshold
+	if !leaf.NotAfter.Equal(monitorURL.CertificateExpiresAt) {
+		notifiedThreshold = 0
+	}
+
+	if leaf.VerificationError != "" {
+		if notifiedThreshold == invalidCertificateThreshold {
+			return "", notifiedThreshold
+		}
+
+		return fmt.Sprintf("Certificate of site %s is invalid: %s", monitorURL.URL, leaf.VerificationError), invalidCertificateThreshold
+	}
+
+	crossedThreshold := getCrossedThreshold(monitorURL.CertificateExpiryThresholds, daysLeft)
+	if crossedThreshold != 0 && (notifiedThreshold == 0 || crossedThreshold < notifiedThreshold) {
+		message := fmt.Sprintf(
+			"Certificate of site %s expires in %d days (%s)",
+			monitorURL.URL, daysLeft, leaf.NotAfter.UTC().Format("2006-01-02"),
+		)
+
+		return message, crossedThreshold
+	}
+
+	return "", notifiedThreshold
+}
+
+// checkCertificateExpiry sends the certificate alerts of the monitor urls with expiry thresholds.
+func checkCertificateExpiry(monitorURL db.MonitorURL, result db.MonitorResult) {
+	if len(monitorURL.CertificateExpiryThresholds) == 0 || len(result.Certificates) == 0 {
+		return
+	}
+
+	leaf := result.Certificates[0]
+	message, notifiedThreshold := getCertificateMessage(monitorURL, leaf, time.Now())
+	if message != "" {
+		alert := db.Alert{
+			Type:       db.AlertTypeCertificate,
+			MonitorURL: monitorURL,
+			Status:     result.Status,
+			Result:     result,
+			Message:    message,
+			Time:       time.Now(),
+		}
+		sendAlertNotification(alert)
+	}
+
+	// Only write when something changed since the last check.
+	if notifiedThreshold != monitorURL.CertificateNotifiedThreshold || !leaf.NotAfter.Equal(monitorURL.CertificateExpiresAt) {
+		datastore := db.New()
+		datastore.UpdateCertificateNotification(monitorURL.ID, leaf.NotAfter, notifiedThreshold)
+	}
+}
@@ -0,0 +1,182 @@
+package tasks
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"io/ioutil"
+	"log"
+	"math/big"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/defraglabs/uptime/internal/db"
+	"github.com/defraglabs/uptime/internal/utils"
+)
+
+// newTestCertificate returns a self signed certificate of the host, valid between the times.
+func newTestCertificate(t *testing.T, host string, notBefore, notAfter time.Time) (tls.Certificate, *x509.Certificate) {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatalf("unable to generate the key: %s", err)
+	}
+
+	template := &x509.Certificate{
+		SerialNumber:          big.NewInt(1),
+		Subject:               pkix.Name{CommonName: host},
+		NotBefore:             notBefore,
+		NotAfter:              notAfter,
+		KeyUsage:              x509.KeyUsageDigitalSignature | x509.KeyUsageCertSign,
+		ExtKeyUsage:           []x509.ExtKeyUsage{x509.ExtKeyUsageServerAuth},
+		BasicConstraintsValid: true,
+		IsCA:                  true,
+	}
+	if ip := net.ParseIP(host); ip != nil {
+		template.IPAddresses = []net.IP{ip}
+	} else {
+		template.DNSNames = []string{host}
+	}
+
+	der, err := x509.CreateCertificate(rand.Reader, template, template, &key.PublicKey, key)
+	if err != nil {
+		t.Fatalf("unable to create the certificate: %s", err)
+	}
+
+	certificate, _ := x509.ParseCertificate(der)
+	return tls.Certificate{Certificate: [][]byte{der}, PrivateKey: key}, certificate
+}
+
+// startTLSServer serves the certificate. The checks trust it as a root, unless untrusted.
+// The returned func stops the server & restores the client of the checks.
+func startTLSServer(t *testing.T, certificate tls.Certificate, root *x509.Certificate) (*httptest.Server, func()) {
+	server := httptest.NewUnstartedServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+	server.TLS = &tls.Config{Certificates: []tls.Certificate{certificate}}
+	// The rejected handshakes are expected.
+	server.Config.ErrorLog = log.New(ioutil.Discard, "", 0)
+	server.StartTLS()
+
+	roots := x509.NewCertPool()
+	if root != nil {
+		roots.AddCert(root)
+	}
+
+	client := httpClient
+	httpClient = &http.Client{Transport: &http.Transport{TLSClientConfig: &tls.Config{RootCAs: roots}}}
+
+	return server, func() {
+		httpClient = client
+		server.Close()
+	}
+}
+
+// checkTLSServer checks the url of the server, e.g. `https://127.0.0.1:port`.
+func checkTLSServer(server *httptest.Server) db.MonitorResult {
+	monitorURL := db.MonitorURL{Protocol: "https", URL: strings.TrimPrefix(server.URL, "https://")}
+
+	return checkHTTP(context.Background(), monitorURL)
+}
+
+func TestCheckRecordsValidCertificate(t *testing.T) {
+	certificate, root := newTestCertificate(t, "127.0.0.1", time.Now().Add(-time.Hour), time.Now().Add(24*time.Hour))
+	server, stop := startTLSServer(t, certificate, root)
+	defer stop()
+
+	result := checkTLSServer(server)
+	if result.Status != utils.StatusUp || len(result.Certificates) != 1 {
+		t.Fatalf("expected UP with the certificate, got %s with %d certificates", result.Status, len(result.Certificates))
+	}
+
+	leaf := result.Certificates[0]
+	if !leaf.HostnameMatch || leaf.VerificationError != "" {
+		t.Errorf("expected a valid certificate, got hostname match %v & %q", leaf.HostnameMatch, leaf.VerificationError)
+	}
+}
+
+func TestCheckRecordsInvalidCertificates(t *testing.T) {
+	now := time.Now()
+	expired, expiredRoot := newTestCertificate(t, "127.0.0.1", now.Add(-48*time.Hour), now.Add(-24*time.Hour))
+	mismatched, mismatchedRoot := newTestCertificate(t, "uptime.example", now.Add(-time.Hour), now.Add(24*time.Hour))
+	selfSigned, _ := newTestCertificate(t, "127.0.0.1", now.Add(-time.Hour), now.Add(24*time.Hour))
+
+	cases := []struct {
+		name          string
+		certificate   tls.Certificate
+		root          *x509.Certificate
+		hostnameMatch bool
+	}{
+		{"expired", expired, expiredRoot, true},
+		{"mismatched", mismatched, mismatchedRoot, false},
+		{"self signed", selfSigned, nil, true},
+	}
+
+	for _, c := range cases {
+		server, stop := startTLSServer(t, c.certificate, c.root)
+		result := checkTLSServer(server)
+		stop()
+
+		if result.Status != utils.StatusDown || result.StatusDescription != "Invalid certificate" {
+			t.Errorf("%s: expected DOWN with an invalid certificate, got %s (%s)", c.name, result.Status, result.StatusDescription)
+		}
+		if len(result.Certificates) != 1 {
+			t.Errorf("%s: expected the certificate to be recorded, got %d certificates", c.name, len(result.Certificates))
+			continue
+		}
+
+		leaf := result.Certificates[0]
+		if leaf.VerificationError == "" || leaf.HostnameMatch != c.hostnameMatch {
+			t.Errorf("%s: expected a verification error & hostname match %v, got %q & %v", c.name, c.hostnameMatch, leaf.VerificationError, leaf.HostnameMatch)
+		}
+	}
+}
+
+func TestGetCertificateMessage(t *testing.T) {
+	now := time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC)
+	notAfter := now.Add(10 * 24 * time.Hour)
+	monitorURL := db.MonitorURL{URL: "example.com", CertificateExpiryThresholds: []int32{30, 14, 7}}
+
+	message, notifiedThreshold := getCertificateMessage(monitorURL, db.Certificate{NotAfter: notAfter}, now)
+	if message != "Certificate of site example.com expires in 10 days (2024-05-11)" || notifiedThreshold != 14 {
+		t.Errorf("expected the 14 days warning, got %q & %d", message, notifiedThreshold)
+	}
+
+	// The threshold was notified for this certificate already.
+	monitorURL.CertificateExpiresAt, monitorURL.CertificateNotifiedThreshold = notAfter, 14
+	if message, notifiedThreshold = getCertificateMessage(monitorURL, db.Certificate{NotAfter: notAfter}, now); message != "" || notifiedThreshold != 14 {
+		t.Errorf("expected no warning, got %q & %d", message, notifiedThreshold)
+	}
+
+	// A renewed certificate starts over.
+	renewed := now.Add(20 * 24 * time.Hour)
+	if message, notifiedThreshold = getCertificateMessage(monitorURL, db.Certificate{NotAfter: renewed}, now); message == "" || notifiedThreshold != 30 {
+		t.Errorf("expected the 30 days warning, got %q & %d", message, notifiedThreshold)
+	}
+
+	invalid := db.Certificate{NotAfter: notAfter, VerificationError: "x509: certificate has expired or is not yet valid"}
+	message, notifiedThreshold = getCertificateMessage(monitorURL, invalid, now)
+	if message != "Certificate of site example.com is invalid: x509: certificate has expired or is not yet valid" || notifiedThreshold != invalidCertificateThreshold {
+		t.Errorf("expected the invalid certificate alert, got %q & %d", message, notifiedThreshold)
+	}
+
+	// The invalid certificate is reported once.
+	monitorURL.CertificateNotifiedThreshold = invalidCertificateThreshold
+	if message, _ = getCertificateMessage(monitorURL, invalid, now); message != "" {
+		t.Errorf("expected no alert, got %q", message)
+	}
+}
+
+func TestGetCrossedThreshold(t *testing.T) {
+	cases := map[int32]int32{40: 0, 30: 30, 20: 30, 14: 14, 3: 7, -2: 7}
+	for daysLeft, expected := range cases {
+		if crossed := getCrossedThreshold([]int32{7, 30, 14}, daysLeft); crossed != expected {
+			t.Errorf("%d days left: expected threshold %d, got %d", daysLeft, expected, crossed)
+		}
+	}
+}
@@ -0,0 +1,124 @@
+package tasks
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"time"
+
+	"github.com/defraglabs/uptime/internal/db"
+	"github.com/defraglabs/uptime/internal/utils"
+	log "github.com/sirupsen/logrus"
+)
+
+// maxBodySize is the maximum number of bytes of the response read for the assertions.
+const maxBodySize = 1 << 20
+
+var httpClient = &http.Client{}
+
+// CheckMonitorURL runs a single check of the monitor url with its own deadline.
+// The result is neither stored nor alerted on.
+func CheckMonitorURL(monitorURL db.MonitorURL) db.MonitorResult {
+	timeout := utils.GetCheckTimeout()
+	if monitorURL.Timeout > 0 {
+		timeout = time.Duration(monitorURL.Timeout) * time.Second
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), timeout)
+	defer cancel()
+
+	switch monitorURL.Type {
+	case utils.MonitorTypeTCP:
+		return checkTCP(ctx, monitorURL)
+	case utils.MonitorTypeDNS:
+		return checkDNS(ctx, monitorURL)
+	case utils.MonitorTypeHeartbeat:
+		return checkHeartbeat(monitorURL)
+	}
+
+	return checkHTTP(ctx, monitorURL)
+}
+
+// checkHTTP pings the monitor url & evaluates its assertions against the response.
+func checkHTTP(ctx context.Context, monitorURL db.MonitorURL) db.MonitorResult {
+	url := fmt.Sprintf("%s://%s", monitorURL.Protocol, monitorURL.URL)
+
+	start := time.Now()
+	result := db.MonitorResult{
+		Time: start.UTC().String(),
+	}
+
+	req, err := utils.NewMonitorRequest(
+		monitorURL.Method,
+		url,
+		monitorURL.Headers,
+		monitorURL.Body,
+		monitorURL.ContentType,
+		monitorURL.UserAgent,
+	)
+	if err != nil {
+		return failedResult(monitorURL, result, "500 Server error", err)
+	}
+
+	resp, err := httpClient.Do(req.WithContext(ctx))
+	if err != nil {
+		result = failedResult(monitorURL, result, "500 Server error", err)
+
+		// The handshake fails on invalid certificates, so the chain is fetched without verifying it.
+		if req.URL.Scheme == "https" {
+			result.Certificates, err = fetchCertificates(ctx, req.URL.Hostname(), req.URL.Port())
+			if err == nil && len(result.Certificates) > 0 && result.Certificates[0].VerificationError != "" {
+				result.StatusDescription = "Invalid certificate"
+			}
+		}
+
+		return result
+	}
+	defer resp.Body.Close()
+
+	result.ResponseTime = float64(time.Since(start).Nanoseconds()) / 1000000
+	result.StatusDescription = resp.Status
+	result.StatusCode = resp.StatusCode
+	result.Status = utils.GetServiceStatus(resp.StatusCode)
+
+	if resp.TLS != nil {
+		result.Certificates = getCertificates(resp.TLS.PeerCertificates, req.URL.Hostname())
+	}
+
+	var body []byte
+	if len(monitorURL.Assertions) > 0 {
+		body, err = ioutil.ReadAll(&io.LimitedReader{R: resp.Body, N: maxBodySize})
+		if err != nil {
+			return failedResult(monitorURL, result, "500 Server error", err)
+		}
+	}
+
+	if result.Status == utils.StatusUp {
+		response := assertionResponse{
+			body:         body,
+			header:       resp.Header,
+			responseTime: result.ResponseTime,
+		}
+
+		failedAssertion := evaluateAssertions(monitorURL.Assertions, response)
+		if failedAssertion != "" {
+			result.Status = utils.StatusDown
+			result.FailedAssertion = failedAssertion
+		}
+	}
+
+	return result
+}
+
+// failedResult marks the result DOWN when the url couldn't be reached.
+func failedResult(monitorURL db.MonitorURL, result db.MonitorResult, description string, err error) db.MonitorResult {
+	log.Warnf("API ping failed for url %s: %s", monitorURL.URL, err)
+
+	result.Status = utils.StatusDown
+	result.ResponseTime = 0.0
+	result.StatusDescription = description
+
+	return result
+}
@@ -0,0 +1,108 @@
+package tasks
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/defraglabs/uptime/internal/db"
+	"github.com/defraglabs/uptime/internal/utils"
+)
+
+// getConfirmationThreshold returns the consecutive checks required to change the status to the given one.
+func getConfirmationThreshold(monitorURL db.MonitorURL, status string) int32 {
+	threshold := monitorURL.RecoveryThreshold
+	if status == utils.StatusDown {
+		threshold = monitorURL.FailureThreshold
+	}
+
+	if threshold < 1 {
+		return 1
+	}
+	return threshold
+}
+
+// confirmStatus applies the failure & recovery thresholds of the monitor url to the status
+// of a check. Returns the confirmed status & the number of consecutive checks disagreeing with it.
+// The first check of a monitor url sets its status right away.
+func confirmStatus(monitorURL db.MonitorURL, checkStatus string) (string, int32) {
+	if monitorURL.Status == "" || monitorURL.Status == checkStatus {
+		return checkStatus, 0
+	}
+
+	pendingChecks := monitorURL.PendingChecks + 1
+	if pendingChecks >= getConfirmationThreshold(monitorURL, checkStatus) {
+		return checkStatus, 0
+	}
+
+	return monitorURL.Status, pendingChecks
+}
+
+// getStatusDetail describes the status along with the pending checks.
+// Example:
+//
+//	UP, 1/3 failures
+func getStatusDetail(monitorURL db.MonitorURL, status string, pendingChecks int32) string {
+	if pendingChecks == 0 {
+		return status
+	}
+
+	pendingStatus, checks := utils.StatusDown, "failures"
+	if status == utils.StatusDown {
+		pendingStatus, checks = utils.StatusUp, "successes"
+	}
+
+	threshold := getConfirmationThreshold(monitorURL, pendingStatus)
+	return fmt.Sprintf("%s, %d/%d %s", status, pendingChecks, threshold, checks)
+}
+
+// getDownSince returns when the outage of the monitor url started, at the first failed check
+// of the streak which took it DOWN, given the status confirmed by the check at t. The start is
+// kept while the failures are pending, and cleared once UP without pending failures.
+func getDownSince(monitorURL db.MonitorURL, checkStatus, status string, pendingChecks int32, t time.Time) time.Time {
+	if status == utils.StatusUp && pendingChecks == 0 {
+		return time.Time{}
+	} else if monitorURL.DownSince.IsZero() && checkStatus == utils.StatusDown {
+		return t
+	}
+
+	return monitorURL.DownSince
+}
+
+// RecordMonitorResult stores the result of a check, updates the status of the monitor url
+// and sends the status alert once a status change is confirmed. While the monitor url is
+// flapping only the flapping & stabilized alerts are sent, though the incident of the outage
+// is resolved once UP. Results recorded during a maintenance window are flagged and leave the
+// status as is.
+func RecordMonitorResult(monitorURL db.MonitorURL, result db.MonitorResult) {
+	datastore := db.New()
+
+	if isUnderMaintenance(datastore, monitorURL, time.Now()) {
+		result.Maintenance = true
+		datastore.AddMonitorResult(monitorURL, result)
+		return
+	}
+
+	previousStatus := monitorURL.Status
+	status, pendingChecks := confirmStatus(monitorURL, result.Status)
+
+	flapping := detectFlapping(monitorURL, result)
+	if flapping != monitorURL.Flapping {
+		sendAlertNotification(db.NewFlappingAlert(monitorURL, flapping, status, result))
+		datastore.UpdateMonitoringURLFlapping(monitorURL.ID, flapping)
+	} else if !flapping && shouldNotify(previousStatus, status) {
+		sendAlertNotification(db.NewStatusAlert(monitorURL, previousStatus, result))
+	}
+
+	// The status alerts are suppressed while flapping, the incident of the outage is resolved all the same.
+	if flapping && previousStatus == utils.StatusDown && status == utils.StatusUp {
+		escalateAlert(db.NewStatusAlert(monitorURL, previousStatus, result))
+	}
+
+	datastore.AddMonitorResult(monitorURL, result)
+	datastore.UpdateMonitoringURLStatus(monitorURL.ID, status, pendingChecks, getStatusDetail(monitorURL, status, pendingChecks))
+
+	// The outage is timed for the reminders & the downtime of the UP alert.
+	if downSince := getDownSince(monitorURL, result.Status, status, pendingChecks, time.Now()); !downSince.Equal(monitorURL.DownSince) {
+		datastore.UpdateMonitoringURLDownSince(monitorURL.ID, downSince)
+	}
+}
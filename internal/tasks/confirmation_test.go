@@ -0,0 +1,151 @@
+package tasks
+
+import (
+	"testing"
+	"time"
+
+	"github.com/defraglabs/uptime/internal/db"
+	"github.com/defraglabs/uptime/internal/utils"
+)
+
+func TestConfirmStatus(t *testing.T) {
+	cases := []struct {
+		name            string
+		monitorURL      db.MonitorURL
+		checkStatus     string
+		expectedStatus  string
+		expectedPending int32
+	}{
+		{
+			"first check",
+			db.MonitorURL{FailureThreshold: 3},
+			utils.StatusDown, utils.StatusDown, 0,
+		},
+		{
+			"same status",
+			db.MonitorURL{Status: utils.StatusUp, PendingChecks: 1, FailureThreshold: 3},
+			utils.StatusUp, utils.StatusUp, 0,
+		},
+		{
+			"first failure",
+			db.MonitorURL{Status: utils.StatusUp, FailureThreshold: 3},
+			utils.StatusDown, utils.StatusUp, 1,
+		},
+		{
+			"second failure",
+			db.MonitorURL{Status: utils.StatusUp, PendingChecks: 1, FailureThreshold: 3},
+			utils.StatusDown, utils.StatusUp, 2,
+		},
+		{
+			"confirmed failure",
+			db.MonitorURL{Status: utils.StatusUp, PendingChecks: 2, FailureThreshold: 3},
+			utils.StatusDown, utils.StatusDown, 0,
+		},
+		{
+			"without thresholds",
+			db.MonitorURL{Status: utils.StatusUp},
+			utils.StatusDown, utils.StatusDown, 0,
+		},
+		{
+			"first success",
+			db.MonitorURL{Status: utils.StatusDown, FailureThreshold: 3, RecoveryThreshold: 2},
+			utils.StatusUp, utils.StatusDown, 1,
+		},
+		{
+			"confirmed recovery",
+			db.MonitorURL{Status: utils.StatusDown, PendingChecks: 1, FailureThreshold: 3, RecoveryThreshold: 2},
+			utils.StatusUp, utils.StatusUp, 0,
+		},
+		{
+			"failure after a success",
+			db.MonitorURL{Status: utils.StatusDown, PendingChecks: 1, RecoveryThreshold: 2},
+			utils.StatusDown, utils.StatusDown, 0,
+		},
+	}
+
+	for _, c := range cases {
+		status, pendingChecks := confirmStatus(c.monitorURL, c.checkStatus)
+		if status != c.expectedStatus || pendingChecks != c.expectedPending {
+			t.Errorf("%s: expected %s with %d pending, got %s with %d pending", c.name, c.expectedStatus, c.expectedPending, status, pendingChecks)
+		}
+	}
+}
+
+func TestGetStatusDetail(t *testing.T) {
+	monitorURL := db.MonitorURL{FailureThreshold: 3, RecoveryThreshold: 2}
+
+	cases := []struct {
+		status        string
+		pendingChecks int32
+		expected      string
+	}{
+		{utils.StatusUp, 0, "UP"},
+		{utils.StatusDown, 0, "DOWN"},
+		{utils.StatusUp, 1, "UP, 1/3 failures"},
+		{utils.StatusUp, 2, "UP, 2/3 failures"},
+		{utils.StatusDown, 1, "DOWN, 1/2 successes"},
+	}
+
+	for _, c := range cases {
+		if detail := getStatusDetail(monitorURL, c.status, c.pendingChecks); detail != c.expected {
+			t.Errorf("expected %q, got %q", c.expected, detail)
+		}
+	}
+
+	// Without thresholds a single check confirms the status.
+	if detail := getStatusDetail(db.MonitorURL{}, utils.StatusUp, 1); detail != "UP, 1/1 failures" {
+		t.Errorf("expected %q, got %q", "UP, 1/1 failures", detail)
+	}
+}
+
+func TestGetDownSinceIsTheFirstFailedCheck(t *testing.T) {
+	start := time.Date(2024, 3, 1, 12, 0, 0, 0, time.UTC)
+	monitorURL := db.MonitorURL{Status: utils.StatusUp, FailureThreshold: 3, RecoveryThreshold: 2}
+
+	// A check every minute, the expected outage start after each of them, as a minute offset, -1 for none.
+	checks := []struct {
+		status   string
+		expected int
+	}{
+		{utils.StatusDown, 0},
+		{utils.StatusDown, 0},
+		{utils.StatusUp, -1},
+		{utils.StatusDown, 3},
+		{utils.StatusDown, 3},
+		{utils.StatusDown, 3},
+		{utils.StatusDown, 3},
+		{utils.StatusUp, 3},
+		{utils.StatusDown, 3},
+		{utils.StatusUp, 3},
+		{utils.StatusUp, -1},
+	}
+
+	for i, check := range checks {
+		status, pendingChecks := confirmStatus(monitorURL, check.status)
+		downSince := getDownSince(monitorURL, check.status, status, pendingChecks, start.Add(time.Duration(i)*time.Minute))
+
+		expected := time.Time{}
+		if check.expected >= 0 {
+			expected = start.Add(time.Duration(check.expected) * time.Minute)
+		}
+		if !downSince.Equal(expected) {
+			t.Errorf("check %d: expected the outage to start at %s, got %s", i, expected, downSince)
+		}
+
+		monitorURL.Status, monitorURL.PendingChecks, monitorURL.DownSince = status, pendingChecks, downSince
+	}
+
+	if monitorURL.Status != utils.StatusUp {
+		t.Errorf("expected the url to be UP after the checks, got %s", monitorURL.Status)
+	}
+}
+
+func TestGetDownSinceOfTheFirstCheck(t *testing.T) {
+	now := time.Now()
+	if downSince := getDownSince(db.MonitorURL{}, utils.StatusDown, utils.StatusDown, 0, now); !downSince.Equal(now) {
+		t.Errorf("expected the outage to start at the first check, got %s", downSince)
+	}
+	if downSince := getDownSince(db.MonitorURL{}, utils.StatusUp, utils.StatusUp, 0, now); !downSince.IsZero() {
+		t.Errorf("expected no outage, got %s", downSince)
+	}
+}
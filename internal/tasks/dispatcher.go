@@ -0,0 +1,147 @@
+package tasks
+
+import (
+	"errors"
+	"time"
+
+	"github.com/defraglabs/uptime/internal/db"
+	"github.com/defraglabs/uptime/internal/utils"
+	log "github.com/sirupsen/logrus"
+)
+
+const (
+	// dispatchInterval is how often the outbox is polled for due notifications.
+	dispatchInterval = time.Second
+
+	// dispatchBatchSize is the maximum number of notifications picked per poll.
+	dispatchBatchSize = 100
+
+	// dispatcherConcurrency is the number of notifications delivered in parallel.
+	dispatcherConcurrency = 10
+
+	// claimDuration hides a notification being delivered from the other dispatchers.
+	// It has to be longer than the timeout of the integrations.
+	claimDuration = time.Minute
+
+	// retryBaseDelay is the delay before the first retry, doubled on every attempt.
+	retryBaseDelay = 30 * time.Second
+
+	// retryMaxDelay caps the delay between two attempts.
+	retryMaxDelay = time.Hour
+)
+
+// errIntegrationNotFound is recorded when the integration was deleted before the delivery.
+var errIntegrationNotFound = errors.New("integration not found")
+
+// sendAlertNotification queues the alert for delivery through the integrations of the monitor url,
+// all the integrations of the user if it has none. Status alerts of monitor urls with an
+// escalation policy are escalated instead.
+func sendAlertNotification(alert db.Alert) {
+	if escalateAlert(alert) {
+		return
+	}
+
+	datastore := db.New()
+	for _, integration := range getMonitorURLIntegrations(datastore, alert.MonitorURL) {
+		datastore.AddNotification(db.NewNotification(integration, alert))
+	}
+}
+
+// getMonitorURLIntegrations returns the integrations the alerts of the monitor url are sent through.
+func getMonitorURLIntegrations(datastore *db.Datastore, monitorURL db.MonitorURL) []db.Integration {
+	integrations := []db.Integration{}
+	for _, integration := range datastore.GetIntegrationsByUserID(monitorURL.UserID) {
+		if len(monitorURL.IntegrationIDs) > 0 && !utils.StringInList(integration.ID, monitorURL.IntegrationIDs) {
+			continue
+		}
+
+		integrations = append(integrations, integration)
+	}
+
+	return integrations
+}
+
+// startDispatcher delivers the notifications of the outbox in the background.
+// Notifications are claimed before being delivered, so running a dispatcher on
+// every replica is safe. Only the leader dispatches to keep the load on one replica.
+func startDispatcher(isLeader func() bool) {
+	jobs := make(chan db.Notification)
+	for i := 0; i < dispatcherConcurrency; i++ {
+		go func() {
+			for notification := range jobs {
+				deliverNotification(notification)
+			}
+		}()
+	}
+
+	ticker := time.NewTicker(dispatchInterval)
+	defer ticker.Stop()
+
+	for t := range ticker.C {
+		if !isLeader() {
+			continue
+		}
+
+		dispatchNotifications(jobs, t)
+	}
+}
+
+// dispatchNotifications hands the due notifications over to the workers.
+func dispatchNotifications(jobs chan<- db.Notification, t time.Time) {
+	datastore := db.New()
+
+	for _, notification := range datastore.GetDueNotifications(t, dispatchBatchSize) {
+		if !datastore.ClaimNotification(notification.ID, t, t.Add(claimDuration)) {
+			continue
+		}
+
+		jobs <- notification
+	}
+}
+
+// deliverNotification makes a delivery attempt and records it. Failed attempts are
+// retried with an exponential backoff until the max attempts is reached.
+func deliverNotification(notification db.Notification) {
+	datastore := db.New()
+	integration := datastore.GetIntegrationByUserID(notification.UserID, notification.IntegrationID)
+
+	var attempt db.DeliveryAttempt
+	var err error
+	if integration.ID == "" {
+		err = errIntegrationNotFound
+		attempt = db.DeliveryAttempt{Time: time.Now(), Error: err.Error()}
+	} else {
+		attempt, err = integration.Attempt(notification.Alert)
+	}
+
+	attempts := len(notification.Attempts) + 1
+	status, nextAttemptAt := getDeliveryStatus(err, attempts, time.Now())
+	if status == db.NotificationStatusFailed {
+		log.Warnf("Giving up on notification %s after %d attempts: %s", notification.ID, attempts, err)
+	}
+
+	datastore.AddNotificationAttempt(notification.ID, attempt, status, nextAttemptAt)
+}
+
+// getDeliveryStatus returns the status of the notification after the given number of attempts,
+// the last one failing with err at t, and when to attempt the delivery again. Deleted integrations
+// & rate limited numbers fail right away.
+func getDeliveryStatus(err error, attempts int, t time.Time) (string, time.Time) {
+	if err == nil {
+		return db.NotificationStatusDelivered, time.Time{}
+	} else if err == errIntegrationNotFound || err == db.ErrPhoneRateLimited || attempts >= utils.GetNotificationMaxAttempts() {
+		return db.NotificationStatusFailed, time.Time{}
+	}
+
+	return db.NotificationStatusPending, t.Add(getRetryDelay(attempts))
+}
+
+// getRetryDelay returns the delay after the given number of failed attempts.
+func getRetryDelay(attempts int) time.Duration {
+	delay := retryBaseDelay << uint(attempts-1)
+	if delay <= 0 || delay > retryMaxDelay {
+		return retryMaxDelay
+	}
+
+	return delay
+}
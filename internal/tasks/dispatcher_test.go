@@ -0,0 +1,76 @@
+package tasks
+
+import (
+	"errors"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/defraglabs/uptime/internal/db"
+)
+
+func TestGetRetryDelay(t *testing.T) {
+	cases := []struct {
+		attempts int
+		expected time.Duration
+	}{
+		{1, 30 * time.Second},
+		{2, time.Minute},
+		{3, 2 * time.Minute},
+		{4, 4 * time.Minute},
+		{7, 32 * time.Minute},
+		{8, time.Hour},
+		{20, time.Hour},
+		{100, time.Hour},
+	}
+
+	for _, c := range cases {
+		if delay := getRetryDelay(c.attempts); delay != c.expected {
+			t.Errorf("after %d attempts: expected %s, got %s", c.attempts, c.expected, delay)
+		}
+	}
+}
+
+func TestGetDeliveryStatus(t *testing.T) {
+	os.Setenv("NOTIFICATION_MAX_ATTEMPTS", "3")
+	defer os.Unsetenv("NOTIFICATION_MAX_ATTEMPTS")
+
+	now := time.Date(2024, 3, 1, 12, 0, 0, 0, time.UTC)
+	errTimeout := errors.New("webhook send failed: timeout")
+
+	cases := []struct {
+		name          string
+		err           error
+		attempts      int
+		status        string
+		nextAttemptAt time.Time
+	}{
+		{"delivered", nil, 1, db.NotificationStatusDelivered, time.Time{}},
+		{"delivered on a retry", nil, 3, db.NotificationStatusDelivered, time.Time{}},
+		{"first failure", errTimeout, 1, db.NotificationStatusPending, now.Add(30 * time.Second)},
+		{"second failure", errTimeout, 2, db.NotificationStatusPending, now.Add(time.Minute)},
+		{"max attempts", errTimeout, 3, db.NotificationStatusFailed, time.Time{}},
+		{"beyond max attempts", errTimeout, 4, db.NotificationStatusFailed, time.Time{}},
+		{"integration not found", errIntegrationNotFound, 1, db.NotificationStatusFailed, time.Time{}},
+		{"phone rate limited", db.ErrPhoneRateLimited, 1, db.NotificationStatusFailed, time.Time{}},
+	}
+
+	for _, c := range cases {
+		status, nextAttemptAt := getDeliveryStatus(c.err, c.attempts, now)
+		if status != c.status || !nextAttemptAt.Equal(c.nextAttemptAt) {
+			t.Errorf("%s: expected %s, next attempt at %s, got %s at %s", c.name, c.status, c.nextAttemptAt, status, nextAttemptAt)
+		}
+	}
+}
+
+func TestGetDeliveryStatusDefaultMaxAttempts(t *testing.T) {
+	os.Unsetenv("NOTIFICATION_MAX_ATTEMPTS")
+
+	errTimeout := errors.New("timeout")
+	if status, _ := getDeliveryStatus(errTimeout, 4, time.Now()); status != db.NotificationStatusPending {
+		t.Errorf("expected the 4th failure to be retried, got %s", status)
+	}
+	if status, _ := getDeliveryStatus(errTimeout, 5, time.Now()); status != db.NotificationStatusFailed {
+		t.Errorf("expected the 5th failure to fail the notification, got %s", status)
+	}
+}
@@ -0,0 +1,163 @@
+package tasks
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/defraglabs/uptime/internal/db"
+	"github.com/defraglabs/uptime/internal/utils"
+)
+
+// checkDNS resolves the record & compares the answers with the expected values, or with the
+// accepted values when no values are expected. The answers of the first successful lookup are accepted.
+func checkDNS(ctx context.Context, monitorURL db.MonitorURL) db.MonitorResult {
+	start := time.Now()
+	result := db.MonitorResult{
+		Time: start.UTC().String(),
+	}
+
+	values, err := resolve(ctx, getResolver(monitorURL.Resolver), monitorURL.RecordType, monitorURL.URL)
+	if err != nil {
+		return failedResult(monitorURL, result, "Lookup failed", err)
+	}
+
+	result.ResponseTime = float64(time.Since(start).Nanoseconds()) / 1000000
+	result.ResolvedValues = values
+	result.StatusDescription = fmt.Sprintf("%d %s records", len(values), monitorURL.RecordType)
+	result.Status = utils.StatusUp
+
+	if len(monitorURL.ExpectedValues) == 0 && len(monitorURL.AcceptedValues) == 0 {
+		datastore := db.New()
+		datastore.UpdateMonitoringURLAcceptedValues(monitorURL.ID, values)
+	} else if failedAssertion := compareDNSValues(monitorURL, values); failedAssertion != "" {
+		result.Status = utils.StatusDown
+		result.FailedAssertion = failedAssertion
+	}
+
+	if result.Status == utils.StatusUp {
+		response := assertionResponse{
+			responseTime: result.ResponseTime,
+		}
+
+		failedAssertion := evaluateAssertions(monitorURL.Assertions, response)
+		if failedAssertion != "" {
+			result.Status = utils.StatusDown
+			result.FailedAssertion = failedAssertion
+		}
+	}
+
+	return result
+}
+
+// getResolver returns a resolver which queries the given address,
+// or the system resolver if the address is empty.
+func getResolver(address string) *net.Resolver {
+	if address == "" {
+		return net.DefaultResolver
+	}
+
+	return &net.Resolver{
+		PreferGo: true,
+		Dial: func(ctx context.Context, network, _ string) (net.Conn, error) {
+			var dialer net.Dialer
+			return dialer.DialContext(ctx, network, address)
+		},
+	}
+}
+
+// resolve looks up the record & returns the sorted answers.
+// Host names are returned without the trailing dot & MX records as `{pref} {host}`.
+func resolve(ctx context.Context, resolver *net.Resolver, recordType, name string) ([]string, error) {
+	var values []string
+
+	switch recordType {
+	case "A", "AAAA":
+		addresses, err := resolver.LookupIPAddr(ctx, name)
+		if err != nil {
+			return nil, err
+		}
+
+		for _, address := range addresses {
+			isIPv4 := address.IP.To4() != nil
+			if isIPv4 == (recordType == "A") {
+				values = append(values, address.IP.String())
+			}
+		}
+	case "CNAME":
+		cname, err := resolver.LookupCNAME(ctx, name)
+		if err != nil {
+			return nil, err
+		}
+
+		values = append(values, strings.TrimSuffix(cname, "."))
+	case "MX":
+		records, err := resolver.LookupMX(ctx, name)
+		if err != nil {
+			return nil, err
+		}
+
+		for _, record := range records {
+			values = append(values, fmt.Sprintf("%d %s", record.Pref, strings.TrimSuffix(record.Host, ".")))
+		}
+	case "TXT":
+		records, err := resolver.LookupTXT(ctx, name)
+		if err != nil {
+			return nil, err
+		}
+
+		values = append(values, records...)
+	default:
+		return nil, fmt.Errorf("unsupported record type %s", recordType)
+	}
+
+	if len(values) == 0 {
+		return nil, fmt.Errorf("no %s records found", recordType)
+	}
+
+	return sortedValues(values), nil
+}
+
+// compareDNSValues describes how the answers differ from the expected values, or from the accepted
+// values when no values are expected. Returns an empty string if they match.
+func compareDNSValues(monitorURL db.MonitorURL, values []string) string {
+	if len(monitorURL.ExpectedValues) > 0 {
+		expectedValues := sortedValues(monitorURL.ExpectedValues)
+		if !equalValues(values, expectedValues) {
+			return fmt.Sprintf("expected %s, got %s", strings.Join(expectedValues, ", "), strings.Join(values, ", "))
+		}
+	} else if len(monitorURL.AcceptedValues) > 0 {
+		acceptedValues := sortedValues(monitorURL.AcceptedValues)
+		if !equalValues(values, acceptedValues) {
+			return fmt.Sprintf("records changed from %s to %s", strings.Join(acceptedValues, ", "), strings.Join(values, ", "))
+		}
+	}
+
+	return ""
+}
+
+func sortedValues(values []string) []string {
+	sorted := make([]string, len(values))
+	copy(sorted, values)
+	sort.Strings(sorted)
+
+	return sorted
+}
+
+// equalValues compares two sorted lists of answers.
+func equalValues(a, b []string) bool {
+	if len(a) != len(b) {
+		return false
+	}
+
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+
+	return true
+}
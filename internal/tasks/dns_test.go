@@ -0,0 +1,181 @@
+package tasks
+
+import (
+	"context"
+	"encoding/binary"
+	"net"
+	"testing"
+
+	"github.com/defraglabs/uptime/internal/db"
+	"github.com/defraglabs/uptime/internal/utils"
+)
+
+// dnsStub answers the A queries with the addresses of the records, and the other queries with no answers.
+type dnsStub struct {
+	conn    net.PacketConn
+	records map[string][]net.IP
+}
+
+// startDNSStub serves the records on a local udp port. The returned func stops it.
+func startDNSStub(t *testing.T, records map[string][]net.IP) (*dnsStub, func()) {
+	conn, err := net.ListenPacket("udp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("unable to listen: %s", err)
+	}
+
+	stub := &dnsStub{conn: conn, records: records}
+	go stub.serve()
+
+	return stub, func() { conn.Close() }
+}
+
+func (stub *dnsStub) serve() {
+	buf := make([]byte, 512)
+	for {
+		n, addr, err := stub.conn.ReadFrom(buf)
+		if err != nil {
+			return
+		}
+
+		if reply := stub.reply(buf[:n]); reply != nil {
+			stub.conn.WriteTo(reply, addr)
+		}
+	}
+}
+
+// reply builds the response to the query, pointing the answers to the name of the question.
+func (stub *dnsStub) reply(query []byte) []byte {
+	if len(query) < 12 {
+		return nil
+	}
+
+	// The question starts after the header, the name being a sequence of labels.
+	offset, labels := 12, []string{}
+	for offset < len(query) && query[offset] != 0 {
+		length := int(query[offset])
+		if offset+1+length > len(query) {
+			return nil
+		}
+		labels = append(labels, string(query[offset+1:offset+1+length]))
+		offset += 1 + length
+	}
+	if offset+5 > len(query) {
+		return nil
+	}
+	questionEnd := offset + 5
+	recordType := binary.BigEndian.Uint16(query[offset+1:])
+
+	name := ""
+	for _, label := range labels {
+		name += label + "."
+	}
+
+	var answers []net.IP
+	if recordType == 1 {
+		answers = stub.records[name]
+	}
+
+	reply := make([]byte, 12, 512)
+	copy(reply, query[:2])
+	binary.BigEndian.PutUint16(reply[2:], 0x8180)
+	binary.BigEndian.PutUint16(reply[4:], 1)
+	binary.BigEndian.PutUint16(reply[6:], uint16(len(answers)))
+	reply = append(reply, query[12:questionEnd]...)
+
+	for _, ip := range answers {
+		answer := make([]byte, 12)
+		binary.BigEndian.PutUint16(answer[0:], 0xc00c)
+		binary.BigEndian.PutUint16(answer[2:], 1)
+		binary.BigEndian.PutUint16(answer[4:], 1)
+		binary.BigEndian.PutUint32(answer[6:], 60)
+		binary.BigEndian.PutUint16(answer[10:], 4)
+		reply = append(reply, answer...)
+		reply = append(reply, ip.To4()...)
+	}
+
+	return reply
+}
+
+func TestResolveQueriesTheResolver(t *testing.T) {
+	stub, stop := startDNSStub(t, map[string][]net.IP{
+		"uptime.example.": {net.ParseIP("192.0.2.2"), net.ParseIP("192.0.2.1")},
+	})
+	defer stop()
+
+	values, err := resolve(context.Background(), getResolver(stub.conn.LocalAddr().String()), "A", "uptime.example")
+	if err != nil {
+		t.Fatalf("unable to resolve: %s", err)
+	}
+
+	if !equalValues(values, []string{"192.0.2.1", "192.0.2.2"}) {
+		t.Errorf("expected the sorted answers, got %v", values)
+	}
+}
+
+func TestCheckDNSComparesWithTheAcceptedValues(t *testing.T) {
+	records := map[string][]net.IP{"uptime.example.": {net.ParseIP("192.0.2.1")}}
+	stub, stop := startDNSStub(t, records)
+	defer stop()
+
+	monitorURL := db.MonitorURL{
+		ID:             "monitor",
+		URL:            "uptime.example",
+		RecordType:     "A",
+		Resolver:       stub.conn.LocalAddr().String(),
+		AcceptedValues: []string{"192.0.2.1"},
+	}
+
+	result := checkDNS(context.Background(), monitorURL)
+	if result.Status != utils.StatusUp {
+		t.Errorf("expected UP with the accepted answers, got %s (%s)", result.Status, result.FailedAssertion)
+	}
+
+	// A change is reported on every check until the answers are accepted, not only on the first one.
+	records["uptime.example."] = []net.IP{net.ParseIP("198.51.100.1")}
+	for i := 0; i < 2; i++ {
+		result = checkDNS(context.Background(), monitorURL)
+		if result.Status != utils.StatusDown || result.FailedAssertion != "records changed from 192.0.2.1 to 198.51.100.1" {
+			t.Errorf("expected DOWN with the changed answers, got %s (%s)", result.Status, result.FailedAssertion)
+		}
+	}
+}
+
+func TestCheckDNSComparesWithTheExpectedValues(t *testing.T) {
+	stub, stop := startDNSStub(t, map[string][]net.IP{"uptime.example.": {net.ParseIP("192.0.2.1")}})
+	defer stop()
+
+	monitorURL := db.MonitorURL{
+		ID:             "monitor",
+		URL:            "uptime.example",
+		RecordType:     "A",
+		Resolver:       stub.conn.LocalAddr().String(),
+		ExpectedValues: []string{"192.0.2.1"},
+		AcceptedValues: []string{"198.51.100.1"},
+	}
+
+	if result := checkDNS(context.Background(), monitorURL); result.Status != utils.StatusUp {
+		t.Errorf("expected UP with the expected answers, got %s (%s)", result.Status, result.FailedAssertion)
+	}
+
+	monitorURL.ExpectedValues = []string{"192.0.2.1", "192.0.2.2"}
+	result := checkDNS(context.Background(), monitorURL)
+	if result.Status != utils.StatusDown || result.FailedAssertion != "expected 192.0.2.1, 192.0.2.2, got 192.0.2.1" {
+		t.Errorf("expected DOWN with the missing answer, got %s (%s)", result.Status, result.FailedAssertion)
+	}
+}
+
+func TestCheckDNSWithoutRecords(t *testing.T) {
+	stub, stop := startDNSStub(t, map[string][]net.IP{})
+	defer stop()
+
+	monitorURL := db.MonitorURL{
+		ID:         "monitor",
+		URL:        "missing.example",
+		RecordType: "A",
+		Resolver:   stub.conn.LocalAddr().String(),
+	}
+
+	if result := checkDNS(context.Background(), monitorURL); result.Status != utils.StatusDown {
+		t.Errorf("expected DOWN without records, got %s", result.Status)
+	}
+}
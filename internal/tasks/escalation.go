@@ -0,0 +1,120 @@
+package tasks
+
+import (
+	"time"
+
+	"github.com/defraglabs/uptime/internal/db"
+	"github.com/defraglabs/uptime/internal/utils"
+	log "github.com/sirupsen/logrus"
+)
+
+const (
+	// escalationInterval is how often the incidents are checked for escalation.
+	escalationInterval = 10 * time.Second
+
+	// escalationBatchSize is the maximum number of incidents escalated per run.
+	escalationBatchSize = 100
+)
+
+// escalateAlert sends the status & stabilized alerts of the monitor urls with an escalation policy.
+// A DOWN alert opens an incident and notifies the first step of the policy, an UP alert resolves
+// it and is sent to every step notified so far.
+// Returns false if the alert is not handled by an escalation policy.
+func escalateAlert(alert db.Alert) bool {
+	if alert.Type != db.AlertTypeStatus && alert.Type != db.AlertTypeStabilized {
+		return false
+	}
+
+	datastore := db.New()
+	userID := alert.MonitorURL.UserID
+	incident := datastore.GetOpenIncidentByMonitorURLID(alert.MonitorURL.ID)
+
+	if alert.Status == utils.StatusUp {
+		if incident.ID == "" {
+			return false
+		}
+
+		datastore.ResolveIncident(incident.ID, alert.Time)
+
+		alert.IncidentID = incident.ID
+		notifyIntegrations(datastore, userID, incident.NotifiedIntegrationIDs, alert)
+		return true
+	}
+
+	if alert.MonitorURL.EscalationPolicyID == "" {
+		return false
+	} else if incident.ID != "" {
+		// The monitor url is still DOWN, the incident is already escalating.
+		return true
+	}
+
+	policy := datastore.GetEscalationPolicyByUserID(userID, alert.MonitorURL.EscalationPolicyID)
+	if policy.ID == "" || len(policy.Steps) == 0 {
+		log.Warnf("Escalation policy %s of %s not found", alert.MonitorURL.EscalationPolicyID, alert.MonitorURL.ID)
+		return false
+	}
+
+	incident = datastore.AddIncident(db.NewIncident(policy, alert))
+	notifyIntegrations(datastore, userID, policy.Steps[0].IntegrationIDs, incident.Alert)
+
+	return true
+}
+
+// notifyIntegrations queues the alert for delivery through the given integrations of the user.
+func notifyIntegrations(datastore *db.Datastore, userID string, integrationIDs []string, alert db.Alert) {
+	for _, integrationID := range integrationIDs {
+		integration := datastore.GetIntegrationByUserID(userID, integrationID)
+		if integration.ID == "" {
+			log.Warnf("Integration %s not found, skipping it", integrationID)
+			continue
+		}
+
+		datastore.AddNotification(db.NewNotification(integration, alert))
+	}
+}
+
+// startEscalations notifies the next step of the incidents which were not acknowledged in time.
+func startEscalations(isLeader func() bool) {
+	ticker := time.NewTicker(escalationInterval)
+	defer ticker.Stop()
+
+	for t := range ticker.C {
+		if !isLeader() {
+			continue
+		}
+
+		escalateIncidents(t)
+	}
+}
+
+// escalateIncidents notifies the next step of the due incidents.
+func escalateIncidents(t time.Time) {
+	datastore := db.New()
+
+	for _, incident := range datastore.GetDueIncidents(t, escalationBatchSize) {
+		// The monitor url is back UP or was deleted without the incident being resolved.
+		monitorURL := datastore.GetMonitoringURLByUserID(incident.UserID, incident.MonitorURLID)
+		if monitorURL.ID == "" || monitorURL.Status == utils.StatusUp {
+			log.Infof("Resolving incident %s of %s which is no longer DOWN", incident.ID, incident.MonitorURLID)
+			datastore.ResolveIncident(incident.ID, t)
+			continue
+		}
+
+		policy := datastore.GetEscalationPolicyByUserID(incident.UserID, incident.EscalationPolicyID)
+
+		nextStep := incident.Step + 1
+		if int(nextStep) >= len(policy.Steps) {
+			// The policy was deleted or shortened meanwhile, stop escalating.
+			datastore.EscalateIncident(incident.ID, incident.Step, incident.Step, []string{}, time.Time{})
+			continue
+		}
+
+		integrationIDs := policy.Steps[nextStep].IntegrationIDs
+		if !datastore.EscalateIncident(incident.ID, incident.Step, nextStep, integrationIDs, policy.GetNextEscalationAt(nextStep, t)) {
+			continue
+		}
+
+		log.Infof("Escalating incident %s to step %d", incident.ID, nextStep+1)
+		notifyIntegrations(datastore, incident.UserID, integrationIDs, incident.Alert)
+	}
+}
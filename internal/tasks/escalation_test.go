@@ -0,0 +1,237 @@
+package tasks
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/defraglabs/uptime/internal/db"
+	"github.com/defraglabs/uptime/internal/forms"
+	"github.com/defraglabs/uptime/internal/utils"
+)
+
+// skipWithoutMongo skips the test when mongo isn't reachable, e.g. outside of docker compose.
+func skipWithoutMongo(t *testing.T) {
+	address := fmt.Sprintf("%s:27017", os.Getenv("MONGO_HOST"))
+	conn, err := net.DialTimeout("tcp", address, time.Second)
+	if err != nil {
+		t.Skipf("mongo is not reachable on %s, set MONGO_HOST: %s", address, err)
+	}
+	conn.Close()
+
+	os.Setenv("MONGO_DATABASE_NAME", "uptime_test")
+}
+
+// clearTaskCollections drops the collections written by the tasks.
+func clearTaskCollections() {
+	datastore := db.New()
+	database := datastore.Client.Database(datastore.DatabaseName)
+
+	for _, collection := range []string{
+		db.MonitorURLCollection,
+		db.IntegrationCollection,
+		db.NotificationCollection,
+		db.EscalationPolicyCollection,
+		db.IncidentCollection,
+	} {
+		database.Collection(collection).Drop(context.Background())
+	}
+}
+
+// addTestEscalation adds a DOWN monitor url escalating to a first integration, then to a second one
+// after a minute. Returns the monitor url & the ids of the integrations.
+func addTestEscalation(datastore *db.Datastore) (db.MonitorURL, []string) {
+	userID := db.GenerateObjectID().Hex()
+
+	integrationIDs := []string{}
+	for _, email := range []string{"first@example.com", "second@example.com"} {
+		integration := datastore.AddIntegration(forms.IntegrationForm{
+			ID:     db.GenerateObjectID().Hex(),
+			UserID: userID,
+			Type:   db.EmailIntegration,
+			Email:  email,
+		})
+		integrationIDs = append(integrationIDs, integration.ID)
+	}
+
+	policy := datastore.AddEscalationPolicy(forms.EscalationPolicyForm{
+		ID:     db.GenerateObjectID().Hex(),
+		UserID: userID,
+		Name:   "on call",
+		Steps: []forms.EscalationStepForm{
+			{IntegrationIDs: integrationIDs[:1], Delay: 1},
+			{IntegrationIDs: integrationIDs[1:]},
+		},
+	})
+
+	monitorURL := datastore.AddMonitoringURL(forms.MonitorURLForm{
+		ID:                 db.GenerateObjectID().Hex(),
+		UserID:             userID,
+		Protocol:           "http",
+		Name:               "example",
+		URL:                "example.com",
+		Frequency:          5,
+		Unit:               "minute",
+		EscalationPolicyID: policy.ID,
+	})
+	datastore.UpdateMonitoringURLStatus(monitorURL.ID, utils.StatusDown, 0, utils.StatusDown)
+
+	return datastore.GetMonitoringURLByUserID(userID, monitorURL.ID), integrationIDs
+}
+
+// getNotifiedStatuses returns the statuses of the alerts sent through the integration.
+func getNotifiedStatuses(datastore *db.Datastore, monitorURL db.MonitorURL, integrationID string) []string {
+	statuses := []string{}
+	for _, notification := range datastore.GetNotificationsByIntegrationID(monitorURL.UserID, integrationID, 10) {
+		statuses = append(statuses, notification.Alert.Status)
+	}
+
+	return statuses
+}
+
+func TestEscalateAlertOpensAndResolvesIncident(t *testing.T) {
+	skipWithoutMongo(t)
+	defer clearTaskCollections()
+
+	datastore := db.New()
+	monitorURL, integrationIDs := addTestEscalation(datastore)
+
+	down := db.NewStatusAlert(monitorURL, utils.StatusUp, db.MonitorResult{Status: utils.StatusDown})
+	if !escalateAlert(down) {
+		t.Fatalf("expected the DOWN alert to be escalated")
+	}
+
+	incident := datastore.GetOpenIncidentByMonitorURLID(monitorURL.ID)
+	if incident.ID == "" || incident.Step != 0 {
+		t.Fatalf("expected an incident at the first step, got %+v", incident)
+	}
+
+	// Another DOWN alert of the same outage doesn't open another incident.
+	if !escalateAlert(down) || datastore.GetOpenIncidentByMonitorURLID(monitorURL.ID).ID != incident.ID {
+		t.Errorf("expected the incident to keep escalating")
+	}
+
+	up := db.NewStatusAlert(monitorURL, utils.StatusDown, db.MonitorResult{Status: utils.StatusUp})
+	if !escalateAlert(up) {
+		t.Fatalf("expected the UP alert to resolve the incident")
+	}
+
+	if datastore.GetIncidentByUserID(monitorURL.UserID, incident.ID).Status != db.IncidentStatusResolved {
+		t.Errorf("expected the incident to be resolved")
+	}
+
+	statuses := getNotifiedStatuses(datastore, monitorURL, integrationIDs[0])
+	if len(statuses) != 2 || !utils.StringInList(utils.StatusDown, statuses) || !utils.StringInList(utils.StatusUp, statuses) {
+		t.Errorf("expected the first step to be notified DOWN & UP, got %v", statuses)
+	}
+	if statuses = getNotifiedStatuses(datastore, monitorURL, integrationIDs[1]); len(statuses) != 0 {
+		t.Errorf("expected the second step not to be notified, got %v", statuses)
+	}
+
+	// The next outage opens a new incident.
+	if !escalateAlert(down) || datastore.GetOpenIncidentByMonitorURLID(monitorURL.ID).ID == incident.ID {
+		t.Errorf("expected the next DOWN alert to open a new incident")
+	}
+}
+
+func TestEscalateAlertResolvesIncidentOnStabilized(t *testing.T) {
+	skipWithoutMongo(t)
+	defer clearTaskCollections()
+
+	datastore := db.New()
+	monitorURL, _ := addTestEscalation(datastore)
+
+	escalateAlert(db.NewStatusAlert(monitorURL, utils.StatusUp, db.MonitorResult{Status: utils.StatusDown}))
+	incident := datastore.GetOpenIncidentByMonitorURLID(monitorURL.ID)
+
+	// Flapping doesn't resolve the incident, stabilizing DOWN neither.
+	result := db.MonitorResult{Status: utils.StatusDown}
+	if escalateAlert(db.NewFlappingAlert(monitorURL, true, utils.StatusDown, result)) {
+		t.Errorf("expected the flapping alert not to be escalated")
+	}
+	if !escalateAlert(db.NewFlappingAlert(monitorURL, false, utils.StatusDown, result)) {
+		t.Errorf("expected the stabilized DOWN alert to be escalated")
+	}
+	if datastore.GetOpenIncidentByMonitorURLID(monitorURL.ID).ID != incident.ID {
+		t.Fatalf("expected the incident to stay open")
+	}
+
+	result = db.MonitorResult{Status: utils.StatusUp}
+	if !escalateAlert(db.NewFlappingAlert(monitorURL, false, utils.StatusUp, result)) {
+		t.Errorf("expected the stabilized UP alert to be escalated")
+	}
+	if datastore.GetIncidentByUserID(monitorURL.UserID, incident.ID).Status != db.IncidentStatusResolved {
+		t.Errorf("expected the stabilized UP alert to resolve the incident")
+	}
+}
+
+func TestEscalateAlertWithoutPolicy(t *testing.T) {
+	skipWithoutMongo(t)
+	defer clearTaskCollections()
+
+	monitorURL := db.MonitorURL{ID: db.GenerateObjectID().Hex(), UserID: db.GenerateObjectID().Hex()}
+
+	if escalateAlert(db.NewStatusAlert(monitorURL, utils.StatusUp, db.MonitorResult{Status: utils.StatusDown})) {
+		t.Errorf("expected the DOWN alert of a url without policy not to be escalated")
+	}
+	if escalateAlert(db.NewStatusAlert(monitorURL, utils.StatusDown, db.MonitorResult{Status: utils.StatusUp})) {
+		t.Errorf("expected the UP alert of a url without incident not to be escalated")
+	}
+}
+
+func TestEscalateIncidentsNotifiesTheNextStep(t *testing.T) {
+	skipWithoutMongo(t)
+	defer clearTaskCollections()
+
+	datastore := db.New()
+	monitorURL, integrationIDs := addTestEscalation(datastore)
+
+	escalateAlert(db.NewStatusAlert(monitorURL, utils.StatusUp, db.MonitorResult{Status: utils.StatusDown}))
+	incident := datastore.GetOpenIncidentByMonitorURLID(monitorURL.ID)
+
+	// The delay of the first step isn't over yet.
+	escalateIncidents(time.Now())
+	if statuses := getNotifiedStatuses(datastore, monitorURL, integrationIDs[1]); len(statuses) != 0 {
+		t.Fatalf("expected the second step not to be notified yet, got %v", statuses)
+	}
+
+	escalateIncidents(time.Now().Add(2 * time.Minute))
+	incident = datastore.GetIncidentByUserID(monitorURL.UserID, incident.ID)
+	if incident.Step != 1 || len(incident.NotifiedIntegrationIDs) != 2 || !incident.NextEscalationAt.IsZero() {
+		t.Errorf("expected the incident at the last step with both integrations notified, got %+v", incident)
+	}
+	if statuses := getNotifiedStatuses(datastore, monitorURL, integrationIDs[1]); len(statuses) != 1 {
+		t.Errorf("expected the second step to be notified once, got %v", statuses)
+	}
+
+	// The last step isn't escalated further.
+	escalateIncidents(time.Now().Add(time.Hour))
+	if statuses := getNotifiedStatuses(datastore, monitorURL, integrationIDs[1]); len(statuses) != 1 {
+		t.Errorf("expected the second step to be notified once, got %v", statuses)
+	}
+}
+
+func TestEscalateIncidentsResolvesIncidentsOfUpURLs(t *testing.T) {
+	skipWithoutMongo(t)
+	defer clearTaskCollections()
+
+	datastore := db.New()
+	monitorURL, integrationIDs := addTestEscalation(datastore)
+
+	escalateAlert(db.NewStatusAlert(monitorURL, utils.StatusUp, db.MonitorResult{Status: utils.StatusDown}))
+	incident := datastore.GetOpenIncidentByMonitorURLID(monitorURL.ID)
+
+	// The url went back UP while flapping, without the incident being resolved.
+	datastore.UpdateMonitoringURLStatus(monitorURL.ID, utils.StatusUp, 0, utils.StatusUp)
+
+	escalateIncidents(time.Now().Add(2 * time.Minute))
+	if datastore.GetIncidentByUserID(monitorURL.UserID, incident.ID).Status != db.IncidentStatusResolved {
+		t.Errorf("expected the incident of the UP url to be resolved")
+	}
+	if statuses := getNotifiedStatuses(datastore, monitorURL, integrationIDs[1]); len(statuses) != 0 {
+		t.Errorf("expected the second step not to be paged, got %v", statuses)
+	}
+}
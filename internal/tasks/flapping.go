@@ -0,0 +1,72 @@
+package tasks
+
+import (
+	"github.com/defraglabs/uptime/internal/db"
+)
+
+const (
+	// flapWindowSize is the number of latest results the state change rate is computed on.
+	flapWindowSize = 20
+
+	// flapMinResults is the number of results required before a monitor url can be flapping.
+	flapMinResults = 10
+
+	// flapStartRate is the state change rate at which a monitor url starts flapping.
+	flapStartRate = 0.3
+
+	// flapStopRate is the state change rate below which a flapping monitor url is stabilized.
+	// Lower than flapStartRate so that a rate close to the threshold doesn't flap itself.
+	flapStopRate = 0.15
+)
+
+// getStateChangeRate returns the share of consecutive statuses which differ.
+func getStateChangeRate(statuses []string) float64 {
+	if len(statuses) < 2 {
+		return 0
+	}
+
+	changes := 0
+	for i := 1; i < len(statuses); i++ {
+		if statuses[i] != statuses[i-1] {
+			changes++
+		}
+	}
+
+	return float64(changes) / float64(len(statuses)-1)
+}
+
+// isFlapping checks if the monitor url is flapping given the statuses of its latest results.
+func isFlapping(flapping bool, statuses []string) bool {
+	if len(statuses) < flapMinResults {
+		return false
+	}
+
+	rate := getStateChangeRate(statuses)
+	if flapping {
+		return rate >= flapStopRate
+	}
+	return rate >= flapStartRate
+}
+
+// getRecentStatuses returns the status of the current check followed by the statuses of the previous results,
+// latest first.
+func getRecentStatuses(result db.MonitorResult, previousResults []db.MonitorResult) []string {
+	statuses := []string{result.Status}
+	for _, previousResult := range previousResults {
+		// Monitor urls with fewer results are padded with empty ones.
+		// Results recorded during maintenance don't count.
+		if previousResult.Status != "" && !previousResult.Maintenance {
+			statuses = append(statuses, previousResult.Status)
+		}
+	}
+
+	return statuses
+}
+
+// detectFlapping checks if the monitor url is flapping, taking the result of the current check into account.
+func detectFlapping(monitorURL db.MonitorURL, result db.MonitorResult) bool {
+	datastore := db.New()
+	previousResults := datastore.GetLastNMonitoringURLStats(monitorURL.ID, flapWindowSize-1)
+
+	return isFlapping(monitorURL.Flapping, getRecentStatuses(result, previousResults))
+}
@@ -0,0 +1,118 @@
+package tasks
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/defraglabs/uptime/internal/db"
+	"github.com/defraglabs/uptime/internal/utils"
+)
+
+// parseStatuses returns the statuses of a sequence like `UDUU`, U being UP & D DOWN.
+func parseStatuses(sequence string) []string {
+	statuses := []string{}
+	for _, status := range sequence {
+		if status == 'U' {
+			statuses = append(statuses, utils.StatusUp)
+		} else {
+			statuses = append(statuses, utils.StatusDown)
+		}
+	}
+
+	return statuses
+}
+
+func TestGetStateChangeRate(t *testing.T) {
+	cases := map[string]float64{
+		"":      0,
+		"U":     0,
+		"UU":    0,
+		"UD":    1,
+		"UDUD":  1,
+		"UUUDD": 0.25,
+		"UUDUU": 0.5,
+	}
+
+	for sequence, expected := range cases {
+		if rate := getStateChangeRate(parseStatuses(sequence)); rate != expected {
+			t.Errorf("%s: expected rate %v, got %v", sequence, expected, rate)
+		}
+	}
+}
+
+func TestIsFlapping(t *testing.T) {
+	cases := []struct {
+		sequence string
+		flapping bool
+		expected bool
+	}{
+		// Fewer results than flapMinResults never flap.
+		{"UDUDUDUDU", false, false},
+		{"UDUDUDUDU", true, false},
+		// 3 changes out of 9: at the start rate.
+		{"UUDDDUUUDD", false, true},
+		// 2 changes out of 9: between the stop & the start rates.
+		{"UUUUDDDUUU", false, false},
+		{"UUUUDDDUUU", true, true},
+		// 1 change out of 9: below the stop rate.
+		{"UUUUUDDDDD", true, false},
+		{"UUUUUUUUUUUUUUUUUUUU", true, false},
+		{"UDUDUDUDUDUDUDUDUDUD", false, true},
+	}
+
+	for _, c := range cases {
+		if flapping := isFlapping(c.flapping, parseStatuses(c.sequence)); flapping != c.expected {
+			t.Errorf("%s while flapping %v: expected %v, got %v", c.sequence, c.flapping, c.expected, flapping)
+		}
+	}
+}
+
+func TestFlappingStartsAndStabilizesOnce(t *testing.T) {
+	// Stable, then failing every other check for a while, then stable again.
+	sequence := "UUUUUUUUUU" + "DUDUDUDUDU" + strings.Repeat("U", 30)
+
+	flapping := false
+	previousResults := []db.MonitorResult{}
+	started, stabilized := []int{}, []int{}
+
+	for i, status := range parseStatuses(sequence) {
+		result := db.MonitorResult{Status: status}
+		if isFlapping(flapping, getRecentStatuses(result, previousResults)) != flapping {
+			flapping = !flapping
+			if flapping {
+				started = append(started, i)
+			} else {
+				stabilized = append(stabilized, i)
+			}
+		}
+
+		previousResults = append([]db.MonitorResult{result}, previousResults...)
+		if len(previousResults) > flapWindowSize-1 {
+			previousResults = previousResults[:flapWindowSize-1]
+		}
+	}
+
+	if len(started) != 1 || started[0] < 10 || started[0] >= 20 {
+		t.Errorf("expected flapping to start once while failing every other check, started at %v", started)
+	}
+	if len(stabilized) != 1 || stabilized[0] < 20 {
+		t.Errorf("expected flapping to stabilize once after the failures, stabilized at %v", stabilized)
+	}
+	if flapping {
+		t.Errorf("expected the monitor url to be stabilized")
+	}
+}
+
+func TestGetRecentStatusesSkipsMaintenanceAndPadding(t *testing.T) {
+	previousResults := []db.MonitorResult{
+		{Status: utils.StatusDown},
+		{Status: utils.StatusDown, Maintenance: true},
+		{Status: utils.StatusUp},
+		{},
+	}
+
+	statuses := getRecentStatuses(db.MonitorResult{Status: utils.StatusUp}, previousResults)
+	if strings.Join(statuses, ",") != "UP,DOWN,UP" {
+		t.Errorf("expected UP,DOWN,UP, got %v", statuses)
+	}
+}
@@ -0,0 +1,130 @@
+package tasks
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/defraglabs/uptime/internal/db"
+	"github.com/defraglabs/uptime/internal/utils"
+	log "github.com/sirupsen/logrus"
+)
+
+const (
+	// HeartbeatPing is sent by the job when it completes.
+	HeartbeatPing = "ping"
+
+	// HeartbeatStart is sent by the job when it starts. It is used to record the run duration.
+	HeartbeatStart = "start"
+
+	// HeartbeatFail is sent by the job when it fails.
+	HeartbeatFail = "fail"
+)
+
+// getHeartbeatDeadline returns when the ping following the one at t is late.
+// Returns the zero time if the cron expression never matches.
+func getHeartbeatDeadline(monitorURL db.MonitorURL, t time.Time) time.Time {
+	var expectedAt time.Time
+	if monitorURL.CronExpression != "" {
+		schedule, err := utils.ParseCron(monitorURL.CronExpression)
+		if err != nil {
+			return time.Time{}
+		}
+
+		expectedAt = schedule.Next(t.UTC())
+		if expectedAt.IsZero() {
+			return expectedAt
+		}
+	} else {
+		expectedAt = t.Add(utils.GetMonitoringInterval(monitorURL.Frequency, monitorURL.Unit))
+	}
+
+	return expectedAt.Add(time.Duration(monitorURL.GracePeriod) * time.Second)
+}
+
+// scheduleHeartbeat queues the check of a heartbeat monitor once the deadline of the next ping has passed.
+func scheduleHeartbeat(pool *workerPool, datastore *db.Datastore, monitorURL db.MonitorURL, t time.Time) {
+	if monitorURL.NextCheckAt.IsZero() {
+		// New or updated monitor, the deadline is counted from the last ping.
+		since := monitorURL.LastPingAt
+		if since.IsZero() {
+			since = t
+		}
+
+		deadline := getHeartbeatDeadline(monitorURL, since)
+		if deadline.IsZero() {
+			log.Infof("Invalid cron expression found for heartbeat %s", monitorURL.Name)
+			return
+		}
+
+		datastore.UpdateMonitoringURLSchedule(monitorURL.ID, monitorURL.LastCheckedAt, deadline)
+		return
+	} else if !isDue(monitorURL, t) {
+		return
+	}
+
+	err := pool.submit(monitorURL)
+	if err != nil {
+		log.Warnf("Skipping check for heartbeat %s: %s", monitorURL.Name, err)
+		return
+	}
+
+	datastore.UpdateMonitoringURLSchedule(monitorURL.ID, t, getHeartbeatDeadline(monitorURL, t))
+}
+
+// checkHeartbeat runs once the deadline of the next ping has passed without any ping.
+func checkHeartbeat(monitorURL db.MonitorURL) db.MonitorResult {
+	result := db.MonitorResult{
+		Time:              time.Now().UTC().String(),
+		Status:            utils.StatusDown,
+		StatusDescription: "No ping received",
+	}
+
+	if !monitorURL.LastPingAt.IsZero() {
+		result.StatusDescription = fmt.Sprintf("No ping received since %s", monitorURL.LastPingAt.UTC())
+	}
+
+	return result
+}
+
+// ReceiveHeartbeat records a ping sent by the job of a heartbeat monitor and sends the alerts.
+// The run duration is recorded as the response time when the job reported its start.
+func ReceiveHeartbeat(monitorURL db.MonitorURL, event string) {
+	now := time.Now()
+	datastore := db.New()
+
+	if event == HeartbeatStart {
+		datastore.UpdateHeartbeatStart(monitorURL.ID, now)
+		return
+	}
+
+	result := db.MonitorResult{
+		Time:              now.UTC().String(),
+		Status:            utils.StatusUp,
+		StatusDescription: "Ping received",
+	}
+
+	hasDuration := monitorURL.LastStartAt.After(monitorURL.LastPingAt)
+	if hasDuration {
+		duration := now.Sub(monitorURL.LastStartAt)
+		result.ResponseTime = float64(duration.Nanoseconds()) / 1000000
+		result.StatusDescription = fmt.Sprintf("Run completed in %s", duration.Round(time.Millisecond))
+	}
+
+	if event == HeartbeatFail {
+		result.Status = utils.StatusDown
+		result.StatusDescription = "Run failed"
+	} else if hasDuration {
+		response := assertionResponse{
+			responseTime: result.ResponseTime,
+		}
+
+		failedAssertion := evaluateAssertions(monitorURL.Assertions, response)
+		if failedAssertion != "" {
+			result.Status = utils.StatusDown
+			result.FailedAssertion = failedAssertion
+		}
+	}
+
+	RecordMonitorResult(monitorURL, result)
+	datastore.UpdateHeartbeatPing(monitorURL.ID, now, getHeartbeatDeadline(monitorURL, now))
+}
@@ -0,0 +1,131 @@
+package tasks
+
+import (
+	"fmt"
+	"os"
+	"sync"
+	"time"
+
+	"github.com/defraglabs/uptime/internal/cache"
+	"github.com/go-redis/redis"
+	"github.com/gofrs/uuid"
+	log "github.com/sirupsen/logrus"
+)
+
+const (
+	// leaderKey is the redis key holding the id of the active scheduler.
+	leaderKey = "uptime:scheduler:leader"
+
+	// leaseDuration is how long the lease is valid without being renewed.
+	// A dead leader is replaced after at most this duration.
+	leaseDuration = 15 * time.Second
+
+	// renewInterval is how often the lease is acquired or renewed.
+	renewInterval = 5 * time.Second
+)
+
+// renewLeaseScript extends the lease only if it is still held by the caller.
+const renewLeaseScript = `
+if redis.call("get", KEYS[1]) == ARGV[1] then
+	return redis.call("pexpire", KEYS[1], ARGV[2])
+end
+return 0
+`
+
+// leaderElection makes sure only one scheduler is active among the replicas.
+// The leader holds a lease in redis which it keeps renewing. When it dies the
+// lease expires and another replica takes over.
+type leaderElection struct {
+	client *redis.Client
+	id     string
+
+	leader bool
+	mutex  sync.RWMutex
+}
+
+func newLeaderElection(client *redis.Client) *leaderElection {
+	hostname, _ := os.Hostname()
+
+	return &leaderElection{
+		client: client,
+		id:     fmt.Sprintf("%s-%s", hostname, uuid.Must(uuid.NewV4())),
+	}
+}
+
+// isLeader checks if this replica currently holds the lease.
+func (election *leaderElection) isLeader() bool {
+	election.mutex.RLock()
+	defer election.mutex.RUnlock()
+
+	return election.leader
+}
+
+func (election *leaderElection) setLeader(leader bool) {
+	election.mutex.Lock()
+	defer election.mutex.Unlock()
+
+	if leader && !election.leader {
+		log.Infof("Scheduler %s acquired leadership", election.id)
+	} else if !leader && election.leader {
+		log.Warnf("Scheduler %s lost leadership", election.id)
+	}
+
+	election.leader = leader
+}
+
+// campaign renews the lease if it is still held by this replica, or tries to acquire it.
+// The lease is renewed even after stepping down, the replica takes its own lease back
+// when redis recovers instead of waiting for it to expire.
+func (election *leaderElection) campaign() {
+	renewed, err := election.client.Eval(
+		renewLeaseScript, []string{leaderKey}, election.id, int64(leaseDuration/time.Millisecond),
+	).Int64()
+
+	if err != nil {
+		log.Warnf("Unable to renew scheduler lease: %s", err)
+
+		// Step down on errors, another replica may take over once the lease expires.
+		election.setLeader(false)
+		return
+	} else if renewed == 1 {
+		election.setLeader(true)
+		return
+	}
+
+	acquired, err := election.client.SetNX(leaderKey, election.id, leaseDuration).Result()
+	if err != nil {
+		log.Warnf("Unable to acquire scheduler lease: %s", err)
+		acquired = false
+	}
+
+	election.setLeader(acquired)
+}
+
+// run campaigns for the leadership forever.
+func (election *leaderElection) run() {
+	election.campaign()
+
+	ticker := time.NewTicker(renewInterval)
+	defer ticker.Stop()
+
+	for range ticker.C {
+		election.campaign()
+	}
+}
+
+// startLeaderElection starts campaigning in the background and returns a func
+// which reports whether this replica should run the checks.
+// Without a valid redis configuration every replica runs the checks.
+func startLeaderElection() func() bool {
+	client, err := cache.GetClient()
+	if err != nil {
+		log.Warnf("Leader election disabled: %s", err)
+
+		return func() bool { return true }
+	}
+
+	election := newLeaderElection(client)
+	go election.run()
+
+	return election.isLeader
+}
@@ -0,0 +1,186 @@
+package tasks
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"net"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/go-redis/redis"
+)
+
+// redisStub is a minimal redis server, it knows the commands of the leader election:
+// SET NX & the renew script. Every command fails while down is set.
+type redisStub struct {
+	listener net.Listener
+
+	mutex  sync.Mutex
+	values map[string]string
+	down   bool
+}
+
+func startRedisStub(t *testing.T) *redisStub {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("unable to start the redis stub: %s", err)
+	}
+
+	stub := &redisStub{listener: listener, values: make(map[string]string)}
+	go func() {
+		for {
+			conn, err := listener.Accept()
+			if err != nil {
+				return
+			}
+
+			go stub.serve(conn)
+		}
+	}()
+
+	return stub
+}
+
+func (stub *redisStub) client() *redis.Client {
+	return redis.NewClient(&redis.Options{Addr: stub.listener.Addr().String(), MaxRetries: 0})
+}
+
+func (stub *redisStub) setDown(down bool) {
+	stub.mutex.Lock()
+	defer stub.mutex.Unlock()
+
+	stub.down = down
+}
+
+func (stub *redisStub) get(key string) string {
+	stub.mutex.Lock()
+	defer stub.mutex.Unlock()
+
+	return stub.values[key]
+}
+
+// readCommand reads a command, an array of bulk strings.
+func readCommand(reader *bufio.Reader) ([]string, error) {
+	line, err := reader.ReadString('\n')
+	if err != nil {
+		return nil, err
+	}
+
+	count, _ := strconv.Atoi(strings.TrimSpace(line[1:]))
+	args := make([]string, count)
+	for i := range args {
+		header, err := reader.ReadString('\n')
+		if err != nil {
+			return nil, err
+		}
+
+		// Bulk strings may contain new lines, they are read by length.
+		length, _ := strconv.Atoi(strings.TrimSpace(header[1:]))
+		arg := make([]byte, length+2)
+		if _, err := io.ReadFull(reader, arg); err != nil {
+			return nil, err
+		}
+		args[i] = string(arg[:length])
+	}
+
+	return args, nil
+}
+
+func (stub *redisStub) serve(conn net.Conn) {
+	defer conn.Close()
+
+	reader := bufio.NewReader(conn)
+	for {
+		args, err := readCommand(reader)
+		if err != nil {
+			return
+		}
+
+		conn.Write([]byte(stub.execute(args)))
+	}
+}
+
+func (stub *redisStub) execute(args []string) string {
+	stub.mutex.Lock()
+	defer stub.mutex.Unlock()
+
+	if stub.down {
+		return "-ERR stub is down\r\n"
+	}
+
+	switch strings.ToLower(args[0]) {
+	case "set":
+		// set key value ex|px ttl nx
+		if _, ok := stub.values[args[1]]; ok {
+			return "$-1\r\n"
+		}
+
+		stub.values[args[1]] = args[2]
+		return "+OK\r\n"
+	case "eval":
+		// eval script 1 key id ttl, renews the lease held by id.
+		if stub.values[args[3]] == args[4] {
+			return ":1\r\n"
+		}
+
+		return ":0\r\n"
+	}
+
+	return fmt.Sprintf("-ERR unknown command %s\r\n", args[0])
+}
+
+func TestLeaderElectionElectsOneLeader(t *testing.T) {
+	stub := startRedisStub(t)
+	defer stub.listener.Close()
+
+	first := newLeaderElection(stub.client())
+	second := newLeaderElection(stub.client())
+
+	first.campaign()
+	second.campaign()
+
+	if !first.isLeader() || second.isLeader() {
+		t.Fatalf("expected the first replica to lead, got %v & %v", first.isLeader(), second.isLeader())
+	}
+	if stub.get(leaderKey) != first.id {
+		t.Errorf("expected the lease to be held by %s, got %s", first.id, stub.get(leaderKey))
+	}
+
+	first.campaign()
+	second.campaign()
+
+	if !first.isLeader() || second.isLeader() {
+		t.Errorf("expected the leader to renew its lease, got %v & %v", first.isLeader(), second.isLeader())
+	}
+}
+
+func TestLeaderElectionTakesItsLeaseBackAfterRedisErrors(t *testing.T) {
+	stub := startRedisStub(t)
+	defer stub.listener.Close()
+
+	leader := newLeaderElection(stub.client())
+	follower := newLeaderElection(stub.client())
+
+	leader.campaign()
+	follower.campaign()
+
+	stub.setDown(true)
+	leader.campaign()
+	follower.campaign()
+
+	if leader.isLeader() || follower.isLeader() {
+		t.Fatalf("expected the replicas to step down while redis fails")
+	}
+
+	// The lease of the leader is still live, it takes it back instead of waiting for it to expire.
+	stub.setDown(false)
+	leader.campaign()
+	follower.campaign()
+
+	if !leader.isLeader() || follower.isLeader() {
+		t.Errorf("expected the leader to take its lease back, got %v & %v", leader.isLeader(), follower.isLeader())
+	}
+}
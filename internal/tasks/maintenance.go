@@ -0,0 +1,27 @@
+package tasks
+
+import (
+	"time"
+
+	"github.com/defraglabs/uptime/internal/db"
+	"github.com/defraglabs/uptime/internal/utils"
+)
+
+// isUnderMaintenance checks if a maintenance window covering the monitor url is in progress at t.
+func isUnderMaintenance(datastore *db.Datastore, monitorURL db.MonitorURL, t time.Time) bool {
+	windows := datastore.GetMaintenanceWindowsByUserID(monitorURL.UserID)
+	window := db.GetActiveMaintenanceWindow(windows, monitorURL, t)
+
+	return window.ID != ""
+}
+
+// skipMaintenanceCheck skips the check of a monitor url during a maintenance window.
+// Urls are left due so that they are checked as soon as the window ends. Heartbeats have
+// their deadline pushed instead, the job gets a full period after the window to ping.
+func skipMaintenanceCheck(datastore *db.Datastore, monitorURL db.MonitorURL, t time.Time) {
+	if monitorURL.Type != utils.MonitorTypeHeartbeat || !isDue(monitorURL, t) {
+		return
+	}
+
+	datastore.UpdateMonitoringURLSchedule(monitorURL.ID, monitorURL.LastCheckedAt, getHeartbeatDeadline(monitorURL, t))
+}
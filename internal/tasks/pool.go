@@ -0,0 +1,71 @@
+package tasks
+
+import (
+	"errors"
+	"sync"
+
+	"github.com/defraglabs/uptime/internal/db"
+)
+
+var (
+	// errCheckInFlight is returned when the previous check of a monitor url
+	// hasn't finished yet.
+	errCheckInFlight = errors.New("check already in flight")
+
+	// errPoolSaturated is returned when every worker is busy and the queue is full.
+	errPoolSaturated = errors.New("worker pool saturated")
+)
+
+// workerPool runs monitor url checks with a bounded number of workers.
+type workerPool struct {
+	jobs chan db.MonitorURL
+
+	// inFlight holds the ids of the monitor urls which are queued or being checked.
+	inFlight map[string]bool
+	mutex    sync.Mutex
+}
+
+// newWorkerPool starts size workers which call check for every submitted
+// monitor url. At most size checks run at a time.
+func newWorkerPool(size int, check func(db.MonitorURL)) *workerPool {
+	pool := &workerPool{
+		jobs:     make(chan db.MonitorURL, size),
+		inFlight: make(map[string]bool),
+	}
+
+	for i := 0; i < size; i++ {
+		go func() {
+			for monitorURL := range pool.jobs {
+				check(monitorURL)
+				pool.done(monitorURL.ID)
+			}
+		}()
+	}
+
+	return pool
+}
+
+// submit queues a check without blocking the caller.
+func (pool *workerPool) submit(monitorURL db.MonitorURL) error {
+	pool.mutex.Lock()
+	defer pool.mutex.Unlock()
+
+	if pool.inFlight[monitorURL.ID] {
+		return errCheckInFlight
+	}
+
+	select {
+	case pool.jobs <- monitorURL:
+		pool.inFlight[monitorURL.ID] = true
+		return nil
+	default:
+		return errPoolSaturated
+	}
+}
+
+func (pool *workerPool) done(monitorURLID string) {
+	pool.mutex.Lock()
+	defer pool.mutex.Unlock()
+
+	delete(pool.inFlight, monitorURLID)
+}
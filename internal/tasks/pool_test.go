@@ -0,0 +1,140 @@
+package tasks
+
+import (
+	"fmt"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/defraglabs/uptime/internal/db"
+)
+
+// blockingCheck is a check which blocks until released, reporting the monitor urls it starts.
+type blockingCheck struct {
+	started chan string
+	release chan struct{}
+}
+
+func newBlockingCheck() *blockingCheck {
+	return &blockingCheck{started: make(chan string, 100), release: make(chan struct{})}
+}
+
+func (check *blockingCheck) run(monitorURL db.MonitorURL) {
+	check.started <- monitorURL.ID
+	<-check.release
+}
+
+// waitStarted waits for n checks to start.
+func (check *blockingCheck) waitStarted(t *testing.T, n int) {
+	for i := 0; i < n; i++ {
+		select {
+		case <-check.started:
+		case <-time.After(time.Second):
+			t.Fatalf("expected %d checks to start, %d did", n, i)
+		}
+	}
+}
+
+func TestWorkerPoolSkipsCheckInFlight(t *testing.T) {
+	check := newBlockingCheck()
+	pool := newWorkerPool(2, check.run)
+	defer close(check.release)
+
+	monitorURL := db.MonitorURL{ID: "monitor"}
+	if err := pool.submit(monitorURL); err != nil {
+		t.Fatalf("unexpected error %s", err)
+	}
+	check.waitStarted(t, 1)
+
+	if err := pool.submit(monitorURL); err != errCheckInFlight {
+		t.Errorf("expected the check in flight to be skipped, got %v", err)
+	}
+	if err := pool.submit(db.MonitorURL{ID: "other"}); err != nil {
+		t.Errorf("expected another monitor url to be checked, got %v", err)
+	}
+	check.waitStarted(t, 1)
+
+	// Once done, the monitor url can be checked again.
+	check.release <- struct{}{}
+	check.release <- struct{}{}
+
+	deadline := time.Now().Add(time.Second)
+	for pool.submit(monitorURL) == errCheckInFlight {
+		if time.Now().After(deadline) {
+			t.Fatalf("expected the check to be submitted again once done")
+		}
+		time.Sleep(time.Millisecond)
+	}
+	check.waitStarted(t, 1)
+}
+
+func TestWorkerPoolIsSaturated(t *testing.T) {
+	check := newBlockingCheck()
+	pool := newWorkerPool(2, check.run)
+	defer close(check.release)
+
+	// The two workers are busy, then the queue of two fills up.
+	for i := 0; i < 4; i++ {
+		if err := pool.submit(db.MonitorURL{ID: fmt.Sprintf("monitor-%d", i)}); err != nil {
+			t.Fatalf("monitor-%d: unexpected error %s", i, err)
+		}
+		if i == 1 {
+			check.waitStarted(t, 2)
+		}
+	}
+
+	if err := pool.submit(db.MonitorURL{ID: "monitor-4"}); err != errPoolSaturated {
+		t.Errorf("expected the pool to be saturated, got %v", err)
+	}
+	if err := pool.submit(db.MonitorURL{ID: "monitor-3"}); err != errCheckInFlight {
+		t.Errorf("expected the queued check to be in flight, got %v", err)
+	}
+}
+
+func TestWorkerPoolBoundsConcurrentChecks(t *testing.T) {
+	const size, checks = 3, 30
+
+	var mutex sync.Mutex
+	running, maxRunning := 0, 0
+	var wg sync.WaitGroup
+	wg.Add(checks)
+
+	pool := newWorkerPool(size, func(monitorURL db.MonitorURL) {
+		mutex.Lock()
+		running++
+		if running > maxRunning {
+			maxRunning = running
+		}
+		mutex.Unlock()
+
+		time.Sleep(2 * time.Millisecond)
+
+		mutex.Lock()
+		running--
+		mutex.Unlock()
+		wg.Done()
+	})
+
+	for i := 0; i < checks; i++ {
+		monitorURL := db.MonitorURL{ID: fmt.Sprintf("monitor-%d", i)}
+		for pool.submit(monitorURL) == errPoolSaturated {
+			time.Sleep(time.Millisecond)
+		}
+	}
+
+	done := make(chan struct{})
+	go func() {
+		wg.Wait()
+		close(done)
+	}()
+
+	select {
+	case <-done:
+	case <-time.After(5 * time.Second):
+		t.Fatalf("expected the %d checks to run", checks)
+	}
+
+	if maxRunning > size || maxRunning < 1 {
+		t.Errorf("expected at most %d checks at a time, got %d", size, maxRunning)
+	}
+}
@@ -0,0 +1,98 @@
+package tasks
+
+import (
+	"time"
+
+	"github.com/defraglabs/uptime/internal/db"
+)
+
+// reminderCheckInterval is how often the monitor urls which are DOWN are checked for reminders.
+const reminderCheckInterval = 30 * time.Second
+
+// getReminderSettings returns the reminder interval & max count of the monitor url,
+// falling back to the ones of the integration.
+func getReminderSettings(monitorURL db.MonitorURL, integration db.Integration) (time.Duration, int32) {
+	if monitorURL.ReminderInterval > 0 {
+		return time.Duration(monitorURL.ReminderInterval) * time.Minute, monitorURL.ReminderMaxCount
+	}
+
+	return time.Duration(integration.ReminderInterval) * time.Minute, integration.ReminderMaxCount
+}
+
+// getDueReminders returns the number of reminders which should have been sent after the downtime,
+// capped by the max count.
+func getDueReminders(downtime, interval time.Duration, maxCount int32) int32 {
+	if interval <= 0 {
+		return 0
+	}
+
+	reminders := int32(downtime / interval)
+	if maxCount > 0 && reminders > maxCount {
+		return maxCount
+	}
+
+	return reminders
+}
+
+// getReminderIntegrations returns the integrations reminded of the outage: the ones notified
+// so far when the monitor url has an incident, none once it is acknowledged.
+func getReminderIntegrations(datastore *db.Datastore, monitorURL db.MonitorURL) []db.Integration {
+	incident := datastore.GetOpenIncidentByMonitorURLID(monitorURL.ID)
+	if incident.ID == "" {
+		return getMonitorURLIntegrations(datastore, monitorURL)
+	} else if incident.Status == db.IncidentStatusAcknowledged {
+		return []db.Integration{}
+	}
+
+	integrations := []db.Integration{}
+	for _, integrationID := range incident.NotifiedIntegrationIDs {
+		integration := datastore.GetIntegrationByUserID(monitorURL.UserID, integrationID)
+		if integration.ID != "" {
+			integrations = append(integrations, integration)
+		}
+	}
+
+	return integrations
+}
+
+// startReminders reminds the integrations of the outages every reminder interval.
+func startReminders(isLeader func() bool) {
+	ticker := time.NewTicker(reminderCheckInterval)
+	defer ticker.Stop()
+
+	for t := range ticker.C {
+		if !isLeader() {
+			continue
+		}
+
+		sendReminders(t)
+	}
+}
+
+// sendReminders sends the due reminders of the monitor urls which are DOWN. Reminders missed
+// while the scheduler was down are sent once. Flapping monitor urls & the ones under
+// maintenance are not reminded of.
+func sendReminders(t time.Time) {
+	datastore := db.New()
+	maintenanceWindows := datastore.GetMaintenanceWindows(t)
+
+	for _, monitorURL := range datastore.GetDownMonitoringURLS() {
+		if monitorURL.Flapping || db.GetActiveMaintenanceWindow(maintenanceWindows, monitorURL, t).ID != "" {
+			continue
+		}
+
+		reminder := db.NewReminderAlert(monitorURL, t)
+
+		for _, integration := range getReminderIntegrations(datastore, monitorURL) {
+			interval, maxCount := getReminderSettings(monitorURL, integration)
+
+			dueReminders := getDueReminders(reminder.Downtime(), interval, maxCount)
+			if dueReminders <= monitorURL.RemindersSent[integration.ID] {
+				continue
+			}
+
+			datastore.SetMonitoringURLReminderSent(monitorURL.ID, integration.ID, dueReminders)
+			datastore.AddNotification(db.NewNotification(integration, reminder))
+		}
+	}
+}
@@ -0,0 +1,134 @@
+package tasks
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/defraglabs/uptime/internal/db"
+	"github.com/defraglabs/uptime/internal/forms"
+	"github.com/defraglabs/uptime/internal/utils"
+)
+
+func TestGetDueReminders(t *testing.T) {
+	cases := []struct {
+		downtime, interval time.Duration
+		maxCount           int32
+		expected           int32
+	}{
+		{time.Hour, 0, 0, 0},
+		{5 * time.Minute, 10 * time.Minute, 0, 0},
+		{10 * time.Minute, 10 * time.Minute, 0, 1},
+		{25 * time.Minute, 10 * time.Minute, 0, 2},
+		{25 * time.Minute, 10 * time.Minute, 1, 1},
+		{24 * time.Hour, 10 * time.Minute, 3, 3},
+		{24 * time.Hour, 10 * time.Minute, 0, 144},
+	}
+
+	for _, c := range cases {
+		if reminders := getDueReminders(c.downtime, c.interval, c.maxCount); reminders != c.expected {
+			t.Errorf("%s every %s up to %d: expected %d reminders, got %d", c.downtime, c.interval, c.maxCount, c.expected, reminders)
+		}
+	}
+}
+
+func TestGetReminderSettings(t *testing.T) {
+	integration := db.Integration{ReminderInterval: 30, ReminderMaxCount: 5}
+
+	interval, maxCount := getReminderSettings(db.MonitorURL{}, integration)
+	if interval != 30*time.Minute || maxCount != 5 {
+		t.Errorf("expected the settings of the integration, got %s up to %d", interval, maxCount)
+	}
+
+	interval, maxCount = getReminderSettings(db.MonitorURL{ReminderInterval: 10}, integration)
+	if interval != 10*time.Minute || maxCount != 0 {
+		t.Errorf("expected the settings of the monitor url, got %s up to %d", interval, maxCount)
+	}
+}
+
+// addTestDownMonitorURL adds a monitor url of the user, DOWN since the time, reminded every 10 minutes
+// up to twice.
+func addTestDownMonitorURL(datastore *db.Datastore, userID string, downSince time.Time) db.MonitorURL {
+	monitorURL := datastore.AddMonitoringURL(forms.MonitorURLForm{
+		ID:               db.GenerateObjectID().Hex(),
+		UserID:           userID,
+		Protocol:         "http",
+		Name:             "example",
+		URL:              "example.com",
+		Frequency:        5,
+		Unit:             "minute",
+		ReminderInterval: 10,
+		ReminderMaxCount: 2,
+	})
+	datastore.UpdateMonitoringURLStatus(monitorURL.ID, utils.StatusDown, 0, utils.StatusDown)
+	datastore.UpdateMonitoringURLDownSince(monitorURL.ID, downSince)
+
+	return datastore.GetMonitoringURLByUserID(userID, monitorURL.ID)
+}
+
+func TestSendReminders(t *testing.T) {
+	skipWithoutMongo(t)
+	clearTaskCollections()
+	defer clearTaskCollections()
+
+	datastore := db.New()
+	userID := db.GenerateObjectID().Hex()
+	integration := datastore.AddIntegration(forms.IntegrationForm{
+		ID:     db.GenerateObjectID().Hex(),
+		UserID: userID,
+		Type:   db.EmailIntegration,
+		Email:  "ops@example.com",
+	})
+
+	now := time.Now()
+	monitorURL := addTestDownMonitorURL(datastore, userID, now.Add(-25*time.Minute))
+
+	// The two reminders missed are sent once.
+	sendReminders(now)
+
+	notifications := datastore.GetNotificationsByIntegrationID(userID, integration.ID, 10)
+	if len(notifications) != 1 {
+		t.Fatalf("expected 1 reminder, got %d", len(notifications))
+	}
+	if !strings.Contains(notifications[0].Alert.Message, "still DOWN after 25m") {
+		t.Errorf("expected the reminder to tell the downtime, got %q", notifications[0].Alert.Message)
+	}
+
+	monitorURL = datastore.GetMonitoringURLByUserID(userID, monitorURL.ID)
+	if monitorURL.RemindersSent[integration.ID] != 2 {
+		t.Errorf("expected 2 reminders sent, got %d", monitorURL.RemindersSent[integration.ID])
+	}
+
+	// No reminder is due until the next interval, and the max count is reached then.
+	sendReminders(now.Add(time.Minute))
+	sendReminders(now.Add(10 * time.Minute))
+
+	if notifications := datastore.GetNotificationsByIntegrationID(userID, integration.ID, 10); len(notifications) != 1 {
+		t.Errorf("expected no more reminders, got %d", len(notifications))
+	}
+}
+
+func TestSendRemindersSkipsFlappingURLs(t *testing.T) {
+	skipWithoutMongo(t)
+	clearTaskCollections()
+	defer clearTaskCollections()
+
+	datastore := db.New()
+	userID := db.GenerateObjectID().Hex()
+	integration := datastore.AddIntegration(forms.IntegrationForm{
+		ID:     db.GenerateObjectID().Hex(),
+		UserID: userID,
+		Type:   db.EmailIntegration,
+		Email:  "ops@example.com",
+	})
+
+	now := time.Now()
+	monitorURL := addTestDownMonitorURL(datastore, userID, now.Add(-time.Hour))
+	datastore.UpdateMonitoringURLFlapping(monitorURL.ID, true)
+
+	sendReminders(now)
+
+	if notifications := datastore.GetNotificationsByIntegrationID(userID, integration.ID, 10); len(notifications) != 0 {
+		t.Errorf("expected no reminder of a flapping url, got %d", len(notifications))
+	}
+}
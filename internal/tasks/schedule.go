@@ -0,0 +1,49 @@
+package tasks
+
+import (
+	"hash/fnv"
+	"time"
+
+	"github.com/defraglabs/uptime/internal/db"
+)
+
+// isDue checks if the monitor url should be checked at t.
+// Urls which were never scheduled are due right away.
+func isDue(monitorURL db.MonitorURL, t time.Time) bool {
+	return !t.Before(monitorURL.NextCheckAt)
+}
+
+// getCheckOffset returns a stable offset within the interval derived from the
+// monitor url id. It spreads the checks evenly instead of running all the urls
+// with the same interval on the same second.
+func getCheckOffset(monitorURLID string, interval time.Duration) time.Duration {
+	seconds := int64(interval / time.Second)
+	if seconds <= 1 {
+		return 0
+	}
+
+	hash := fnv.New32a()
+	hash.Write([]byte(monitorURLID))
+
+	return time.Duration(int64(hash.Sum32())%seconds) * time.Second
+}
+
+// getNextCheckAt returns when the monitor url should be checked after the run at t,
+// along with the number of runs which were missed since the scheduled one.
+// Runs are aligned on the monitor url's offset so the url keeps its exact cadence.
+// Missed runs are not replayed, the url is checked once and goes back to its cadence.
+func getNextCheckAt(monitorURL db.MonitorURL, interval time.Duration, t time.Time) (time.Time, int64) {
+	offset := getCheckOffset(monitorURL.ID, interval)
+
+	// Slots are counted from the unix epoch, shifted by the offset.
+	elapsed := t.Sub(time.Unix(0, 0).Add(offset))
+	slot := elapsed / interval
+	nextCheckAt := time.Unix(0, 0).Add(offset).Add((slot + 1) * interval)
+
+	var missed int64
+	if !monitorURL.NextCheckAt.IsZero() {
+		missed = int64(t.Sub(monitorURL.NextCheckAt) / interval)
+	}
+
+	return nextCheckAt, missed
+}
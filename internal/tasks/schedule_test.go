@@ -0,0 +1,138 @@
+package tasks
+
+import (
+	"testing"
+	"time"
+
+	"github.com/defraglabs/uptime/internal/db"
+	"github.com/defraglabs/uptime/internal/utils"
+)
+
+func TestIsDue(t *testing.T) {
+	now := time.Date(2019, 1, 2, 15, 4, 5, 0, time.UTC)
+
+	cases := []struct {
+		name        string
+		nextCheckAt time.Time
+		due         bool
+	}{
+		{"never scheduled", time.Time{}, true},
+		{"scheduled now", now, true},
+		{"scheduled before", now.Add(-time.Minute), true},
+		{"scheduled after", now.Add(time.Second), false},
+	}
+
+	for _, c := range cases {
+		if due := isDue(db.MonitorURL{NextCheckAt: c.nextCheckAt}, now); due != c.due {
+			t.Errorf("%s: expected due %v, got %v", c.name, c.due, due)
+		}
+	}
+}
+
+func TestGetNextCheckAtKeepsCadence(t *testing.T) {
+	monitorURL := db.MonitorURL{ID: "5c2c1b8e9f1b2a0001a1b2c3"}
+	interval := utils.GetMonitoringInterval(5, utils.MINUTE)
+	offset := getCheckOffset(monitorURL.ID, interval)
+
+	now := time.Date(2019, 1, 2, 15, 4, 5, 0, time.UTC)
+	nextCheckAt, missed := getNextCheckAt(monitorURL, interval, now)
+
+	if !nextCheckAt.After(now) || nextCheckAt.Sub(now) > interval {
+		t.Errorf("expected the next check within %s of %s, got %s", interval, now, nextCheckAt)
+	}
+	if nextCheckAt.Sub(time.Unix(0, 0).Add(offset))%interval != 0 {
+		t.Errorf("expected %s to be aligned on the offset %s", nextCheckAt, offset)
+	}
+	if missed != 0 {
+		t.Errorf("expected no missed run for a url never scheduled, got %d", missed)
+	}
+
+	// Checked on time, the url moves on to the next slot.
+	monitorURL.NextCheckAt = nextCheckAt
+	following, missed := getNextCheckAt(monitorURL, interval, nextCheckAt)
+	if following.Sub(nextCheckAt) != interval || missed != 0 {
+		t.Errorf("expected the next check %s later without missed runs, got %s & %d", interval, following.Sub(nextCheckAt), missed)
+	}
+}
+
+func TestGetNextCheckAtCatchesUpAfterMissedRuns(t *testing.T) {
+	monitorURL := db.MonitorURL{ID: "5c2c1b8e9f1b2a0001a1b2c3"}
+	interval := utils.GetMonitoringInterval(1, utils.MINUTE)
+
+	scheduled, _ := getNextCheckAt(monitorURL, interval, time.Date(2019, 1, 2, 15, 0, 0, 0, time.UTC))
+	monitorURL.NextCheckAt = scheduled
+
+	// The scheduler was down for 3.5 intervals, the url is checked once & goes back to its cadence.
+	now := scheduled.Add(3*interval + interval/2)
+	nextCheckAt, missed := getNextCheckAt(monitorURL, interval, now)
+
+	if missed != 3 {
+		t.Errorf("expected 3 missed runs, got %d", missed)
+	}
+	if nextCheckAt != scheduled.Add(4*interval) {
+		t.Errorf("expected the next check at %s, got %s", scheduled.Add(4*interval), nextCheckAt)
+	}
+}
+
+func TestGetNextCheckAtWithHourAndDayUnits(t *testing.T) {
+	monitorURL := db.MonitorURL{ID: "5c2c1b8e9f1b2a0001a1b2c3"}
+	now := time.Date(2019, 1, 2, 15, 4, 5, 0, time.UTC)
+
+	for _, unit := range []string{utils.HOUR, utils.DAY} {
+		interval := utils.GetMonitoringInterval(2, unit)
+		offset := getCheckOffset(monitorURL.ID, interval)
+
+		nextCheckAt, _ := getNextCheckAt(monitorURL, interval, now)
+		if !nextCheckAt.After(now) || nextCheckAt.Sub(now) > interval {
+			t.Errorf("%s: expected the next check within %s of %s, got %s", unit, interval, now, nextCheckAt)
+		}
+		if nextCheckAt.Sub(time.Unix(0, 0).Add(offset))%interval != 0 {
+			t.Errorf("%s: expected %s to be aligned on the offset %s", unit, nextCheckAt, offset)
+		}
+	}
+}
+
+func TestGetCheckOffsetIsStableWithinInterval(t *testing.T) {
+	intervals := []time.Duration{
+		utils.GetMonitoringInterval(30, utils.SECOND),
+		utils.GetMonitoringInterval(5, utils.MINUTE),
+		utils.GetMonitoringInterval(1, utils.HOUR),
+		utils.GetMonitoringInterval(1, utils.DAY),
+	}
+	ids := []string{"5c2c1b8e9f1b2a0001a1b2c3", "5c2c1b8e9f1b2a0001a1b2c4", "a", ""}
+
+	for _, interval := range intervals {
+		for _, id := range ids {
+			offset := getCheckOffset(id, interval)
+			if offset < 0 || offset >= interval {
+				t.Errorf("expected the offset of %q within %s, got %s", id, interval, offset)
+			}
+			if offset%time.Second != 0 {
+				t.Errorf("expected a whole number of seconds, got %s", offset)
+			}
+			if again := getCheckOffset(id, interval); again != offset {
+				t.Errorf("expected a stable offset for %q, got %s then %s", id, offset, again)
+			}
+		}
+	}
+}
+
+func TestGetCheckOffsetSpreadsUrls(t *testing.T) {
+	interval := utils.GetMonitoringInterval(5, utils.MINUTE)
+
+	offsets := map[time.Duration]bool{}
+	for i := 0; i < 50; i++ {
+		offsets[getCheckOffset(db.GenerateObjectID().Hex(), interval)] = true
+	}
+
+	// 50 urls on 300 slots, they should not all land on a handful of seconds.
+	if len(offsets) < 25 {
+		t.Errorf("expected the offsets to be spread, got %d distinct offsets for 50 urls", len(offsets))
+	}
+}
+
+func TestGetCheckOffsetOfShortIntervals(t *testing.T) {
+	if offset := getCheckOffset("5c2c1b8e9f1b2a0001a1b2c3", time.Second); offset != 0 {
+		t.Errorf("expected no offset for a 1s interval, got %s", offset)
+	}
+}
@@ -0,0 +1,85 @@
+package tasks
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"net"
+	"time"
+
+	"github.com/defraglabs/uptime/internal/db"
+	"github.com/defraglabs/uptime/internal/utils"
+)
+
+// maxBannerSize is the maximum number of bytes read while waiting for the banner.
+const maxBannerSize = 4096
+
+// checkTCP opens a connection to host:port, optionally sends the payload
+// and matches the reply against the expected banner.
+func checkTCP(ctx context.Context, monitorURL db.MonitorURL) db.MonitorResult {
+	start := time.Now()
+	result := db.MonitorResult{
+		Time: start.UTC().String(),
+	}
+
+	var dialer net.Dialer
+	conn, err := dialer.DialContext(ctx, "tcp", monitorURL.URL)
+	if err != nil {
+		return failedResult(monitorURL, result, "Connection failed", err)
+	}
+	defer conn.Close()
+
+	result.ResponseTime = float64(time.Since(start).Nanoseconds()) / 1000000
+	result.StatusDescription = "Connected"
+	result.Status = utils.StatusUp
+
+	if deadline, ok := ctx.Deadline(); ok {
+		conn.SetDeadline(deadline)
+	}
+
+	if monitorURL.Payload != "" {
+		_, err = conn.Write([]byte(monitorURL.Payload))
+		if err != nil {
+			return failedResult(monitorURL, result, "Payload send failed", err)
+		}
+	}
+
+	if monitorURL.ExpectedBanner != "" && !readBanner(conn, []byte(monitorURL.ExpectedBanner)) {
+		result.Status = utils.StatusDown
+		result.FailedAssertion = fmt.Sprintf("banner doesn't contain %q", monitorURL.ExpectedBanner)
+	}
+
+	if result.Status == utils.StatusUp {
+		response := assertionResponse{
+			responseTime: result.ResponseTime,
+		}
+
+		failedAssertion := evaluateAssertions(monitorURL.Assertions, response)
+		if failedAssertion != "" {
+			result.Status = utils.StatusDown
+			result.FailedAssertion = failedAssertion
+		}
+	}
+
+	return result
+}
+
+// readBanner reads from the connection until the expected banner shows up,
+// the connection is closed or the deadline is reached.
+func readBanner(conn net.Conn, expectedBanner []byte) bool {
+	var banner []byte
+	buffer := make([]byte, 512)
+
+	for len(banner) < maxBannerSize {
+		n, err := conn.Read(buffer)
+		banner = append(banner, buffer[:n]...)
+
+		if bytes.Contains(banner, expectedBanner) {
+			return true
+		} else if err != nil {
+			return false
+		}
+	}
+
+	return false
+}
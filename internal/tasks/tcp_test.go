@@ -0,0 +1,136 @@
+package tasks
+
+import (
+	"bufio"
+	"context"
+	"net"
+	"testing"
+	"time"
+
+	"github.com/defraglabs/uptime/internal/db"
+	"github.com/defraglabs/uptime/internal/utils"
+)
+
+// startTCPStub serves the connections with the handler on a local port. Returns the address
+// & the func stopping it.
+func startTCPStub(t *testing.T, handle func(net.Conn)) (string, func()) {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("unable to listen: %s", err)
+	}
+
+	go func() {
+		for {
+			conn, err := listener.Accept()
+			if err != nil {
+				return
+			}
+
+			go func() {
+				defer conn.Close()
+				handle(conn)
+			}()
+		}
+	}()
+
+	return listener.Addr().String(), func() { listener.Close() }
+}
+
+func checkTestTCP(monitorURL db.MonitorURL, timeout time.Duration) db.MonitorResult {
+	ctx, cancel := context.WithTimeout(context.Background(), timeout)
+	defer cancel()
+
+	return checkTCP(ctx, monitorURL)
+}
+
+func TestCheckTCPConnects(t *testing.T) {
+	address, stop := startTCPStub(t, func(conn net.Conn) {})
+	defer stop()
+
+	result := checkTestTCP(db.MonitorURL{URL: address}, time.Second)
+	if result.Status != utils.StatusUp || result.StatusDescription != "Connected" {
+		t.Errorf("expected UP & connected, got %s: %s", result.Status, result.StatusDescription)
+	}
+}
+
+func TestCheckTCPConnectionFailed(t *testing.T) {
+	// The port of a closed listener refuses the connections.
+	address, stop := startTCPStub(t, func(conn net.Conn) {})
+	stop()
+
+	result := checkTestTCP(db.MonitorURL{URL: address}, time.Second)
+	if result.Status != utils.StatusDown || result.StatusDescription != "Connection failed" {
+		t.Errorf("expected DOWN as the connection failed, got %s: %s", result.Status, result.StatusDescription)
+	}
+}
+
+func TestCheckTCPSendsPayloadAndMatchesBanner(t *testing.T) {
+	payloads := make(chan string, 1)
+	address, stop := startTCPStub(t, func(conn net.Conn) {
+		line, _ := bufio.NewReader(conn).ReadString('\n')
+		payloads <- line
+		if line == "PING\r\n" {
+			conn.Write([]byte("+PONG\r\n"))
+		}
+	})
+	defer stop()
+
+	monitorURL := db.MonitorURL{URL: address, Payload: "PING\r\n", ExpectedBanner: "+PONG"}
+	result := checkTestTCP(monitorURL, time.Second)
+	if result.Status != utils.StatusUp {
+		t.Errorf("expected UP, got %s: %s", result.Status, result.FailedAssertion)
+	}
+
+	select {
+	case payload := <-payloads:
+		if payload != "PING\r\n" {
+			t.Errorf("expected the payload to be sent, got %q", payload)
+		}
+	case <-time.After(time.Second):
+		t.Errorf("expected the payload to be received")
+	}
+}
+
+func TestCheckTCPBannerAcrossWrites(t *testing.T) {
+	address, stop := startTCPStub(t, func(conn net.Conn) {
+		conn.Write([]byte("SSH-"))
+		time.Sleep(10 * time.Millisecond)
+		conn.Write([]byte("2.0-OpenSSH_9.6\r\n"))
+	})
+	defer stop()
+
+	result := checkTestTCP(db.MonitorURL{URL: address, ExpectedBanner: "SSH-2.0"}, time.Second)
+	if result.Status != utils.StatusUp {
+		t.Errorf("expected UP, got %s: %s", result.Status, result.FailedAssertion)
+	}
+}
+
+func TestCheckTCPBannerMismatch(t *testing.T) {
+	address, stop := startTCPStub(t, func(conn net.Conn) {
+		conn.Write([]byte("220 smtp.example.com ESMTP\r\n"))
+	})
+	defer stop()
+
+	result := checkTestTCP(db.MonitorURL{URL: address, ExpectedBanner: "SSH-2.0"}, time.Second)
+	if result.Status != utils.StatusDown || result.FailedAssertion != `banner doesn't contain "SSH-2.0"` {
+		t.Errorf("expected DOWN as the banner differs, got %s: %s", result.Status, result.FailedAssertion)
+	}
+}
+
+func TestCheckTCPBannerTimeout(t *testing.T) {
+	release := make(chan struct{})
+	address, stop := startTCPStub(t, func(conn net.Conn) {
+		<-release
+	})
+	defer stop()
+	defer close(release)
+
+	start := time.Now()
+	result := checkTestTCP(db.MonitorURL{URL: address, ExpectedBanner: "SSH-2.0"}, 200*time.Millisecond)
+	if result.Status != utils.StatusDown || result.FailedAssertion == "" {
+		t.Errorf("expected DOWN without banner, got %s: %s", result.Status, result.FailedAssertion)
+	}
+	if elapsed := time.Since(start); elapsed > time.Second {
+		t.Errorf("expected the check to stop at the timeout, took %s", elapsed)
+	}
+}
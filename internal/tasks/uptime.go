@@ -1,8 +1,6 @@
 package tasks
 
 import (
-	"fmt"
-	"net/http"
 	"time"
 
 	"github.com/defraglabs/uptime/internal/db"
@@ -10,18 +8,31 @@ import (
 	log "github.com/sirupsen/logrus"
 )
 
-func pingURL(t time.Time) {
+// tickInterval is how often the scheduler looks for monitor urls to check.
+const tickInterval = 1 * time.Second
+
+func pingURL(pool *workerPool, t time.Time) {
 	datastore := db.New()
 	monitoringURLS := datastore.GetMonitoringURLS()
+	maintenanceWindows := datastore.GetMaintenanceWindows(t)
 
-	currentTime := time.Now()
-	if int32(currentTime.Minute()*60+currentTime.Second())%(5*60) == 0 {
+	if int32(t.Minute()*60+t.Second())%(5*60) == 0 {
 		log.Infof("Start pinging urls. Total urls %d", len(monitoringURLS))
 	}
 
 	for _, monitorURL := range monitoringURLS {
-		url := fmt.Sprintf("%s://%s", monitorURL.Protocol, monitorURL.URL)
-		start := currentTime
+		maintenanceWindow := db.GetActiveMaintenanceWindow(maintenanceWindows, monitorURL, t)
+
+		if monitorURL.MonitoringStatus == db.MonitoringStatusPaused {
+			log.Infof("Monitoring paused for url %s", monitorURL.URL)
+			continue
+		} else if maintenanceWindow.Action == utils.MaintenanceActionSkipChecks {
+			skipMaintenanceCheck(datastore, monitorURL, t)
+			continue
+		} else if monitorURL.Type == utils.MonitorTypeHeartbeat {
+			scheduleHeartbeat(pool, datastore, monitorURL, t)
+			continue
+		}
 
 		// Validate if the provided frequency and units are valid.
 		if val, ok := utils.MonitoringConfig[monitorURL.Unit]; ok {
@@ -33,97 +44,82 @@ func pingURL(t time.Time) {
 			continue
 		}
 
-		if monitorURL.MonitoringStatus == db.MonitoringStatusPaused {
-			log.Infof("Monitoring paused for url %s", monitorURL.URL)
-			continue
-		} else if monitorURL.Unit == utils.MINUTE && int32(currentTime.Minute()*60+currentTime.Second())%(monitorURL.Frequency*60) != 0 {
-			continue
-		} else if monitorURL.Unit == utils.SECOND && int32(currentTime.Second())%monitorURL.Frequency != 0 {
+		interval := utils.GetMonitoringInterval(monitorURL.Frequency, monitorURL.Unit)
+		if interval <= 0 {
 			continue
 		}
 
-		resp, err := http.Get(url)
-		responseTime := time.Since(start)
-		timeStamp := currentTime.UTC().String()
+		if !isDue(monitorURL, t) {
+			continue
+		}
 
-		var serviceStatus string
-		var statusCode string
-		var responseTimeInMillSeconds float64
+		nextCheckAt, missed := getNextCheckAt(monitorURL, interval, t)
+		if missed > 0 {
+			log.Warnf("Catching up on url %s, missed %d runs", monitorURL.URL, missed)
+		}
 
+		// When the check can't be queued the schedule is left as is,
+		// so the url is picked again on the next tick.
+		err := pool.submit(monitorURL)
 		if err != nil {
-			// Don't fail like this.
-			log.Warn("API ping failed")
-			serviceStatus = utils.StatusDown
-			responseTimeInMillSeconds = 0.0
-			statusCode = "500 Server error"
-		} else {
-			serviceStatus = utils.GetServiceStatus(resp.StatusCode)
-
-			responseTimeInMillSeconds = float64(responseTime.Nanoseconds()) / 1000000
-			statusCode = resp.Status
-
+			log.Warnf("Skipping check for url %s: %s", monitorURL.URL, err)
+			continue
 		}
 
-		notify := shouldNotify(monitorURL, serviceStatus)
-		if notify {
-			sendAlertNotification(monitorURL, serviceStatus)
-		}
+		datastore.UpdateMonitoringURLSchedule(monitorURL.ID, t, nextCheckAt)
+	}
 
-		datastore.AddMonitorDetail(monitorURL, statusCode, serviceStatus, timeStamp, responseTimeInMillSeconds)
+	// The ticker drops ticks when we fall behind, so report it.
+	elapsed := time.Since(t)
+	if elapsed > tickInterval {
+		log.Warnf("Scheduler tick %s overran by %s", t.UTC(), elapsed-tickInterval)
 	}
 }
 
+// checkMonitorURL checks a single monitor url, records the result and sends the alerts.
+func checkMonitorURL(monitorURL db.MonitorURL) {
+	result := CheckMonitorURL(monitorURL)
+
+	checkCertificateExpiry(monitorURL, result)
+
+	RecordMonitorResult(monitorURL, result)
+}
+
 // shouldNotify checks if a notification has to be sent.
-func shouldNotify(monitorURL db.MonitorURL, serviceStatus string) bool {
-	datastore := db.New()
-	results := datastore.GetLastNMonitoringURLStats(monitorURL.ID, 1)
-	if len(results) == 1 {
-		latestResult := results[0]
-		if latestResult.Status != serviceStatus {
-			if serviceStatus == utils.StatusUp {
-				return true
-			} else if serviceStatus == utils.StatusDown {
-				return true
-			}
+// The first check of a monitor url, without previous status, doesn't notify.
+func shouldNotify(previousStatus, serviceStatus string) bool {
+	if previousStatus != "" && previousStatus != serviceStatus {
+		if serviceStatus == utils.StatusUp {
+			return true
+		} else if serviceStatus == utils.StatusDown {
+			return true
 		}
 	}
 
 	return false
 }
 
-// sendAlertNotification sends a notification through all the configured integrations
-func sendAlertNotification(monitorURL db.MonitorURL, serviceStatus string) {
-	userID := monitorURL.UserID
+// StartScheduler runs the scheduler
+func StartScheduler() {
+	concurrency := utils.GetSchedulerConcurrency()
+	log.Infof("Starting scheduler with %d workers", concurrency)
 
-	datastore := db.New()
-	userIntegrations := datastore.GetIntegrationsByUserID(userID)
+	pool := newWorkerPool(concurrency, checkMonitorURL)
 
-	for _, integration := range userIntegrations {
-		integration.Send(monitorURL, serviceStatus)
-	}
-}
+	// Only one of the replicas runs the checks at a time.
+	isLeader := startLeaderElection()
+	go startDispatcher(isLeader)
+	go startEscalations(isLeader)
+	go startReminders(isLeader)
 
-// StartScheduler runs the scheduler
-func StartScheduler() {
-	log.Info("Starting scheduler")
-	c := make(chan time.Time)
-	go func() {
-		var frequency = time.Duration(1 * time.Second)
-		ticker := time.Tick(frequency)
-
-		for {
-			time.Sleep(time.Duration(1 * time.Second))
-			c <- <-ticker
-		}
-	}()
-
-	for {
-		select {
-		case t := <-c:
-			pingURL(t)
-		case <-time.After(time.Duration(45 * time.Minute)):
-			// This case acts as a timeout.
-			log.Info("Task timeout.")
+	ticker := time.NewTicker(tickInterval)
+	defer ticker.Stop()
+
+	for t := range ticker.C {
+		if !isLeader() {
+			continue
 		}
+
+		pingURL(pool, t)
 	}
 }
@@ -0,0 +1,28 @@
+package tasks
+
+import (
+	"testing"
+
+	"github.com/defraglabs/uptime/internal/utils"
+)
+
+func TestShouldNotify(t *testing.T) {
+	cases := []struct {
+		previousStatus string
+		status         string
+		expected       bool
+	}{
+		{"", utils.StatusDown, false},
+		{"", utils.StatusUp, false},
+		{utils.StatusUp, utils.StatusDown, true},
+		{utils.StatusDown, utils.StatusUp, true},
+		{utils.StatusUp, utils.StatusUp, false},
+		{utils.StatusDown, utils.StatusDown, false},
+	}
+
+	for _, c := range cases {
+		if notify := shouldNotify(c.previousStatus, c.status); notify != c.expected {
+			t.Errorf("%q to %q: expected %v, got %v", c.previousStatus, c.status, c.expected, notify)
+		}
+	}
+}
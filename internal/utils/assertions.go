@@ -0,0 +1,157 @@
+package utils
+
+import (
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+const (
+	// AssertionContains checks that the response body contains the value.
+	AssertionContains = "contains"
+
+	// AssertionNotContains checks that the response body doesn't contain the value.
+	AssertionNotContains = "notContains"
+
+	// AssertionRegex checks that the response body matches the regex in value.
+	AssertionRegex = "regex"
+
+	// AssertionJSONPath compares the value at the json path in property.
+	AssertionJSONPath = "jsonPath"
+
+	// AssertionHeader compares the response header named in property.
+	AssertionHeader = "header"
+
+	// AssertionResponseTime checks that the response time is below value milliseconds.
+	AssertionResponseTime = "responseTime"
+)
+
+const (
+	// ComparisonEquals compares for equality.
+	ComparisonEquals = "equals"
+
+	// ComparisonNotEquals compares for inequality.
+	ComparisonNotEquals = "notEquals"
+
+	// ComparisonContains checks for a substring.
+	ComparisonContains = "contains"
+
+	// ComparisonGreaterThan compares numbers.
+	ComparisonGreaterThan = "greaterThan"
+
+	// ComparisonLessThan compares numbers.
+	ComparisonLessThan = "lessThan"
+)
+
+// AssertionTypes lists the valid assertion types.
+var AssertionTypes = []string{
+	AssertionContains,
+	AssertionNotContains,
+	AssertionRegex,
+	AssertionJSONPath,
+	AssertionHeader,
+	AssertionResponseTime,
+}
+
+// Comparisons lists the valid comparisons for json path & header assertions.
+var Comparisons = []string{
+	ComparisonEquals,
+	ComparisonNotEquals,
+	ComparisonContains,
+	ComparisonGreaterThan,
+	ComparisonLessThan,
+}
+
+// StringInList checks if the given string exists in list.
+func StringInList(a string, list []string) bool {
+	for _, b := range list {
+		if b == a {
+			return true
+		}
+	}
+	return false
+}
+
+// Compare compares actual with expected using the given comparison.
+// greaterThan & lessThan expect both values to be numbers.
+func Compare(actual, comparison, expected string) (bool, error) {
+	switch comparison {
+	case ComparisonEquals:
+		return actual == expected, nil
+	case ComparisonNotEquals:
+		return actual != expected, nil
+	case ComparisonContains:
+		return strings.Contains(actual, expected), nil
+	case ComparisonGreaterThan, ComparisonLessThan:
+		actualNumber, err := strconv.ParseFloat(actual, 64)
+		if err != nil {
+			return false, fmt.Errorf("%s is not a number", actual)
+		}
+
+		expectedNumber, err := strconv.ParseFloat(expected, 64)
+		if err != nil {
+			return false, fmt.Errorf("%s is not a number", expected)
+		}
+
+		if comparison == ComparisonGreaterThan {
+			return actualNumber > expectedNumber, nil
+		}
+		return actualNumber < expectedNumber, nil
+	}
+
+	return false, fmt.Errorf("invalid comparison %s", comparison)
+}
+
+// GetJSONPathValue returns the value found at path in the json body as a string.
+// Path is made of keys & array indexes separated by dots. A leading `$.` is optional.
+// Example:
+//
+//	$.data.items[0].healthy
+func GetJSONPathValue(body []byte, path string) (string, error) {
+	var value interface{}
+	err := json.Unmarshal(body, &value)
+	if err != nil {
+		return "", fmt.Errorf("response is not valid json")
+	}
+
+	path = strings.TrimPrefix(strings.TrimPrefix(path, "$"), ".")
+	if path != "" {
+		// Turn `items[0]` into `items.0` so that every part is a key or an index.
+		path = strings.Replace(strings.Replace(path, "[", ".", -1), "]", "", -1)
+
+		for _, key := range strings.Split(path, ".") {
+			switch node := value.(type) {
+			case map[string]interface{}:
+				child, ok := node[key]
+				if !ok {
+					return "", fmt.Errorf("key %s not found", key)
+				}
+				value = child
+			case []interface{}:
+				index, err := strconv.Atoi(key)
+				if err != nil || index < 0 || index >= len(node) {
+					return "", fmt.Errorf("index %s not found", key)
+				}
+				value = node[index]
+			default:
+				return "", fmt.Errorf("key %s not found", key)
+			}
+		}
+	}
+
+	switch node := value.(type) {
+	case string:
+		return node, nil
+	case nil:
+		return "null", nil
+	case float64:
+		return strconv.FormatFloat(node, 'f', -1, 64), nil
+	case bool:
+		return strconv.FormatBool(node), nil
+	}
+
+	// Objects & arrays are compared with their json representation.
+	encoded, _ := json.Marshal(value)
+	return string(encoded), nil
+}
@@ -0,0 +1,88 @@
+package utils
+
+import "testing"
+
+func TestGetJSONPathValue(t *testing.T) {
+	body := []byte(`{
+		"status": "ok",
+		"healthy": true,
+		"count": 42,
+		"ratio": 0.5,
+		"missing": null,
+		"data": {"items": [{"name": "db", "up": true}, {"name": "cache", "up": false}], "tags": ["a", "b"]}
+	}`)
+
+	cases := []struct {
+		path     string
+		expected string
+		err      bool
+	}{
+		{"$.status", "ok", false},
+		{"status", "ok", false},
+		{".status", "ok", false},
+		{"$.healthy", "true", false},
+		{"$.count", "42", false},
+		{"$.ratio", "0.5", false},
+		{"$.missing", "null", false},
+		{"$.data.items[0].name", "db", false},
+		{"$.data.items[1].up", "false", false},
+		{"$.data.items.1.name", "cache", false},
+		{"$.data.tags", `["a","b"]`, false},
+		{"$.data.items[0]", `{"name":"db","up":true}`, false},
+		{"$.unknown", "", true},
+		{"$.data.items[2].name", "", true},
+		{"$.data.items[-1]", "", true},
+		{"$.data.items[x]", "", true},
+		{"$.status.value", "", true},
+	}
+
+	for _, c := range cases {
+		value, err := GetJSONPathValue(body, c.path)
+		if c.err && err == nil {
+			t.Errorf("%s: expected an error, got %q", c.path, value)
+		} else if !c.err && (err != nil || value != c.expected) {
+			t.Errorf("%s: expected %q, got %q (%v)", c.path, c.expected, value, err)
+		}
+	}
+}
+
+func TestGetJSONPathValueOfWholeBody(t *testing.T) {
+	if value, err := GetJSONPathValue([]byte(`"up"`), "$"); err != nil || value != "up" {
+		t.Errorf("expected the whole body, got %q (%v)", value, err)
+	}
+
+	if _, err := GetJSONPathValue([]byte(`<html>`), "$.status"); err == nil {
+		t.Errorf("expected an error for a body which is not json")
+	}
+}
+
+func TestCompare(t *testing.T) {
+	cases := []struct {
+		actual     string
+		comparison string
+		expected   string
+		ok         bool
+		err        bool
+	}{
+		{"ok", ComparisonEquals, "ok", true, false},
+		{"ok", ComparisonEquals, "OK", false, false},
+		{"ok", ComparisonNotEquals, "down", true, false},
+		{"ok", ComparisonNotEquals, "ok", false, false},
+		{"all systems ok", ComparisonContains, "ok", true, false},
+		{"all systems ok", ComparisonContains, "down", false, false},
+		{"10", ComparisonGreaterThan, "9", true, false},
+		{"10", ComparisonGreaterThan, "10", false, false},
+		{"9.5", ComparisonLessThan, "10", true, false},
+		{"-1", ComparisonLessThan, "-2", false, false},
+		{"ten", ComparisonGreaterThan, "9", false, true},
+		{"10", ComparisonLessThan, "nine", false, true},
+		{"10", "between", "9", false, true},
+	}
+
+	for _, c := range cases {
+		ok, err := Compare(c.actual, c.comparison, c.expected)
+		if c.err != (err != nil) || ok != c.ok {
+			t.Errorf("%s %s %s: expected %v (error %v), got %v (%v)", c.actual, c.comparison, c.expected, c.ok, c.err, ok, err)
+		}
+	}
+}
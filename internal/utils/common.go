@@ -1,5 +1,11 @@
 package utils
 
+import (
+	"fmt"
+	"strings"
+	"time"
+)
+
 const (
 	// StatusUp service status up
 	StatusUp = "UP"
@@ -8,6 +14,77 @@ const (
 	StatusDown = "DOWN"
 )
 
+const (
+	// MonitorTypeHTTP checks a url over http/https. It is the default type.
+	MonitorTypeHTTP = "http"
+
+	// MonitorTypeTCP checks that a connection can be opened to host:port.
+	MonitorTypeTCP = "tcp"
+
+	// MonitorTypeDNS resolves a dns record & compares the answers.
+	MonitorTypeDNS = "dns"
+
+	// MonitorTypeHeartbeat expects pings from a job instead of checking it.
+	MonitorTypeHeartbeat = "heartbeat"
+)
+
+// MonitorTypes lists the valid monitor types.
+var MonitorTypes = []string{
+	MonitorTypeHTTP,
+	MonitorTypeTCP,
+	MonitorTypeDNS,
+	MonitorTypeHeartbeat,
+}
+
+const (
+	// SeverityCritical is the severity of a monitor url whose failures need immediate attention.
+	SeverityCritical = "critical"
+
+	// SeverityError is the severity of a monitor url whose failures need attention.
+	SeverityError = "error"
+
+	// SeverityWarning is the severity of a monitor url whose failures can wait.
+	SeverityWarning = "warning"
+
+	// SeverityInfo is the severity of a monitor url whose failures are informational.
+	SeverityInfo = "info"
+)
+
+// Severities lists the valid severities, as understood by pagerduty.
+var Severities = []string{
+	SeverityCritical,
+	SeverityError,
+	SeverityWarning,
+	SeverityInfo,
+}
+
+// DNSRecordTypes lists the records a dns monitor can resolve.
+var DNSRecordTypes = []string{"A", "AAAA", "CNAME", "MX", "TXT"}
+
+// FormatDuration formats a duration in days, hours & minutes, e.g. `1d 2h 5m`.
+// Durations under a minute are formatted in seconds.
+func FormatDuration(duration time.Duration) string {
+	if duration < time.Minute {
+		return fmt.Sprintf("%ds", int64(duration/time.Second))
+	}
+
+	minutes := int64(duration / time.Minute)
+	days, hours, minutes := minutes/(24*60), minutes/60%24, minutes%60
+
+	parts := []string{}
+	if days > 0 {
+		parts = append(parts, fmt.Sprintf("%dd", days))
+	}
+	if hours > 0 {
+		parts = append(parts, fmt.Sprintf("%dh", hours))
+	}
+	if minutes > 0 {
+		parts = append(parts, fmt.Sprintf("%dm", minutes))
+	}
+
+	return strings.Join(parts, " ")
+}
+
 // GetServiceStatus returns StatusUp or StatusDown depending
 // on the response status code.
 func GetServiceStatus(responseStatusCode int) string {
@@ -0,0 +1,31 @@
+package utils
+
+import (
+	"testing"
+	"time"
+)
+
+func TestFormatDuration(t *testing.T) {
+	cases := []struct {
+		duration time.Duration
+		expected string
+	}{
+		{0, "0s"},
+		{1500 * time.Millisecond, "1s"},
+		{59 * time.Second, "59s"},
+		{time.Minute, "1m"},
+		{90 * time.Second, "1m"},
+		{time.Hour, "1h"},
+		{time.Hour + 5*time.Minute, "1h 5m"},
+		{24 * time.Hour, "1d"},
+		{24*time.Hour + 59*time.Second, "1d"},
+		{50*time.Hour + 30*time.Minute, "2d 2h 30m"},
+		{48*time.Hour + 7*time.Minute, "2d 7m"},
+	}
+
+	for _, c := range cases {
+		if formatted := FormatDuration(c.duration); formatted != c.expected {
+			t.Errorf("%s: expected %q, got %q", c.duration, c.expected, formatted)
+		}
+	}
+}
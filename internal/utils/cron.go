@@ -0,0 +1,156 @@
+package utils
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// cronMacros are the supported shorthands for common cron expressions.
+var cronMacros = map[string]string{
+	"@hourly":  "0 * * * *",
+	"@daily":   "0 0 * * *",
+	"@weekly":  "0 0 * * 0",
+	"@monthly": "0 0 1 * *",
+	"@yearly":  "0 0 1 1 *",
+}
+
+// cronSearchLimit bounds the search for the next run, for expressions like `0 0 30 2 *`
+// which never match.
+const cronSearchLimit = 5 * 366 * 24 * time.Hour
+
+// CronSchedule is a parsed standard cron expression with the fields
+// minute, hour, day of month, month & day of week.
+type CronSchedule struct {
+	minutes  uint64
+	hours    uint64
+	days     uint64
+	months   uint64
+	weekdays uint64
+
+	// Day of month & day of week match when either matches, unless one of them is `*`.
+	anyDay     bool
+	anyWeekday bool
+}
+
+// ParseCron parses a 5 field cron expression. Fields support `*`, lists,
+// ranges & steps, e.g. `*/15 9-17 * * 1-5`. Day of week 0 & 7 are sunday.
+func ParseCron(expression string) (*CronSchedule, error) {
+	if macro, ok := cronMacros[strings.TrimSpace(expression)]; ok {
+		expression = macro
+	}
+
+	fields := strings.Fields(expression)
+	if len(fields) != 5 {
+		return nil, fmt.Errorf("cron expression should have 5 fields")
+	}
+
+	schedule := &CronSchedule{
+		anyDay:     fields[2] == "*",
+		anyWeekday: fields[4] == "*",
+	}
+
+	var err error
+	if schedule.minutes, err = parseCronField(fields[0], 0, 59); err != nil {
+		return nil, err
+	} else if schedule.hours, err = parseCronField(fields[1], 0, 23); err != nil {
+		return nil, err
+	} else if schedule.days, err = parseCronField(fields[2], 1, 31); err != nil {
+		return nil, err
+	} else if schedule.months, err = parseCronField(fields[3], 1, 12); err != nil {
+		return nil, err
+	} else if schedule.weekdays, err = parseCronField(fields[4], 0, 7); err != nil {
+		return nil, err
+	}
+
+	// 7 is sunday as well.
+	if schedule.weekdays&(1<<7) != 0 {
+		schedule.weekdays |= 1
+	}
+
+	return schedule, nil
+}
+
+// parseCronField returns the bitset of the values matched by the field.
+func parseCronField(field string, min, max int) (uint64, error) {
+	var bits uint64
+
+	for _, part := range strings.Split(field, ",") {
+		step := 1
+		if i := strings.Index(part, "/"); i != -1 {
+			var err error
+			step, err = strconv.Atoi(part[i+1:])
+			if err != nil || step <= 0 {
+				return 0, fmt.Errorf("invalid step in %s", field)
+			}
+			part = part[:i]
+		}
+
+		start, end := min, max
+		if part != "*" {
+			bounds := strings.SplitN(part, "-", 2)
+
+			var err error
+			start, err = strconv.Atoi(bounds[0])
+			if err != nil {
+				return 0, fmt.Errorf("invalid value in %s", field)
+			}
+
+			end = start
+			if len(bounds) == 2 {
+				end, err = strconv.Atoi(bounds[1])
+				if err != nil {
+					return 0, fmt.Errorf("invalid range in %s", field)
+				}
+			} else if step > 1 {
+				// `5/15` means every 15 starting at 5.
+				end = max
+			}
+		}
+
+		if start < min || end > max || start > end {
+			return 0, fmt.Errorf("%s is out of range %d-%d", field, min, max)
+		}
+
+		for value := start; value <= end; value += step {
+			bits |= 1 << uint(value)
+		}
+	}
+
+	return bits, nil
+}
+
+func (schedule *CronSchedule) matchesDay(t time.Time) bool {
+	dayMatches := schedule.days&(1<<uint(t.Day())) != 0
+	weekdayMatches := schedule.weekdays&(1<<uint(t.Weekday())) != 0
+
+	if schedule.anyDay || schedule.anyWeekday {
+		return dayMatches && weekdayMatches
+	}
+	return dayMatches || weekdayMatches
+}
+
+// Next returns the first time strictly after t matching the schedule, in the location of t.
+// Returns the zero time if nothing matches within 5 years.
+func (schedule *CronSchedule) Next(t time.Time) time.Time {
+	location := t.Location()
+	t = t.Truncate(time.Minute).Add(time.Minute)
+	limit := t.Add(cronSearchLimit)
+
+	for t.Before(limit) {
+		if schedule.months&(1<<uint(t.Month())) == 0 {
+			t = time.Date(t.Year(), t.Month()+1, 1, 0, 0, 0, 0, location)
+		} else if !schedule.matchesDay(t) {
+			t = time.Date(t.Year(), t.Month(), t.Day()+1, 0, 0, 0, 0, location)
+		} else if schedule.hours&(1<<uint(t.Hour())) == 0 {
+			t = time.Date(t.Year(), t.Month(), t.Day(), t.Hour()+1, 0, 0, 0, location)
+		} else if schedule.minutes&(1<<uint(t.Minute())) == 0 {
+			t = t.Add(time.Minute)
+		} else {
+			return t
+		}
+	}
+
+	return time.Time{}
+}
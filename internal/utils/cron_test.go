@@ -0,0 +1,116 @@
+package utils
+
+import (
+	"testing"
+	"time"
+)
+
+func TestParseCronField(t *testing.T) {
+	cases := []struct {
+		field    string
+		min, max int
+		expected []int
+	}{
+		{"*", 0, 6, []int{0, 1, 2, 3, 4, 5, 6}},
+		{"5", 0, 59, []int{5}},
+		{"1,3,5", 0, 59, []int{1, 3, 5}},
+		{"9-12", 0, 23, []int{9, 10, 11, 12}},
+		{"*/15", 0, 59, []int{0, 15, 30, 45}},
+		{"10-20/5", 0, 59, []int{10, 15, 20}},
+		{"5/20", 0, 59, []int{5, 25, 45}},
+		{"1-2,20-21", 1, 31, []int{1, 2, 20, 21}},
+		{"*/5,7", 1, 12, []int{1, 6, 7, 11}},
+	}
+
+	for _, c := range cases {
+		bits, err := parseCronField(c.field, c.min, c.max)
+		if err != nil {
+			t.Errorf("%s: unexpected error %s", c.field, err)
+			continue
+		}
+
+		var expected uint64
+		for _, value := range c.expected {
+			expected |= 1 << uint(value)
+		}
+		if bits != expected {
+			t.Errorf("%s: expected %v, got %b", c.field, c.expected, bits)
+		}
+	}
+}
+
+func TestParseCronInvalidExpressions(t *testing.T) {
+	expressions := []string{
+		"* * * *",
+		"* * * * * *",
+		"60 * * * *",
+		"* 24 * * *",
+		"* * 0 * *",
+		"* * * 13 *",
+		"* * * * 8",
+		"5-1 * * * *",
+		"*/0 * * * *",
+		"a * * * *",
+		"1-b * * * *",
+		"@every",
+	}
+
+	for _, expression := range expressions {
+		if _, err := ParseCron(expression); err == nil {
+			t.Errorf("expected an error for %q", expression)
+		}
+	}
+}
+
+func TestCronNext(t *testing.T) {
+	// 2024-05-01 is a wednesday.
+	from := time.Date(2024, 5, 1, 10, 7, 30, 0, time.UTC)
+
+	cases := []struct {
+		expression string
+		expected   time.Time
+	}{
+		{"* * * * *", time.Date(2024, 5, 1, 10, 8, 0, 0, time.UTC)},
+		{"*/15 * * * *", time.Date(2024, 5, 1, 10, 15, 0, 0, time.UTC)},
+		{"0 9-17 * * 1-5", time.Date(2024, 5, 1, 11, 0, 0, 0, time.UTC)},
+		{"0 18 * * 1-5", time.Date(2024, 5, 1, 18, 0, 0, 0, time.UTC)},
+		{"30 8 * * 6,0", time.Date(2024, 5, 4, 8, 30, 0, 0, time.UTC)},
+		{"0 0 * * 7", time.Date(2024, 5, 5, 0, 0, 0, 0, time.UTC)},
+		{"0 0 1 * *", time.Date(2024, 6, 1, 0, 0, 0, 0, time.UTC)},
+		{"@hourly", time.Date(2024, 5, 1, 11, 0, 0, 0, time.UTC)},
+		{"@daily", time.Date(2024, 5, 2, 0, 0, 0, 0, time.UTC)},
+		{"@weekly", time.Date(2024, 5, 5, 0, 0, 0, 0, time.UTC)},
+		{"@yearly", time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)},
+		{"0 12 29 2 *", time.Date(2028, 2, 29, 12, 0, 0, 0, time.UTC)},
+		// Day of month & day of week restricted: either matches.
+		{"0 0 10 * 5", time.Date(2024, 5, 3, 0, 0, 0, 0, time.UTC)},
+		{"0 0 2 * 0", time.Date(2024, 5, 2, 0, 0, 0, 0, time.UTC)},
+		// Day of week `*`: only the day of month matches.
+		{"0 0 10 * *", time.Date(2024, 5, 10, 0, 0, 0, 0, time.UTC)},
+		// Day of month `*`: only the day of week matches.
+		{"0 0 * * 5", time.Date(2024, 5, 3, 0, 0, 0, 0, time.UTC)},
+		{"0 0 30 2 *", time.Time{}},
+	}
+
+	for _, c := range cases {
+		schedule, err := ParseCron(c.expression)
+		if err != nil {
+			t.Errorf("%s: unexpected error %s", c.expression, err)
+			continue
+		}
+
+		if next := schedule.Next(from); !next.Equal(c.expected) {
+			t.Errorf("%s: expected %s, got %s", c.expression, c.expected, next)
+		}
+	}
+}
+
+func TestCronNextKeepsTheLocation(t *testing.T) {
+	location := time.FixedZone("UTC+5", 5*60*60)
+	schedule, _ := ParseCron("0 9 * * *")
+
+	next := schedule.Next(time.Date(2024, 5, 1, 10, 0, 0, 0, location))
+	if !next.Equal(time.Date(2024, 5, 2, 9, 0, 0, 0, location)) || next.Location() != location {
+		t.Errorf("expected 09:00 of the next day in UTC+5, got %s", next)
+	}
+}
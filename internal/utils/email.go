@@ -1,76 +1,221 @@
 package utils
 
 import (
+	"bytes"
+	"errors"
 	"fmt"
-	"log"
+	"io"
+	"mime"
+	"mime/multipart"
+	"mime/quotedprintable"
+	"net"
 	"net/smtp"
+	"net/textproto"
 	"os"
+	"time"
 
 	"github.com/aws/aws-sdk-go/aws"
 	"github.com/aws/aws-sdk-go/aws/credentials"
 	"github.com/aws/aws-sdk-go/aws/session"
 	"github.com/aws/aws-sdk-go/service/ses"
+	log "github.com/sirupsen/logrus"
 )
 
-// SendMail sends email to the provided arguments.
-func SendMail(sub, msg, toEmail string) {
-	auth := smtp.CRAMMD5Auth("", "")
-	fromEmail := os.Getenv("FROM_EMAIL")
-	env := os.Getenv("ENV")
-
-	// Connect to the server, authenticate, set the sender and recipient,
-	// and send the email all in one step.
-	to := []string{toEmail}
-	content := fmt.Sprintf("To: %s\r\n"+
-		"Subject: %s\r\n"+
-		"\r\n"+
-		"%s\r\n", toEmail, sub, msg,
-	)
-
-	emailMsg := []byte(content)
-	if env == "local" {
-		err := smtp.SendMail("mailhog:1025", auth, fromEmail, to, emailMsg)
+// localSMTPAddress is the mailhog container used in local development.
+const localSMTPAddress = "mailhog:1025"
+
+// Mail is an email with a plain text and an html version of the body.
+// Either of them can be empty.
+type Mail struct {
+	To      string
+	Subject string
+	Text    string
+	HTML    string
+}
+
+// MailTransport delivers emails.
+type MailTransport interface {
+	Send(mail Mail) error
+}
+
+// SMTPTransport delivers emails through an smtp server, e.g. mailhog.
+type SMTPTransport struct {
+	Address  string
+	From     string
+	Username string
+	Password string
+}
+
+// Send delivers the mail as a multipart message.
+func (transport SMTPTransport) Send(mail Mail) error {
+	var auth smtp.Auth
+	if transport.Username != "" {
+		host, _, err := net.SplitHostPort(transport.Address)
 		if err != nil {
-			log.Fatal(err)
+			return err
 		}
-	} else {
-		awsSESRegion := os.Getenv("AWS_SES_REGION")
-		awsSESAccessKeyID := os.Getenv("AWS_SES_ACCESS_KEY")
-		awsSESAccessKeySecret := os.Getenv("AWS_SES_ACCESS_SECRET")
+		auth = smtp.PlainAuth("", transport.Username, transport.Password, host)
+	}
 
-		if awsSESRegion == "" || awsSESAccessKeyID == "" || awsSESAccessKeySecret == "" {
-			log.Fatal("AWS SES configuration invalid.")
-		}
+	msg, err := BuildMIMEMessage(transport.From, mail)
+	if err != nil {
+		return err
+	}
 
-		awsSession := session.New(&aws.Config{
-			Region:      aws.String(awsSESRegion),
-			Credentials: credentials.NewStaticCredentials(awsSESAccessKeyID, awsSESAccessKeySecret, ""),
-		})
+	return smtp.SendMail(transport.Address, auth, transport.From, []string{mail.To}, msg)
+}
 
-		sesSession := ses.New(awsSession)
+// SESTransport delivers emails through AWS SES.
+type SESTransport struct {
+	Region          string
+	AccessKeyID     string
+	AccessKeySecret string
+	From            string
+}
 
-		sesEmailInput := &ses.SendEmailInput{
-			Destination: &ses.Destination{
-				ToAddresses: []*string{aws.String(toEmail)},
-			},
-			Message: &ses.Message{
-				Body: &ses.Body{
-					Html: &ses.Content{
-						Data: aws.String(msg)},
-				},
-				Subject: &ses.Content{
-					Data: aws.String(sub),
-				},
-			},
-			Source: aws.String(fromEmail),
-			ReplyToAddresses: []*string{
-				aws.String(fromEmail),
+// Send delivers the mail, SES builds the multipart message from the text & html bodies.
+func (transport SESTransport) Send(mail Mail) error {
+	awsSession := session.New(&aws.Config{
+		Region:      aws.String(transport.Region),
+		Credentials: credentials.NewStaticCredentials(transport.AccessKeyID, transport.AccessKeySecret, ""),
+	})
+
+	sesSession := ses.New(awsSession)
+
+	body := &ses.Body{}
+	if mail.Text != "" {
+		body.Text = &ses.Content{Data: aws.String(mail.Text)}
+	}
+	if mail.HTML != "" {
+		body.Html = &ses.Content{Data: aws.String(mail.HTML)}
+	}
+
+	sesEmailInput := &ses.SendEmailInput{
+		Destination: &ses.Destination{
+			ToAddresses: []*string{aws.String(mail.To)},
+		},
+		Message: &ses.Message{
+			Body: body,
+			Subject: &ses.Content{
+				Data: aws.String(mail.Subject),
 			},
+		},
+		Source: aws.String(transport.From),
+		ReplyToAddresses: []*string{
+			aws.String(transport.From),
+		},
+	}
+
+	_, err := sesSession.SendEmail(sesEmailInput)
+	return err
+}
+
+// GetMailTransport returns the transport configured through the env variables.
+// SMTP_ADDRESS takes precedence, then mailhog when ENV is local, and AWS SES otherwise.
+func GetMailTransport() (MailTransport, error) {
+	fromEmail := os.Getenv("FROM_EMAIL")
+
+	smtpAddress := os.Getenv("SMTP_ADDRESS")
+	if smtpAddress == "" && os.Getenv("ENV") == "local" {
+		smtpAddress = localSMTPAddress
+	}
+
+	if smtpAddress != "" {
+		return SMTPTransport{
+			Address:  smtpAddress,
+			From:     fromEmail,
+			Username: os.Getenv("SMTP_USERNAME"),
+			Password: os.Getenv("SMTP_PASSWORD"),
+		}, nil
+	}
+
+	transport := SESTransport{
+		Region:          os.Getenv("AWS_SES_REGION"),
+		AccessKeyID:     os.Getenv("AWS_SES_ACCESS_KEY"),
+		AccessKeySecret: os.Getenv("AWS_SES_ACCESS_SECRET"),
+		From:            fromEmail,
+	}
+
+	if transport.Region == "" || transport.AccessKeyID == "" || transport.AccessKeySecret == "" {
+		return nil, errors.New("AWS SES configuration invalid")
+	}
+
+	return transport, nil
+}
+
+// BuildMIMEMessage builds the message sent over smtp. With both bodies set it is a
+// multipart/alternative message, the plain text part first as per RFC 2046.
+func BuildMIMEMessage(from string, mail Mail) ([]byte, error) {
+	var msg bytes.Buffer
+
+	fmt.Fprintf(&msg, "From: %s\r\n", from)
+	fmt.Fprintf(&msg, "To: %s\r\n", mail.To)
+	fmt.Fprintf(&msg, "Subject: %s\r\n", mime.QEncoding.Encode("utf-8", mail.Subject))
+	fmt.Fprintf(&msg, "Date: %s\r\n", time.Now().Format(time.RFC1123Z))
+	msg.WriteString("MIME-Version: 1.0\r\n")
+
+	if mail.Text == "" || mail.HTML == "" {
+		contentType, content := "text/plain", mail.Text
+		if mail.HTML != "" {
+			contentType, content = "text/html", mail.HTML
 		}
 
-		_, err := sesSession.SendEmail(sesEmailInput)
+		fmt.Fprintf(&msg, "Content-Type: %s; charset=utf-8\r\n", contentType)
+		msg.WriteString("Content-Transfer-Encoding: quoted-printable\r\n\r\n")
+
+		err := writeQuotedPrintable(&msg, content)
+		return msg.Bytes(), err
+	}
+
+	writer := multipart.NewWriter(&msg)
+	fmt.Fprintf(&msg, "Content-Type: multipart/alternative; boundary=%s\r\n\r\n", writer.Boundary())
+
+	parts := []struct{ contentType, content string }{
+		{"text/plain", mail.Text},
+		{"text/html", mail.HTML},
+	}
+
+	for _, part := range parts {
+		partWriter, err := writer.CreatePart(textproto.MIMEHeader{
+			"Content-Type":              {part.contentType + "; charset=utf-8"},
+			"Content-Transfer-Encoding": {"quoted-printable"},
+		})
 		if err != nil {
-			log.Fatal(err)
+			return nil, err
 		}
+
+		err = writeQuotedPrintable(partWriter, part.content)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	err := writer.Close()
+	return msg.Bytes(), err
+}
+
+func writeQuotedPrintable(w io.Writer, content string) error {
+	encoder := quotedprintable.NewWriter(w)
+	_, err := encoder.Write([]byte(content))
+	if err != nil {
+		return err
 	}
+
+	return encoder.Close()
+}
+
+// SendMail sends email to the provided arguments.
+func SendMail(sub, msg, toEmail string) error {
+	transport, err := GetMailTransport()
+	if err != nil {
+		log.Warnf("Unable to send mail to %s: %s", toEmail, err)
+		return err
+	}
+
+	err = transport.Send(Mail{To: toEmail, Subject: sub, HTML: msg})
+	if err != nil {
+		log.Warnf("Unable to send mail to %s: %s", toEmail, err)
+	}
+
+	return err
 }
@@ -0,0 +1,174 @@
+package utils
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"mime"
+	"mime/multipart"
+	"net"
+	"net/http"
+	"net/mail"
+	"os"
+	"strings"
+	"testing"
+	"time"
+)
+
+type mailhogMessages struct {
+	Items []struct {
+		Content struct {
+			Headers map[string][]string
+		}
+		MIME struct {
+			Parts []struct {
+				Headers map[string][]string
+			}
+		}
+	} `json:"items"`
+}
+
+func getMailhogHost() string {
+	host := os.Getenv("MAILHOG_HOST")
+	if host == "" {
+		host = "mailhog"
+	}
+
+	return host
+}
+
+// skipWithoutMailhog skips the test when mailhog isn't reachable, e.g. outside of docker compose.
+func skipWithoutMailhog(t *testing.T, host string) {
+	conn, err := net.DialTimeout("tcp", fmt.Sprintf("%s:1025", host), time.Second)
+	if err != nil {
+		t.Skipf("mailhog is not reachable on %s, set MAILHOG_HOST: %s", host, err)
+	}
+	conn.Close()
+}
+
+func TestSMTPTransportSendsMultipartMail(t *testing.T) {
+	host := getMailhogHost()
+	skipWithoutMailhog(t, host)
+
+	toEmail := fmt.Sprintf("alerts-%d@example.com", time.Now().UnixNano())
+
+	transport := SMTPTransport{
+		Address: fmt.Sprintf("%s:1025", host),
+		From:    "support@uptime.com",
+	}
+
+	mail := Mail{
+		To:      toEmail,
+		Subject: "Site example.com DOWN",
+		Text:    "Site example.com DOWN",
+		HTML:    "<h2>Site example.com DOWN</h2>",
+	}
+
+	err := transport.Send(mail)
+	if err != nil {
+		t.Fatalf("unable to send mail: %s", err)
+	}
+
+	url := fmt.Sprintf("http://%s:8025/api/v2/search?kind=to&query=%s", host, toEmail)
+	resp, err := http.Get(url)
+	if err != nil {
+		t.Fatalf("unable to fetch mails from mailhog: %s", err)
+	}
+	defer resp.Body.Close()
+
+	messages := mailhogMessages{}
+	json.NewDecoder(resp.Body).Decode(&messages)
+
+	if len(messages.Items) != 1 {
+		t.Fatalf("expected 1 mail, got %d", len(messages.Items))
+	}
+
+	message := messages.Items[0]
+	if !strings.HasPrefix(message.Content.Headers["Content-Type"][0], "multipart/alternative") {
+		t.Errorf("expected a multipart/alternative mail, got %v", message.Content.Headers["Content-Type"])
+	}
+
+	if len(message.MIME.Parts) != 2 {
+		t.Errorf("expected text & html parts, got %d parts", len(message.MIME.Parts))
+	}
+}
+
+func TestBuildMIMEMessageWithTextAndHTML(t *testing.T) {
+	message, err := BuildMIMEMessage("support@uptime.com", Mail{
+		To:      "alerts@example.com",
+		Subject: "Site café.example.com DOWN",
+		Text:    "Site café.example.com DOWN",
+		HTML:    "<h2>Site café.example.com DOWN</h2>",
+	})
+	if err != nil {
+		t.Fatalf("unable to build the message: %s", err)
+	}
+
+	msg, err := mail.ReadMessage(strings.NewReader(string(message)))
+	if err != nil {
+		t.Fatalf("unable to parse the message: %s", err)
+	}
+
+	subject, _ := new(mime.WordDecoder).DecodeHeader(msg.Header.Get("Subject"))
+	if subject != "Site café.example.com DOWN" || msg.Header.Get("To") != "alerts@example.com" {
+		t.Errorf("unexpected subject %q or recipient %q", subject, msg.Header.Get("To"))
+	}
+
+	mediaType, params, err := mime.ParseMediaType(msg.Header.Get("Content-Type"))
+	if err != nil || mediaType != "multipart/alternative" || params["boundary"] == "" {
+		t.Fatalf("expected a multipart/alternative message with a boundary, got %q", msg.Header.Get("Content-Type"))
+	}
+
+	expectedParts := []struct{ contentType, content string }{
+		{"text/plain; charset=utf-8", "Site café.example.com DOWN"},
+		{"text/html; charset=utf-8", "<h2>Site café.example.com DOWN</h2>"},
+	}
+
+	reader := multipart.NewReader(msg.Body, params["boundary"])
+	for _, expected := range expectedParts {
+		part, err := reader.NextPart()
+		if err != nil {
+			t.Fatalf("expected the %s part, got %s", expected.contentType, err)
+		}
+
+		// The reader decodes the quoted-printable parts.
+		content, _ := ioutil.ReadAll(part)
+		if part.Header.Get("Content-Type") != expected.contentType || string(content) != expected.content {
+			t.Errorf("expected %s part %q, got %s part %q", expected.contentType, expected.content, part.Header.Get("Content-Type"), content)
+		}
+	}
+
+	if _, err := reader.NextPart(); err == nil {
+		t.Errorf("expected only the text & html parts")
+	}
+}
+
+func TestBuildMIMEMessageWithASingleBody(t *testing.T) {
+	cases := []struct {
+		mail        Mail
+		contentType string
+	}{
+		{Mail{To: "alerts@example.com", Text: "Site example.com UP"}, "text/plain; charset=utf-8"},
+		{Mail{To: "alerts@example.com", HTML: "<h2>Site example.com UP</h2>"}, "text/html; charset=utf-8"},
+	}
+
+	for _, c := range cases {
+		message, err := BuildMIMEMessage("support@uptime.com", c.mail)
+		if err != nil {
+			t.Fatalf("unable to build the message: %s", err)
+		}
+
+		msg, err := mail.ReadMessage(strings.NewReader(string(message)))
+		if err != nil {
+			t.Fatalf("unable to parse the message: %s", err)
+		}
+
+		if msg.Header.Get("Content-Type") != c.contentType {
+			t.Errorf("expected %s, got %s", c.contentType, msg.Header.Get("Content-Type"))
+		}
+
+		if msg.Header.Get("Content-Transfer-Encoding") != "quoted-printable" {
+			t.Errorf("expected a quoted-printable body, got %s", msg.Header.Get("Content-Transfer-Encoding"))
+		}
+	}
+}
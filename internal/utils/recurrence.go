@@ -0,0 +1,115 @@
+package utils
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// rruleWeekdays maps the RRULE weekdays to the cron ones.
+var rruleWeekdays = map[string]int{
+	"SU": 0,
+	"MO": 1,
+	"TU": 2,
+	"WE": 3,
+	"TH": 4,
+	"FR": 5,
+	"SA": 6,
+}
+
+// ParseRecurrence parses a recurrence, either a 5 field cron expression or an RFC 5545 RRULE,
+// e.g. `FREQ=WEEKLY;BYDAY=TU,TH;BYHOUR=22`. The parts of an RRULE which are not set default
+// to start, like DTSTART would, or to midnight on monday 1 january without start.
+func ParseRecurrence(recurrence string, start time.Time) (*CronSchedule, error) {
+	rule := strings.TrimPrefix(strings.ToUpper(strings.TrimSpace(recurrence)), "RRULE:")
+	if !strings.HasPrefix(rule, "FREQ=") {
+		return ParseCron(recurrence)
+	}
+
+	expression, err := rruleToCron(rule, start)
+	if err != nil {
+		return nil, err
+	}
+
+	return ParseCron(expression)
+}
+
+// rruleToCron converts the subset of RRULE expressible as cron: FREQ (HOURLY, DAILY, WEEKLY,
+// MONTHLY, YEARLY) with BYMINUTE, BYHOUR, BYDAY, BYMONTHDAY & BYMONTH.
+// INTERVAL other than 1, COUNT & UNTIL are not supported.
+func rruleToCron(rule string, start time.Time) (string, error) {
+	parts := make(map[string]string)
+	for _, part := range strings.Split(rule, ";") {
+		keyValue := strings.SplitN(part, "=", 2)
+		if len(keyValue) != 2 {
+			return "", fmt.Errorf("invalid rrule part %s", part)
+		}
+		parts[keyValue[0]] = keyValue[1]
+	}
+
+	if interval, ok := parts["INTERVAL"]; ok && interval != "1" {
+		return "", fmt.Errorf("rrule INTERVAL is not supported")
+	} else if _, ok := parts["COUNT"]; ok {
+		return "", fmt.Errorf("rrule COUNT is not supported")
+	} else if _, ok := parts["UNTIL"]; ok {
+		return "", fmt.Errorf("rrule UNTIL is not supported, set the end of the window instead")
+	}
+
+	if start.IsZero() {
+		// The zero time in the location may have an odd local mean time offset.
+		start = time.Time{}
+	}
+
+	minute := strconv.Itoa(start.Minute())
+	hour := strconv.Itoa(start.Hour())
+	day := "*"
+	month := "*"
+	weekday := "*"
+
+	switch parts["FREQ"] {
+	case "HOURLY":
+		hour = "*"
+	case "DAILY":
+	case "WEEKLY":
+		weekday = strconv.Itoa(int(start.Weekday()))
+	case "MONTHLY":
+		day = strconv.Itoa(start.Day())
+	case "YEARLY":
+		day = strconv.Itoa(start.Day())
+		month = strconv.Itoa(int(start.Month()))
+	default:
+		return "", fmt.Errorf("invalid rrule FREQ %s", parts["FREQ"])
+	}
+
+	if value, ok := parts["BYMINUTE"]; ok {
+		minute = value
+	}
+	if value, ok := parts["BYHOUR"]; ok {
+		hour = value
+	}
+	if value, ok := parts["BYMONTHDAY"]; ok {
+		day = value
+	}
+	if value, ok := parts["BYMONTH"]; ok {
+		month = value
+	}
+
+	if value, ok := parts["BYDAY"]; ok {
+		weekdays := []string{}
+		for _, name := range strings.Split(value, ",") {
+			number, ok := rruleWeekdays[name]
+			if !ok {
+				return "", fmt.Errorf("invalid rrule BYDAY %s", name)
+			}
+			weekdays = append(weekdays, strconv.Itoa(number))
+		}
+
+		weekday = strings.Join(weekdays, ",")
+		if _, ok := parts["BYMONTHDAY"]; !ok {
+			day = "*"
+		}
+	}
+
+	return strings.Join([]string{minute, hour, day, month, weekday}, " "), nil
+}
@@ -0,0 +1,91 @@
+package utils
+
+import (
+	"testing"
+	"time"
+)
+
+func TestRRuleToCron(t *testing.T) {
+	// A tuesday.
+	start := time.Date(2024, 3, 5, 22, 15, 0, 0, time.UTC)
+	paris, _ := time.LoadLocation("Europe/Paris")
+
+	cases := []struct {
+		rule     string
+		start    time.Time
+		expected string
+	}{
+		{"FREQ=HOURLY", start, "15 * * * *"},
+		{"FREQ=DAILY", start, "15 22 * * *"},
+		{"FREQ=DAILY;INTERVAL=1;BYHOUR=3;BYMINUTE=30", start, "30 3 * * *"},
+		{"FREQ=WEEKLY", start, "15 22 * * 2"},
+		{"FREQ=WEEKLY;BYDAY=TU,TH;BYHOUR=23;BYMINUTE=0", start, "0 23 * * 2,4"},
+		{"FREQ=MONTHLY", start, "15 22 5 * *"},
+		{"FREQ=MONTHLY;BYDAY=MO", start, "15 22 * * 1"},
+		{"FREQ=MONTHLY;BYMONTHDAY=1,15;BYDAY=SA", start, "15 22 1,15 * 6"},
+		{"FREQ=YEARLY", start, "15 22 5 3 *"},
+		{"FREQ=YEARLY;BYMONTH=12;BYMONTHDAY=24", start, "15 22 24 12 *"},
+
+		// Without start, the missing parts are midnight on monday 1 january, whatever the location.
+		{"FREQ=DAILY", time.Time{}, "0 0 * * *"},
+		{"FREQ=WEEKLY", time.Time{}.In(paris), "0 0 * * 1"},
+		{"FREQ=YEARLY", time.Time{}.In(paris), "0 0 1 1 *"},
+		{"FREQ=DAILY;BYHOUR=22", time.Time{}.In(paris), "0 22 * * *"},
+	}
+
+	for _, c := range cases {
+		expression, err := rruleToCron(c.rule, c.start)
+		if err != nil {
+			t.Errorf("%s: unexpected error %s", c.rule, err)
+		} else if expression != c.expected {
+			t.Errorf("%s: expected %q, got %q", c.rule, c.expected, expression)
+		}
+	}
+}
+
+func TestParseRecurrence(t *testing.T) {
+	start := time.Date(2024, 3, 5, 22, 15, 0, 0, time.UTC)
+
+	cases := []struct {
+		recurrence string
+		from       time.Time
+		expected   time.Time
+	}{
+		// Cron expressions are parsed as is.
+		{"0 22 * * 2", start, time.Date(2024, 3, 12, 22, 0, 0, 0, time.UTC)},
+		{"FREQ=DAILY", start, time.Date(2024, 3, 6, 22, 15, 0, 0, time.UTC)},
+		{"RRULE:freq=weekly;byday=th;byhour=6", start, time.Date(2024, 3, 7, 6, 15, 0, 0, time.UTC)},
+		{" FREQ=MONTHLY;BYMONTHDAY=1;BYHOUR=0;BYMINUTE=0 ", start, time.Date(2024, 4, 1, 0, 0, 0, 0, time.UTC)},
+	}
+
+	for _, c := range cases {
+		schedule, err := ParseRecurrence(c.recurrence, start)
+		if err != nil {
+			t.Errorf("%s: unexpected error %s", c.recurrence, err)
+			continue
+		}
+
+		if next := schedule.Next(c.from); !next.Equal(c.expected) {
+			t.Errorf("%s: expected %s, got %s", c.recurrence, c.expected, next)
+		}
+	}
+}
+
+func TestParseRecurrenceInvalidRecurrences(t *testing.T) {
+	recurrences := []string{
+		"FREQ=DAILY;INTERVAL=2",
+		"FREQ=DAILY;COUNT=3",
+		"FREQ=DAILY;UNTIL=20250101T000000Z",
+		"FREQ=SECONDLY",
+		"FREQ=WEEKLY;BYDAY=XX",
+		"FREQ=DAILY;BYHOUR",
+		"FREQ=DAILY;BYHOUR=24",
+		"* * *",
+	}
+
+	for _, recurrence := range recurrences {
+		if _, err := ParseRecurrence(recurrence, time.Now()); err == nil {
+			t.Errorf("expected an error for %q", recurrence)
+		}
+	}
+}
@@ -0,0 +1,51 @@
+package utils
+
+import (
+	"net/http"
+	"regexp"
+	"strings"
+)
+
+// MaxCheckTimeout is the maximum timeout in seconds a monitor url can be configured with.
+const MaxCheckTimeout = 60
+
+// HeaderNameRegex matches the characters allowed in a http header name.
+var HeaderNameRegex = regexp.MustCompile("^[!#$%&'*+.^_`|~0-9A-Za-z-]+$")
+
+// HTTPMethods lists the methods a monitor url can be checked with.
+var HTTPMethods = []string{
+	http.MethodGet,
+	http.MethodHead,
+	http.MethodPost,
+	http.MethodPut,
+	http.MethodPatch,
+	http.MethodDelete,
+	http.MethodOptions,
+}
+
+// NewMonitorRequest builds the request used to check a monitor url.
+// Method defaults to GET. Content type & user agent take precedence over the headers.
+func NewMonitorRequest(method, url string, headers map[string]string, body, contentType, userAgent string) (*http.Request, error) {
+	if method == "" {
+		method = http.MethodGet
+	}
+
+	req, err := http.NewRequest(method, url, strings.NewReader(body))
+	if err != nil {
+		return nil, err
+	}
+
+	for name, value := range headers {
+		req.Header.Set(name, value)
+	}
+
+	if contentType != "" {
+		req.Header.Set("Content-Type", contentType)
+	}
+
+	if userAgent != "" {
+		req.Header.Set("User-Agent", userAgent)
+	}
+
+	return req, nil
+}
@@ -1,15 +1,69 @@
 package utils
 
+import (
+	"os"
+	"strconv"
+	"strings"
+	"time"
+)
+
 // MINUTE is the minute const.
 const MINUTE = "minute"
 
 // SECOND is the second const.
 const SECOND = "second"
 
+// HOUR is the hour const.
+const HOUR = "hour"
+
+// DAY is the day const.
+const DAY = "day"
+
+const (
+	// defaultSchedulerConcurrency is the number of checks run in parallel
+	// when SCHEDULER_CONCURRENCY is not set.
+	defaultSchedulerConcurrency = 20
+
+	// defaultCheckTimeout is the deadline of a single check when
+	// CHECK_TIMEOUT_SECONDS is not set.
+	defaultCheckTimeout = 10 * time.Second
+
+	// defaultNotificationMaxAttempts is the number of delivery attempts of a
+	// notification when NOTIFICATION_MAX_ATTEMPTS is not set.
+	defaultNotificationMaxAttempts = 5
+
+	// defaultPhoneRateLimit is the number of sms & calls per phone number per hour
+	// when PHONE_RATE_LIMIT is not set.
+	defaultPhoneRateLimit = 5
+)
+
+// MaxConfirmationThreshold is the maximum number of consecutive checks
+// required to confirm a status change.
+const MaxConfirmationThreshold = 10
+
+// MaxReminderInterval is the maximum interval in minutes between two reminders of an outage.
+const MaxReminderInterval = 24 * 60
+
+// MaxEscalationSteps is the maximum number of steps of an escalation policy.
+const MaxEscalationSteps = 10
+
+// MaxEscalationDelay is the maximum delay in minutes before an incident escalates to the next step.
+const MaxEscalationDelay = 24 * 60
+
 // MonitoringConfig stores the acceptable values for frequency & unit.
 var MonitoringConfig = map[string][]int32{
 	SECOND: []int32{30},
 	MINUTE: []int32{1, 5, 15, 30},
+	HOUR:   []int32{1, 6, 12},
+	DAY:    []int32{1},
+}
+
+// unitDurations maps the MonitoringConfig units to their duration.
+var unitDurations = map[string]time.Duration{
+	SECOND: time.Second,
+	MINUTE: time.Minute,
+	HOUR:   time.Hour,
+	DAY:    24 * time.Hour,
 }
 
 // GetMonitoringConfigKeys returns the keys from monitoringConfig.
@@ -32,3 +86,76 @@ func FrequencyInMonitoringConfig(a int32, list []int32) bool {
 	}
 	return false
 }
+
+// GetMonitoringInterval returns the time between two checks for the given
+// frequency & unit. Returns 0 if the unit is unknown.
+func GetMonitoringInterval(frequency int32, unit string) time.Duration {
+	return time.Duration(frequency) * unitDurations[unit]
+}
+
+// GetSchedulerConcurrency returns the maximum number of checks the scheduler
+// runs at the same time. Configured with SCHEDULER_CONCURRENCY.
+func GetSchedulerConcurrency() int {
+	concurrency, err := strconv.Atoi(os.Getenv("SCHEDULER_CONCURRENCY"))
+	if err != nil || concurrency <= 0 {
+		return defaultSchedulerConcurrency
+	}
+
+	return concurrency
+}
+
+// GetCheckTimeout returns the deadline of a single check.
+// Configured with CHECK_TIMEOUT_SECONDS.
+func GetCheckTimeout() time.Duration {
+	seconds, err := strconv.Atoi(os.Getenv("CHECK_TIMEOUT_SECONDS"))
+	if err != nil || seconds <= 0 {
+		return defaultCheckTimeout
+	}
+
+	return time.Duration(seconds) * time.Second
+}
+
+// GetNotificationMaxAttempts returns how many times the delivery of a notification
+// is attempted before giving up. Configured with NOTIFICATION_MAX_ATTEMPTS.
+func GetNotificationMaxAttempts() int {
+	attempts, err := strconv.Atoi(os.Getenv("NOTIFICATION_MAX_ATTEMPTS"))
+	if err != nil || attempts <= 0 {
+		return defaultNotificationMaxAttempts
+	}
+
+	return attempts
+}
+
+// GetPhoneRateLimit returns how many sms & calls a phone number receives per hour at most.
+// Configured with PHONE_RATE_LIMIT.
+func GetPhoneRateLimit() int {
+	limit, err := strconv.Atoi(os.Getenv("PHONE_RATE_LIMIT"))
+	if err != nil || limit <= 0 {
+		return defaultPhoneRateLimit
+	}
+
+	return limit
+}
+
+// GetDashboardURL returns the url of the dashboard the alerts link to, without trailing slash.
+// Configured with DASHBOARD_URL, empty when not configured.
+func GetDashboardURL() string {
+	return strings.TrimSuffix(os.Getenv("DASHBOARD_URL"), "/")
+}
+
+const (
+	// MaintenanceActionSkipChecks skips the checks of the monitor urls during the window.
+	MaintenanceActionSkipChecks = "skipChecks"
+
+	// MaintenanceActionSuppressAlerts runs the checks but sends no alerts during the window.
+	MaintenanceActionSuppressAlerts = "suppressAlerts"
+)
+
+// MaintenanceActions lists the valid actions of a maintenance window.
+var MaintenanceActions = []string{
+	MaintenanceActionSkipChecks,
+	MaintenanceActionSuppressAlerts,
+}
+
+// MaxMaintenanceDuration is the maximum duration in minutes of a recurring maintenance window.
+const MaxMaintenanceDuration = 7 * 24 * 60
@@ -0,0 +1,35 @@
+package utils
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"text/template"
+)
+
+// WebhookSignatureHeader carries the signature of the webhook body.
+const WebhookSignatureHeader = "X-Uptime-Signature"
+
+// webhookTemplateFuncs are available in the body templates of webhooks.
+// `json` quotes & escapes a value, e.g. `{"text": {{json .Summary}}}`.
+var webhookTemplateFuncs = template.FuncMap{
+	"json": func(value interface{}) (string, error) {
+		encoded, err := json.Marshal(value)
+		return string(encoded), err
+	},
+}
+
+// ParseWebhookTemplate parses the body template of a webhook.
+func ParseWebhookTemplate(text string) (*template.Template, error) {
+	return template.New("webhook").Funcs(webhookTemplateFuncs).Option("missingkey=error").Parse(text)
+}
+
+// SignWebhookBody returns the signature of the body, the hex encoded HMAC-SHA256
+// of the body keyed with the secret, prefixed with `sha256=`.
+func SignWebhookBody(secret string, body []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write(body)
+
+	return "sha256=" + hex.EncodeToString(mac.Sum(nil))
+}
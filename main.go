@@ -9,17 +9,42 @@ import (
 	log "github.com/sirupsen/logrus"
 )
 
+const (
+	// modeAll runs both the api server & the scheduler.
+	modeAll = "all"
+
+	// modeAPI runs only the api server.
+	modeAPI = "api"
+
+	// modeScheduler runs only the scheduler.
+	modeScheduler = "scheduler"
+)
+
 func init() {
 	setupLogin()
 }
 
 func main() {
-	go tasks.StartScheduler()
+	mode, ok := os.LookupEnv("UPTIME_MODE")
+	if !ok {
+		mode = modeAll
+	}
 
 	datastore := db.New()
 	datastore.AddIndexes()
+	datastore.MigrateIntegrations()
 
-	api.StartServer()
+	switch mode {
+	case modeAll:
+		go tasks.StartScheduler()
+		api.StartServer()
+	case modeAPI:
+		api.StartServer()
+	case modeScheduler:
+		tasks.StartScheduler()
+	default:
+		log.Fatalf("Invalid UPTIME_MODE %s. Should be all/api/scheduler", mode)
+	}
 }
 
 func setupLogin() {